@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"server/internal/di"
+	"strings"
+)
+
+// casbin 策略文件与 casbin 文件适配器格式一致，每行一条，比如 p, admin, /api/system/user, GET。
+// dump 导出的是由角色接口、按钮接口派生出的生效策略；load 把 p 规则写为角色直接分配的接口、
+// g 规则（g, 用户名, 角色编码）写为用户角色，再重新生成策略，因此按钮带来的接口导入后会变成直接分配
+func runCasbin(app *di.App, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand: dump | load")
+	}
+
+	flags := flag.NewFlagSet("casbin "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case "dump":
+		output := flags.String("o", "", "输出文件，默认输出到标准输出")
+		_ = flags.Parse(args[1:])
+
+		rules, err := app.CasbinUsecase.DumpPolicies()
+		if err != nil {
+			return err
+		}
+		var w io.Writer = os.Stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		for _, rule := range rules {
+			if _, err := fmt.Fprintln(w, strings.Join(rule, ", ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "load":
+		file := flags.String("f", "", "策略文件")
+		replace := flags.Bool("replace", false, "覆盖文件中出现的角色的直接接口及用户的角色，默认只追加")
+		_ = flags.Parse(args[1:])
+		if *file == "" {
+			return fmt.Errorf("-f is required")
+		}
+
+		rules, err := readPolicyFile(*file)
+		if err != nil {
+			return err
+		}
+		if err := app.RoleUsecase.ImportPolicies(context.Background(), rules, *replace); err != nil {
+			return err
+		}
+		// 运行中的服务只在启动时加载策略，需重启后生效
+		fmt.Fprintln(os.Stderr, "导入成功，运行中的服务需重启后才会使用新策略")
+		return nil
+	}
+	return fmt.Errorf("unknown subcommand: %s", args[0])
+}
+
+func readPolicyFile(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules [][]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		rules = append(rules, fields)
+	}
+	return rules, scanner.Err()
+}
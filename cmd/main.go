@@ -1,18 +1,32 @@
 package main
 
 import (
-	"context"
-	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
 	"server/internal/di"
-	"syscall"
-	"time"
+	"sort"
 )
 
-const configFile = "./etc/config.yaml"
+const (
+	defaultConfigFile = "./etc/config.yaml"
+	configEnv         = "SERVER_CONFIG" // 配置文件路径环境变量，优先级低于 --config
+)
+
+type command struct {
+	usage string
+	run   func(app *di.App, args []string) error
+}
+
+var commands = map[string]command{
+	"serve":   {usage: "启动 HTTP 服务（默认）", run: runServe},
+	"migrate": {usage: "数据库迁移：up [--dry-run] | down [-n 1] [--dry-run] | status", run: runMigrate},
+	"seed":    {usage: "补齐内置接口及超级管理员权限", run: runSeed},
+	"user":    {usage: "用户管理：create-admin | reset-password", run: runUser},
+	"casbin":  {usage: "权限策略：dump [-o file] | load -f file [--replace]", run: runCasbin},
+	"routes":  {usage: "打印已注册的路由", run: runRoutes},
+}
 
 // @title 系统管理API
 // @version 1.0
@@ -34,24 +48,50 @@ const configFile = "./etc/config.yaml"
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
-	app, err := di.InitApp(configFile)
-	if err != nil {
-		log.Fatalf("init error: %v", err)
+	flags := flag.NewFlagSet("server", flag.ExitOnError)
+	configFile := flags.String("config", "", "配置文件路径，未指定时读取环境变量 "+configEnv+"，默认 "+defaultConfigFile)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "用法: server [--config file] <command> [args]")
+		fmt.Fprintln(flags.Output(), "\n命令:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(flags.Output(), "  %-8s %s\n", name, commands[name].usage)
+		}
+		fmt.Fprintln(flags.Output(), "\n参数:")
+		flags.PrintDefaults()
 	}
+	_ = flags.Parse(os.Args[1:])
 
-	go func() {
-		if err := app.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("listen error: %v", err)
-		}
-	}()
+	name, args := "serve", flags.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		flags.Usage()
+		os.Exit(2)
+	}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	app, err := di.InitApp(resolveConfigFile(*configFile))
+	if err != nil {
+		log.Fatalf("init error: %v", err)
+	}
+	if err := cmd.run(app, args); err != nil {
+		log.Fatalf("%s error: %v", name, err)
+	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := app.Shutdown(ctx); err != nil {
-		log.Fatalf("shutdown error: %v", err)
+// resolveConfigFile 配置文件路径优先级：--config > 环境变量 > 默认路径
+func resolveConfigFile(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv(configEnv); env != "" {
+		return env
 	}
+	return defaultConfigFile
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"server/internal/core/migrate"
+	"server/internal/di"
+	"text/tabwriter"
+)
+
+func runMigrate(app *di.App, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand: up | down | status")
+	}
+
+	ctx := context.Background()
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case "up":
+		dryRun := flags.Bool("dry-run", false, "只打印执行计划")
+		_ = flags.Parse(args[1:])
+		if *dryRun {
+			steps, err := app.Migrator.Plan(ctx)
+			printSteps(steps)
+			return err
+		}
+		steps, err := app.Migrator.Up(ctx)
+		printSteps(steps)
+		return err
+	case "down":
+		n := flags.Int("n", 1, "回滚的迁移数量")
+		dryRun := flags.Bool("dry-run", false, "只打印回滚计划")
+		_ = flags.Parse(args[1:])
+		steps, err := app.Migrator.Down(ctx, *n, *dryRun)
+		printSteps(steps)
+		return err
+	case "status":
+		steps, err := app.Migrator.Plan(ctx)
+		printSteps(steps)
+		return err
+	}
+	return fmt.Errorf("unknown subcommand: %s", args[0])
+}
+
+func runSeed(app *di.App, _ []string) error {
+	return app.InitUsecase.Seed(context.Background())
+}
+
+func printSteps(steps []*migrate.Step) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tACTION\tDESCRIPTION")
+	for _, step := range steps {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", step.Name, step.Action, step.Description)
+	}
+	_ = w.Flush()
+}
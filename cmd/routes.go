@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"server/internal/di"
+	"text/tabwriter"
+)
+
+func runRoutes(app *di.App, _ []string) error {
+	if err := app.Router.InitIfNeeded(); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER")
+	for _, route := range app.Router.Engine().Routes() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"server/internal/di"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func runServe(app *di.App, _ []string) error {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	// 配置文件变化或收到 SIGHUP 时热更新日志级别、跨域及令牌有效期
+	app.Reloader.Watch(ctx, func(err error) {
+		if err != nil {
+			app.Logger.Error("[Config] reload fail", zap.Error(err))
+			return
+		}
+		app.Logger.Info("[Config] reload success")
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := app.Server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-errCh:
+		return err
+	case <-quit:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// 长连接已被接管，不受 HTTP 服务关闭影响，需要单独关闭
+	app.Hub.Close()
+	return app.Server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"server/internal/di"
+)
+
+func runUser(app *di.App, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand: create-admin | reset-password")
+	}
+
+	flags := flag.NewFlagSet("user "+args[0], flag.ExitOnError)
+	username := flags.String("username", "", "用户名")
+	password := flags.String("password", "", "密码")
+
+	switch args[0] {
+	case "create-admin":
+		nickname := flags.String("nickname", "", "昵称")
+		_ = flags.Parse(args[1:])
+		if *username == "" || *password == "" {
+			return fmt.Errorf("--username and --password are required")
+		}
+		return app.UserUsecase.CreateAdmin(context.Background(), *username, *password, *nickname)
+	case "reset-password":
+		_ = flags.Parse(args[1:])
+		if *username == "" || *password == "" {
+			return fmt.Errorf("--username and --password are required")
+		}
+		return app.UserUsecase.ResetPassword(context.Background(), *username, *password)
+	}
+	return fmt.Errorf("unknown subcommand: %s", args[0])
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -13,26 +18,101 @@ type Config struct {
 	Http        *HTTPServer `mapstructure:"http" json:"http" yaml:"http"`
 	Jwt         *Jwt        `mapstructure:"jwt" json:"jwt" yaml:"jwt"`
 	Redis       *Redis      `mapstructure:"redis" json:"redis" yaml:"redis"`
+	Storage     *Storage    `mapstructure:"storage" json:"storage" yaml:"storage"`
+	Im          *Im         `mapstructure:"im" json:"im" yaml:"im"`
 }
 
+const (
+	envPrefix     = "SERVER"
+	envName       = envPrefix + "_ENV" // 运行环境，比如 prod，用于加载覆盖文件 config.prod.yaml
+	envFileSuffix = "_FILE"            // 从文件读取配置值，比如 SERVER_JWT_SECRET_FILE=/run/secrets/jwt
+)
+
+// LoadConfig 按以下顺序加载配置，后者覆盖前者：
+// 默认值 -> 配置文件 -> 环境覆盖文件（config.<env>.yaml）-> 环境变量（SERVER_JWT_SECRET）-> 密钥文件（SERVER_JWT_SECRET_FILE）
 func LoadConfig(path string) (*Config, error) {
 	v := viper.New()
+	setDefaults(v)
 
 	// 直接指定配置文件路径和名称
-	v.SetConfigFile(path) // 这里用常量
-
+	v.SetConfigFile(path)
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("read config file error: %w", err)
 	}
 
+	if env := os.Getenv(envName); env != "" {
+		overlay := overlayPath(path, env)
+		if _, err := os.Stat(overlay); err != nil {
+			return nil, fmt.Errorf("read config overlay file error: %w", err)
+		}
+		v.SetConfigFile(overlay)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("merge config overlay file error: %w", err)
+		}
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if err := loadSecretFiles(v); err != nil {
+		return nil, err
+	}
+
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unmarshal config error: %w", err)
 	}
 
+	var errs []error
+	for _, key := range unknownKeys(v) {
+		errs = append(errs, fmt.Errorf("%s: unknown config key", key))
+	}
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+
 	return &cfg, nil
 }
 
+// overlayPath etc/config.yaml -> etc/config.prod.yaml
+func overlayPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// loadSecretFiles 读取 SERVER_<KEY>_FILE 指向的文件作为配置值，避免密钥出现在配置文件或环境变量中
+func loadSecretFiles(v *viper.Viper) error {
+	for key := range defaults {
+		env := envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + envFileSuffix
+		file := os.Getenv(env)
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read %s error: %w", env, err)
+		}
+		v.Set(key, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// unknownKeys 返回配置文件中拼写错误或已废弃的配置项
+func unknownKeys(v *viper.Viper) []string {
+	var result []string
+	for _, key := range v.AllKeys() {
+		if _, ok := defaults[key]; !ok {
+			result = append(result, key)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 func ProvideLoggerConfig(cfg *Config) *Logger {
 	return cfg.Logger
 }
@@ -57,6 +137,34 @@ func ProvideRedisConfig(cfg *Config) *Redis {
 	return cfg.Redis
 }
 
+func ProvideStorageConfig(cfg *Config) *Storage {
+	return cfg.Storage
+}
+
+func ProvideAttachmentConfig(cfg *Config) *Attachment {
+	return cfg.Im.Attachment
+}
+
+func ProvideModerationConfig(cfg *Config) *Moderation {
+	return cfg.Im.Moderation
+}
+
+func ProvideGatewayConfig(cfg *Config) *Gateway {
+	return cfg.Im.Gateway
+}
+
+func ProvideGroupConfig(cfg *Config) *Group {
+	return cfg.Im.Group
+}
+
+func ProvidePresenceConfig(cfg *Config) *Presence {
+	return cfg.Im.Presence
+}
+
+func ProvideMessageConfig(cfg *Config) *Message {
+	return cfg.Im.Message
+}
+
 func ProvideImMysqlConfig(cfg *Config) *Mysql {
 	return cfg.ImMySQL
 }
@@ -0,0 +1,99 @@
+package config
+
+import "github.com/spf13/viper"
+
+// defaults 全部配置项的默认值，同时作为已知配置项列表：环境变量覆盖只对已知配置项生效，未知配置项在校验时报错
+var defaults = map[string]any{
+	"logger.level":          "info",
+	"logger.prefix":         "",
+	"logger.format":         "console",
+	"logger.director":       "logs",
+	"logger.show_line":      true,
+	"logger.log_in_console": true,
+	"logger.max_size":       100,
+	"logger.max_backups":    30,
+	"logger.max_age":        30,
+	"logger.compress":       true,
+
+	"system_mysql.host":           "127.0.0.1",
+	"system_mysql.port":           3306,
+	"system_mysql.user":           "root",
+	"system_mysql.password":       "",
+	"system_mysql.dbname":         "",
+	"system_mysql.charset":        "utf8mb4",
+	"system_mysql.max_open_conns": 100,
+	"system_mysql.max_idle_conns": 10,
+
+	"im_mysql.host":           "127.0.0.1",
+	"im_mysql.port":           3306,
+	"im_mysql.user":           "root",
+	"im_mysql.password":       "",
+	"im_mysql.dbname":         "",
+	"im_mysql.charset":        "utf8mb4",
+	"im_mysql.max_open_conns": 100,
+	"im_mysql.max_idle_conns": 10,
+
+	"http.addr":               "9999",
+	"http.read_timeout":       10,
+	"http.write_timeout":      10,
+	"http.max_body_size":      10,
+	"http.expose_errors":      false,
+	"http.cors.enabled":       false,
+	"http.cors.allow_origins": []string{},
+
+	"jwt.secret":         "",
+	"jwt.access_expire":  3600,
+	"jwt.refresh_expire": 604800,
+
+	"redis.addr":     "",
+	"redis.password": "",
+	"redis.db":       0,
+
+	"storage.driver":             StorageDriverLocal,
+	"storage.local.dir":          "uploads",
+	"storage.local.base_url":     "/api/im/attachment/file",
+	"storage.local.secret":       "",
+	"storage.s3.endpoint":        "",
+	"storage.s3.public_endpoint": "",
+	"storage.s3.region":          "us-east-1",
+	"storage.s3.bucket":          "",
+	"storage.s3.access_key":      "",
+	"storage.s3.secret_key":      "",
+	"storage.s3.path_style":      true,
+
+	"im.gateway.write_wait":         10,
+	"im.gateway.pong_wait":          60,
+	"im.gateway.max_message_size":   65536,
+	"im.gateway.send_buffer":        256,
+	"im.gateway.max_conns_per_user": 5,
+
+	"im.group.max_members":         2000,
+	"im.group.default_max_members": 500,
+	"im.group.max_owned_groups":    100,
+
+	"im.presence.ttl":            150,
+	"im.presence.max_subscribes": 1000,
+
+	"im.message.recall_window":     120,
+	"im.message.edit_window":       86400,
+	"im.message.max_reaction_kind": 20,
+
+	"im.attachment.max_image_size": 5,
+	"im.attachment.max_voice_size": 2,
+	"im.attachment.max_file_size":  8,
+	"im.attachment.image_types":    []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+	"im.attachment.voice_types":    []string{"audio/*", "application/ogg", "video/webm", "video/mp4"},
+	"im.attachment.file_types":     []string{},
+	"im.attachment.thumbnail_size": 320,
+	"im.attachment.url_expire":     3600,
+
+	"im.moderation.enabled":         true,
+	"im.moderation.reload_interval": 30,
+	"im.moderation.max_group_words": 200,
+}
+
+func setDefaults(v *viper.Viper) {
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+}
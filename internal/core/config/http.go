@@ -4,6 +4,8 @@ type HTTPServer struct {
 	Addr         string `mapstructure:"addr" json:"addr" yaml:"addr"`                            // 服务监听地址
 	ReadTimeout  int    `mapstructure:"read_timeout" json:"read_timeout" yaml:"read_timeout"`    // 读取超时时间（秒）
 	WriteTimeout int    `mapstructure:"write_timeout" json:"write_timeout" yaml:"write_timeout"` // 写入超时时间（秒）
+	MaxBodySize  int64  `mapstructure:"max_body_size" json:"max_body_size" yaml:"max_body_size"` // 请求体大小上限（MB）
+	ExposeErrors bool   `mapstructure:"expose_errors" json:"expose_errors" yaml:"expose_errors"` // 错误响应中返回原始错误，仅用于开发调试
 	Cors         *Cors  `mapstructure:"cors" json:"cors" yaml:"cors"`
 }
 
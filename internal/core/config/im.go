@@ -0,0 +1,58 @@
+package config
+
+type Im struct {
+	Gateway    *Gateway    `mapstructure:"gateway" json:"gateway" yaml:"gateway"`
+	Group      *Group      `mapstructure:"group" json:"group" yaml:"group"`
+	Presence   *Presence   `mapstructure:"presence" json:"presence" yaml:"presence"`
+	Message    *Message    `mapstructure:"message" json:"message" yaml:"message"`
+	Attachment *Attachment `mapstructure:"attachment" json:"attachment" yaml:"attachment"`
+	Moderation *Moderation `mapstructure:"moderation" json:"moderation" yaml:"moderation"`
+}
+
+// Gateway WebSocket 网关配置
+type Gateway struct {
+	WriteWait       int   `mapstructure:"write_wait" json:"write_wait" yaml:"write_wait"`                         // 单次写入超时时间（秒）
+	PongWait        int   `mapstructure:"pong_wait" json:"pong_wait" yaml:"pong_wait"`                            // 心跳超时时间（秒），超时未收到任何数据时断开连接
+	MaxMessageSize  int64 `mapstructure:"max_message_size" json:"max_message_size" yaml:"max_message_size"`       // 单个消息帧大小上限（字节）
+	SendBuffer      int   `mapstructure:"send_buffer" json:"send_buffer" yaml:"send_buffer"`                      // 每个连接的发送队列长度，队列满时视为慢客户端并断开
+	MaxConnsPerUser int   `mapstructure:"max_conns_per_user" json:"max_conns_per_user" yaml:"max_conns_per_user"` // 每个用户的连接数上限，超出时关闭最早的连接
+}
+
+// Group 群聊配置
+type Group struct {
+	MaxMembers        int `mapstructure:"max_members" json:"max_members" yaml:"max_members"`                         // 群成员数上限，创建群组时指定的上限不能超过该值
+	DefaultMaxMembers int `mapstructure:"default_max_members" json:"default_max_members" yaml:"default_max_members"` // 创建群组未指定上限时使用的默认值
+	MaxOwnedGroups    int `mapstructure:"max_owned_groups" json:"max_owned_groups" yaml:"max_owned_groups"`          // 每个用户可创建的群组数上限
+}
+
+// Presence 在线状态配置
+type Presence struct {
+	TTL           int `mapstructure:"ttl" json:"ttl" yaml:"ttl"`                                  // 连接在线状态有效期（秒），心跳时续期，实例异常退出后超时视为离线
+	MaxSubscribes int `mapstructure:"max_subscribes" json:"max_subscribes" yaml:"max_subscribes"` // 每个连接可订阅在线状态的用户数上限
+}
+
+// Message 消息撤回、编辑与表情回应配置
+type Message struct {
+	RecallWindow    int `mapstructure:"recall_window" json:"recall_window" yaml:"recall_window"`             // 发送后可撤回的时间（秒）
+	EditWindow      int `mapstructure:"edit_window" json:"edit_window" yaml:"edit_window"`                   // 发送后可编辑的时间（秒），0 表示不限制
+	MaxReactionKind int `mapstructure:"max_reaction_kind" json:"max_reaction_kind" yaml:"max_reaction_kind"` // 每条消息的表情回应种类上限
+}
+
+// Attachment 图片、文件、语音消息的附件配置，大小上限不能超过 http.max_body_size
+type Attachment struct {
+	MaxImageSize  int64    `mapstructure:"max_image_size" json:"max_image_size" yaml:"max_image_size"` // 图片大小上限（MB）
+	MaxVoiceSize  int64    `mapstructure:"max_voice_size" json:"max_voice_size" yaml:"max_voice_size"` // 语音大小上限（MB）
+	MaxFileSize   int64    `mapstructure:"max_file_size" json:"max_file_size" yaml:"max_file_size"`    // 文件大小上限（MB）
+	ImageTypes    []string `mapstructure:"image_types" json:"image_types" yaml:"image_types"`          // 允许的图片 MIME 类型，支持 image/* 形式的通配
+	VoiceTypes    []string `mapstructure:"voice_types" json:"voice_types" yaml:"voice_types"`          // 允许的语音 MIME 类型
+	FileTypes     []string `mapstructure:"file_types" json:"file_types" yaml:"file_types"`             // 允许的文件 MIME 类型，为空表示不限制
+	ThumbnailSize int      `mapstructure:"thumbnail_size" json:"thumbnail_size" yaml:"thumbnail_size"` // 缩略图最长边（像素），图片不超过该尺寸时不生成缩略图
+	URLExpire     int      `mapstructure:"url_expire" json:"url_expire" yaml:"url_expire"`             // 下载地址有效期（秒）
+}
+
+// Moderation 消息内容审核配置，敏感词保存在数据库中，由管理后台维护
+type Moderation struct {
+	Enabled        bool `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                         // 是否审核文本及自定义消息
+	ReloadInterval int  `mapstructure:"reload_interval" json:"reload_interval" yaml:"reload_interval"` // 检查敏感词和群审核设置变化的间隔（秒），多实例部署时其他实例的修改在该间隔内生效
+	MaxGroupWords  int  `mapstructure:"max_group_words" json:"max_group_words" yaml:"max_group_words"` // 每个群可自定义的敏感词数量上限
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadableKeys 支持热更新的配置项，其余配置项（数据库、Redis、监听地址、JWT 密钥等）变化时拒绝本次重载
+var reloadableKeys = map[string]struct{}{
+	"logger.level":            {},
+	"http.expose_errors":      {},
+	"http.cors.enabled":       {},
+	"http.cors.allow_origins": {},
+	"jwt.access_expire":       {},
+	"jwt.refresh_expire":      {},
+}
+
+// Reloader 监听配置文件（含环境覆盖文件）变化和 SIGHUP 信号，重新加载并校验配置后通知订阅者
+type Reloader struct {
+	path        string
+	mu          sync.Mutex // 串行化重载和订阅
+	current     atomic.Pointer[Config]
+	subscribers []func(cfg *Config)
+}
+
+func NewReloader(path string, cfg *Config) *Reloader {
+	r := &Reloader{path: path}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current 当前生效的配置
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Subscribe 注册配置变化回调，回调只应读取可热更新的配置项
+func (r *Reloader) Subscribe(fn func(cfg *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload 重新加载配置，校验失败或修改了不可热更新的配置项时保持原配置不变
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := LoadConfig(r.path)
+	if err != nil {
+		return err
+	}
+	if keys := immutableChanges(r.current.Load(), cfg); len(keys) > 0 {
+		return fmt.Errorf("config reload rejected, restart required for: %s", strings.Join(keys, ", "))
+	}
+
+	r.current.Store(cfg)
+	for _, fn := range r.subscribers {
+		fn(cfg)
+	}
+	return nil
+}
+
+// Watch 监听配置文件及环境覆盖文件（config.<SERVER_ENV>.yaml）的变化和 SIGHUP 信号触发重载，
+// 每次重载的结果交给 report，ctx 结束后停止监听
+func (r *Reloader) Watch(ctx context.Context, report func(err error)) {
+	files := map[string]struct{}{filepath.Clean(r.path): {}}
+	if env := os.Getenv(envName); env != "" {
+		files[filepath.Clean(overlayPath(r.path, env))] = struct{}{}
+	}
+
+	// 监听所在目录而不是文件本身，编辑器保存时可能替换文件，直接监听文件会丢失后续事件
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		report(fmt.Errorf("watch config files error: %w", err))
+	} else {
+		dirs := make(map[string]struct{}, len(files))
+		for file := range files {
+			dirs[filepath.Dir(file)] = struct{}{}
+		}
+		for dir := range dirs {
+			if err := watcher.Add(dir); err != nil {
+				report(fmt.Errorf("watch config dir %s error: %w", dir, err))
+			}
+		}
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		if watcher != nil {
+			defer watcher.Close()
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				report(r.Reload())
+			case e, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				if _, watched := files[filepath.Clean(e.Name)]; watched && e.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					report(r.Reload())
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				report(fmt.Errorf("watch config files error: %w", err))
+			}
+		}
+	}()
+}
+
+// immutableChanges 返回新旧配置中值不同且不支持热更新的配置项
+func immutableChanges(old, new *Config) []string {
+	var keys []string
+	diffKeys("", reflect.ValueOf(old), reflect.ValueOf(new), &keys)
+	result := keys[:0]
+	for _, key := range keys {
+		if _, ok := reloadableKeys[key]; !ok {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+func diffKeys(prefix string, a, b reflect.Value, keys *[]string) {
+	for a.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*keys = append(*keys, prefix)
+			}
+			return
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*keys = append(*keys, prefix)
+		}
+		return
+	}
+	for i := 0; i < a.NumField(); i++ {
+		key := a.Type().Field(i).Tag.Get("mapstructure")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		diffKeys(key, a.Field(i), b.Field(i), keys)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// Storage 文件存储配置
+type Storage struct {
+	Driver string        `mapstructure:"driver" json:"driver" yaml:"driver"` // local 本地磁盘，s3 兼容 S3 协议的对象存储（AWS S3、MinIO 等）
+	Local  *LocalStorage `mapstructure:"local" json:"local" yaml:"local"`
+	S3     *S3Storage    `mapstructure:"s3" json:"s3" yaml:"s3"`
+}
+
+type LocalStorage struct {
+	Dir     string `mapstructure:"dir" json:"dir" yaml:"dir"`                // 存储目录
+	BaseURL string `mapstructure:"base_url" json:"base_url" yaml:"base_url"` // 下载地址前缀，对应提供文件下载的路由
+	Secret  string `mapstructure:"secret" json:"secret" yaml:"secret"`       // 下载地址签名密钥，为空时使用 jwt.secret
+}
+
+type S3Storage struct {
+	Endpoint       string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`                      // 服务地址，比如 https://s3.us-east-1.amazonaws.com、http://127.0.0.1:9000
+	PublicEndpoint string `mapstructure:"public_endpoint" json:"public_endpoint" yaml:"public_endpoint"` // 客户端访问的地址，用于生成下载地址，为空时与 endpoint 相同
+	Region         string `mapstructure:"region" json:"region" yaml:"region"`
+	Bucket         string `mapstructure:"bucket" json:"bucket" yaml:"bucket"`
+	AccessKey      string `mapstructure:"access_key" json:"access_key" yaml:"access_key"`
+	SecretKey      string `mapstructure:"secret_key" json:"secret_key" yaml:"secret_key"`
+	PathStyle      bool   `mapstructure:"path_style" json:"path_style" yaml:"path_style"` // 使用路径形式访问存储桶，MinIO 等自建服务通常需要开启
+}
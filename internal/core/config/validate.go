@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// validator 收集全部配置错误，启动时一次性报告
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, key, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+// Validate 校验配置，返回的错误包含所有不合法的配置项
+func (c *Config) Validate() error {
+	v := &validator{}
+	c.Logger.validate(v)
+	c.SystemMySQL.validate(v, "system_mysql")
+	c.ImMySQL.validate(v, "im_mysql")
+	c.Http.validate(v)
+	c.Jwt.validate(v)
+	c.Redis.validate(v)
+	c.Im.Gateway.validate(v)
+	c.Im.Group.validate(v)
+	c.Im.Presence.validate(v, c.Im.Gateway)
+	c.Im.Message.validate(v)
+	c.Im.Attachment.validate(v, c.Http)
+	c.Im.Moderation.validate(v)
+	c.Storage.validate(v)
+	return v.err()
+}
+
+func (l *Logger) validate(v *validator) {
+	switch l.Level {
+	case "debug", "info", "warn", "error", "dpanic", "panic", "fatal":
+	default:
+		v.check(false, "logger.level", "unsupported level %q", l.Level)
+	}
+	v.check(l.Format == "console" || l.Format == "json", "logger.format", "must be console or json, got %q", l.Format)
+	v.check(l.Director != "", "logger.director", "is required")
+	v.check(l.MaxSize > 0, "logger.max_size", "must be greater than 0")
+	v.check(l.MaxBackups >= 0, "logger.max_backups", "must not be negative")
+	v.check(l.MaxAge >= 0, "logger.max_age", "must not be negative")
+}
+
+func (m *Mysql) validate(v *validator, prefix string) {
+	v.check(m.Host != "", prefix+".host", "is required")
+	v.check(m.Port > 0 && m.Port <= 65535, prefix+".port", "must be between 1 and 65535, got %d", m.Port)
+	v.check(m.User != "", prefix+".user", "is required")
+	v.check(m.Dbname != "", prefix+".dbname", "is required")
+	v.check(m.Charset != "", prefix+".charset", "is required")
+	v.check(m.MaxOpenConns > 0, prefix+".max_open_conns", "must be greater than 0")
+	v.check(m.MaxIdleConns >= 0 && m.MaxIdleConns <= m.MaxOpenConns, prefix+".max_idle_conns", "must be between 0 and max_open_conns")
+}
+
+func (h *HTTPServer) validate(v *validator) {
+	port, err := strconv.Atoi(h.Addr)
+	v.check(err == nil && port > 0 && port <= 65535, "http.addr", "must be a port between 1 and 65535, got %q", h.Addr)
+	v.check(h.ReadTimeout > 0, "http.read_timeout", "must be greater than 0")
+	v.check(h.WriteTimeout > 0, "http.write_timeout", "must be greater than 0")
+	v.check(h.MaxBodySize > 0, "http.max_body_size", "must be greater than 0")
+	if h.Cors.Enabled {
+		v.check(len(h.Cors.AllowOrigins) > 0, "http.cors.allow_origins", "is required when cors is enabled")
+	}
+}
+
+func (j *Jwt) validate(v *validator) {
+	v.check(j.Secret != "", "jwt.secret", "is required")
+	v.check(j.AccessExpire > 0, "jwt.access_expire", "must be greater than 0")
+	v.check(j.RefreshExpire >= j.AccessExpire, "jwt.refresh_expire", "must not be less than access_expire")
+}
+
+func (r *Redis) validate(v *validator) {
+	v.check(r.DB >= 0 && r.DB <= 15, "redis.db", "must be between 0 and 15, got %d", r.DB)
+}
+
+func (g *Gateway) validate(v *validator) {
+	v.check(g.WriteWait > 0, "im.gateway.write_wait", "must be greater than 0")
+	v.check(g.PongWait > 1, "im.gateway.pong_wait", "must be greater than 1")
+	v.check(g.MaxMessageSize > 0, "im.gateway.max_message_size", "must be greater than 0")
+	v.check(g.SendBuffer > 0, "im.gateway.send_buffer", "must be greater than 0")
+	v.check(g.MaxConnsPerUser > 0, "im.gateway.max_conns_per_user", "must be greater than 0")
+}
+
+func (g *Group) validate(v *validator) {
+	v.check(g.MaxMembers > 1, "im.group.max_members", "must be greater than 1")
+	v.check(g.DefaultMaxMembers > 1 && g.DefaultMaxMembers <= g.MaxMembers, "im.group.default_max_members", "must be between 2 and max_members")
+	v.check(g.MaxOwnedGroups > 0, "im.group.max_owned_groups", "must be greater than 0")
+}
+
+func (p *Presence) validate(v *validator, gateway *Gateway) {
+	// 服务端每 pong_wait*0.9 秒发送一次 ping，有效期需覆盖一个心跳周期
+	v.check(p.TTL > gateway.PongWait, "im.presence.ttl", "must be greater than im.gateway.pong_wait")
+	v.check(p.MaxSubscribes > 0, "im.presence.max_subscribes", "must be greater than 0")
+}
+
+func (m *Message) validate(v *validator) {
+	v.check(m.RecallWindow > 0, "im.message.recall_window", "must be greater than 0")
+	v.check(m.EditWindow >= 0, "im.message.edit_window", "must not be negative")
+	v.check(m.MaxReactionKind > 0, "im.message.max_reaction_kind", "must be greater than 0")
+}
+
+func (a *Attachment) validate(v *validator, http *HTTPServer) {
+	// 上传请求受 http.max_body_size 限制，附件大小上限不能超过该值
+	v.check(a.MaxImageSize > 0 && a.MaxImageSize <= http.MaxBodySize, "im.attachment.max_image_size", "must be between 1 and http.max_body_size")
+	v.check(a.MaxVoiceSize > 0 && a.MaxVoiceSize <= http.MaxBodySize, "im.attachment.max_voice_size", "must be between 1 and http.max_body_size")
+	v.check(a.MaxFileSize > 0 && a.MaxFileSize <= http.MaxBodySize, "im.attachment.max_file_size", "must be between 1 and http.max_body_size")
+	v.check(len(a.ImageTypes) > 0, "im.attachment.image_types", "is required")
+	v.check(len(a.VoiceTypes) > 0, "im.attachment.voice_types", "is required")
+	v.check(a.ThumbnailSize > 0, "im.attachment.thumbnail_size", "must be greater than 0")
+	v.check(a.URLExpire > 0, "im.attachment.url_expire", "must be greater than 0")
+}
+
+func (m *Moderation) validate(v *validator) {
+	v.check(m.ReloadInterval > 0, "im.moderation.reload_interval", "must be greater than 0")
+	v.check(m.MaxGroupWords >= 0, "im.moderation.max_group_words", "must not be negative")
+}
+
+func (s *Storage) validate(v *validator) {
+	switch s.Driver {
+	case StorageDriverLocal:
+		v.check(s.Local.Dir != "", "storage.local.dir", "is required")
+		v.check(s.Local.BaseURL != "", "storage.local.base_url", "is required")
+	case StorageDriverS3:
+		v.check(s.S3.Endpoint != "", "storage.s3.endpoint", "is required")
+		v.check(s.S3.Region != "", "storage.s3.region", "is required")
+		v.check(s.S3.Bucket != "", "storage.s3.bucket", "is required")
+		v.check(s.S3.AccessKey != "", "storage.s3.access_key", "is required")
+		v.check(s.S3.SecretKey != "", "storage.s3.secret_key", "is required")
+	default:
+		v.check(false, "storage.driver", "must be local or s3, got %q", s.Driver)
+	}
+}
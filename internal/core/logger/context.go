@@ -0,0 +1,19 @@
+package logger
+
+import "context"
+
+type requestIDKey struct{}
+
+// NewRequestIDContext 将请求 ID 放入 ctx，WithContext 返回的日志会携带该请求 ID
+func NewRequestIDContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求 ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 )
 
@@ -11,4 +13,6 @@ type Logger interface {
 	Error(msg string, fields ...zap.Field)
 	Fatal(msg string, fields ...zap.Field)
 	Sync() error
+	// WithContext 返回携带 ctx 中请求 ID 的日志
+	WithContext(ctx context.Context) Logger
 }
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,7 +15,8 @@ import (
 )
 
 type ZapLogger struct {
-	zap *zap.Logger
+	zap   *zap.Logger
+	level zap.AtomicLevel // 最低输出级别，支持热更新
 }
 
 func NewZapLogger(cfg *config.Logger) (*ZapLogger, error) {
@@ -27,23 +29,21 @@ func NewZapLogger(cfg *config.Logger) (*ZapLogger, error) {
 		}
 	}
 
-	baseLevel := parseLevel(cfg.Level)
+	baseLevel := zap.NewAtomicLevelAt(parseLevel(cfg.Level))
 	cores := make([]zapcore.Core, 0)
 
-	// 文件输出
+	// 文件输出，每个级别的文件都会创建，是否写入由当前最低级别决定，以便热更新级别后生效
 	jsonEncoder := getEncoder(cfg, false)
 	for _, level := range []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel} {
-		if baseLevel <= level {
-			ws := getFileWriter(cfg, level.String())
-			core := zapcore.NewCore(jsonEncoder, ws, zap.NewAtomicLevelAt(level))
-			cores = append(cores, core)
-		}
+		ws := getFileWriter(cfg, level.String())
+		core := zapcore.NewCore(jsonEncoder, ws, fileLevelEnabler(level, baseLevel))
+		cores = append(cores, core)
 	}
 
 	// 控制台输出（彩色）
 	if cfg.LogInConsole {
 		consoleEncoder := getEncoder(cfg, true)
-		consoleCore := zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stdout), zap.NewAtomicLevelAt(baseLevel))
+		consoleCore := zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stdout), baseLevel)
 		cores = append(cores, consoleCore)
 	}
 
@@ -52,7 +52,12 @@ func NewZapLogger(cfg *config.Logger) (*ZapLogger, error) {
 		logger = logger.WithOptions(zap.AddCaller(), zap.AddCallerSkip(1))
 	}
 
-	return &ZapLogger{zap: logger}, nil
+	return &ZapLogger{zap: logger, level: baseLevel}, nil
+}
+
+// SetLevel 修改最低输出级别，配置热更新时调用
+func (l *ZapLogger) SetLevel(level string) {
+	l.level.SetLevel(parseLevel(level))
 }
 
 func (l *ZapLogger) Debug(msg string, fields ...zap.Field) {
@@ -75,6 +80,14 @@ func (l *ZapLogger) Fatal(msg string, fields ...zap.Field) {
 	l.zap.Fatal(msg, fields...)
 }
 
+func (l *ZapLogger) WithContext(ctx context.Context) Logger {
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		return l
+	}
+	return &ZapLogger{zap: l.zap.With(zap.String("request_id", requestID)), level: l.level}
+}
+
 func (l *ZapLogger) Sync() error {
 	return l.zap.Sync()
 }
@@ -85,6 +98,13 @@ func (l *ZapLogger) Zap() *zap.Logger {
 
 // ---------- 辅助函数 ----------
 
+// fileLevelEnabler 级别文件记录不低于该级别且不低于当前最低级别的日志
+func fileLevelEnabler(level zapcore.Level, base zap.AtomicLevel) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= level && base.Enabled(l)
+	})
+}
+
 func parseLevel(level string) zapcore.Level {
 	switch strings.ToLower(level) {
 	case "debug":
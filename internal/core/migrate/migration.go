@@ -0,0 +1,141 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+type (
+	// Migration 一次表结构或数据迁移，由各模块注册
+	Migration struct {
+		Module      string // 所属模块，比如 system
+		Version     string // 语义化版本，比如 v1.0.0，同一模块内按版本升序执行
+		Name        string // 版本内的步骤名称，同一版本内按注册顺序执行
+		Description string
+
+		// Repeatable 可重复执行的迁移（比如表结构同步），校验和变化时重新执行，不参与回滚
+		Repeatable bool
+		// Source 参与校验和计算的迁移内容（比如种子数据），已执行的版本迁移内容被修改时拒绝继续执行
+		Source any
+		// Legacy 旧版 sys_init 中的初始化标记，旧库已存在该标记时直接视为已执行
+		Legacy string
+
+		Up   func(ctx context.Context) error
+		Down func(ctx context.Context) error // 为空表示不支持回滚
+	}
+
+	// Record 迁移执行记录
+	Record struct {
+		Name        string
+		Module      string
+		Version     string
+		Checksum    string
+		Description string
+		AppliedAt   time.Time
+	}
+
+	// Source 模块的迁移注册入口
+	Source interface {
+		Migrations() []*Migration
+	}
+
+	// AfterMigrateHook 模块可选实现，本次执行或回滚了迁移后调用，用于刷新内存中的缓存
+	AfterMigrateHook interface {
+		AfterMigrate(ctx context.Context) error
+	}
+
+	// Store 迁移记录存储
+	Store interface {
+		// Prepare 确保迁移记录表存在
+		Prepare(ctx context.Context) error
+		// Lock 持有全局锁执行 fn，防止多个实例同时迁移
+		Lock(ctx context.Context, fn func(ctx context.Context) error) error
+		// InTx 在事务中执行 fn
+		InTx(ctx context.Context, fn func(ctx context.Context) error) error
+		// Records 返回全部执行记录（包括旧版初始化标记），按名称索引
+		Records(ctx context.Context) (map[string]*Record, error)
+		Save(ctx context.Context, record *Record) error
+		Remove(ctx context.Context, name string) error
+	}
+)
+
+// Key 迁移在 sys_init 中的唯一名称
+func (m *Migration) Key() string {
+	if m.Repeatable {
+		return m.Module + ":repeatable:" + m.Name
+	}
+	return m.Module + ":" + m.Version + ":" + m.Name
+}
+
+// Checksum 迁移内容的校验和
+func (m *Migration) Checksum() (string, error) {
+	data, err := json.Marshal(struct {
+		Key    string `json:"key"`
+		Source any    `json:"source"`
+	}{Key: m.Key(), Source: m.Source})
+	if err != nil {
+		return "", fmt.Errorf("migration %s checksum error: %w", m.Key(), err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SchemaSource 将模型结构（字段名、类型及 tag）转换为校验和内容，模型变化时表结构同步迁移会重新执行
+func SchemaSource(tables ...schema.Tabler) []string {
+	result := make([]string, 0, len(tables))
+	for _, table := range tables {
+		t := reflect.TypeOf(table)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		result = append(result, t.String()+"{"+schemaFields(t)+"}")
+	}
+	return result
+}
+
+func schemaFields(t reflect.Type) string {
+	if t.Kind() != reflect.Struct {
+		return t.String()
+	}
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			fields = append(fields, schemaFields(f.Type))
+			continue
+		}
+		fields = append(fields, f.Name+" "+f.Type.String()+" "+string(f.Tag))
+	}
+	return strings.Join(fields, ";")
+}
+
+// compareVersion 比较 vX.Y.Z 形式的版本号
+func compareVersion(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
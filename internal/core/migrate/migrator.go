@@ -0,0 +1,307 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/internal/core/logger"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Action string
+
+const (
+	ActionApply    Action = "apply"    // 待执行
+	ActionSkip     Action = "skip"     // 已执行，无需处理
+	ActionAdopt    Action = "adopt"    // 旧版初始化标记已存在，只补写记录
+	ActionRerun    Action = "rerun"    // 可重复迁移内容变化，重新执行
+	ActionConflict Action = "conflict" // 已执行的版本迁移内容被修改
+	ActionRollback Action = "rollback" // 回滚
+)
+
+var (
+	ErrChecksumMismatch = errors.New("migrate: applied migration has been modified")
+	ErrIrreversible     = errors.New("migrate: migration does not support rollback")
+	ErrLocked           = errors.New("migrate: another instance is migrating")
+)
+
+// Step 迁移计划中的一步
+type Step struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Action      Action `json:"action"`
+	Checksum    string `json:"checksum"`
+
+	migration *Migration
+}
+
+// Migrator 按模块、版本顺序执行迁移，并在 sys_init 中记录执行结果
+type Migrator struct {
+	logger     logger.Logger
+	store      Store
+	sources    []Source
+	migrations []*Migration
+}
+
+func NewMigrator(logger logger.Logger, store Store, sources []Source) (*Migrator, error) {
+	m := &Migrator{logger: logger, store: store, sources: sources}
+	seen := make(map[string]struct{})
+	for _, source := range sources {
+		migrations := source.Migrations()
+		// 可重复迁移（表结构同步）先执行，版本迁移按版本升序，同一版本保持注册顺序
+		sort.SliceStable(migrations, func(i, j int) bool {
+			a, b := migrations[i], migrations[j]
+			if a.Repeatable != b.Repeatable {
+				return a.Repeatable
+			}
+			return compareVersion(a.Version, b.Version) < 0
+		})
+		for _, migration := range migrations {
+			if migration.Module == "" || migration.Name == "" || migration.Up == nil {
+				return nil, fmt.Errorf("migrate: invalid migration %q", migration.Key())
+			}
+			if !migration.Repeatable && !strings.HasPrefix(migration.Version, "v") {
+				return nil, fmt.Errorf("migrate: invalid version of migration %q", migration.Key())
+			}
+			if _, ok := seen[migration.Key()]; ok {
+				return nil, fmt.Errorf("migrate: duplicate migration %q", migration.Key())
+			}
+			seen[migration.Key()] = struct{}{}
+			m.migrations = append(m.migrations, migration)
+		}
+	}
+	return m, nil
+}
+
+// InitIfNeeded 启动时执行待执行的迁移
+func (m *Migrator) InitIfNeeded() error {
+	_, err := m.Up(context.Background())
+	return err
+}
+
+// Plan 返回迁移计划，不修改数据库
+func (m *Migrator) Plan(ctx context.Context) ([]*Step, error) {
+	if err := m.store.Prepare(ctx); err != nil {
+		return nil, err
+	}
+	records, err := m.store.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.plan(records)
+}
+
+func (m *Migrator) plan(records map[string]*Record) ([]*Step, error) {
+	steps := make([]*Step, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		checksum, err := migration.Checksum()
+		if err != nil {
+			return nil, err
+		}
+		step := &Step{
+			Name:        migration.Key(),
+			Description: migration.Description,
+			Checksum:    checksum,
+			migration:   migration,
+		}
+
+		record, applied := records[migration.Key()]
+		switch {
+		case !applied && migration.Legacy != "" && records[migration.Legacy] != nil:
+			step.Action = ActionAdopt
+		case !applied:
+			step.Action = ActionApply
+		case record.Checksum == checksum:
+			step.Action = ActionSkip
+		case migration.Repeatable:
+			step.Action = ActionRerun
+		default:
+			step.Action = ActionConflict
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// Up 持锁执行全部待执行的迁移，返回执行计划
+func (m *Migrator) Up(ctx context.Context) ([]*Step, error) {
+	if err := m.store.Prepare(ctx); err != nil {
+		return nil, err
+	}
+
+	var steps []*Step
+	err := m.store.Lock(ctx, func(ctx context.Context) error {
+		// 持锁后重新读取记录，其他实例可能已执行过部分迁移
+		records, err := m.store.Records(ctx)
+		if err != nil {
+			return err
+		}
+		if steps, err = m.plan(records); err != nil {
+			return err
+		}
+
+		var conflicts []string
+		for _, step := range steps {
+			if step.Action == ActionConflict {
+				conflicts = append(conflicts, step.Name)
+			}
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(conflicts, ", "))
+		}
+
+		for _, step := range steps {
+			if err := m.apply(ctx, step); err != nil {
+				m.logger.Error("[Migrator] migration fail", zap.String("name", step.Name), zap.Error(err))
+				return fmt.Errorf("migration %s: %w", step.Name, err)
+			}
+			if step.Action != ActionSkip {
+				m.logger.Info("[Migrator] migration "+string(step.Action), zap.String("name", step.Name))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return steps, err
+	}
+
+	for _, step := range steps {
+		if step.Action != ActionSkip {
+			return steps, m.afterMigrate(ctx)
+		}
+	}
+	return steps, nil
+}
+
+func (m *Migrator) afterMigrate(ctx context.Context) error {
+	for _, source := range m.sources {
+		if hook, ok := source.(AfterMigrateHook); ok {
+			if err := hook.AfterMigrate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, step *Step) error {
+	migration := step.migration
+	record := &Record{
+		Name:        migration.Key(),
+		Module:      migration.Module,
+		Version:     migration.Version,
+		Checksum:    step.Checksum,
+		Description: migration.Description,
+		AppliedAt:   time.Now(),
+	}
+
+	switch step.Action {
+	case ActionAdopt:
+		return m.store.Save(ctx, record)
+	case ActionApply, ActionRerun:
+		// 表结构变更在 MySQL 中会隐式提交，可重复迁移不放在事务中
+		if migration.Repeatable {
+			if err := migration.Up(ctx); err != nil {
+				return err
+			}
+			return m.store.Save(ctx, record)
+		}
+		return m.store.InTx(ctx, func(ctx context.Context) error {
+			if err := migration.Up(ctx); err != nil {
+				return err
+			}
+			return m.store.Save(ctx, record)
+		})
+	}
+	return nil
+}
+
+// Down 按执行顺序倒序回滚最近 n 个版本迁移，dryRun 为 true 时只返回计划
+func (m *Migrator) Down(ctx context.Context, n int, dryRun bool) ([]*Step, error) {
+	if err := m.store.Prepare(ctx); err != nil {
+		return nil, err
+	}
+
+	var steps []*Step
+	rollback := func(ctx context.Context) error {
+		records, err := m.store.Records(ctx)
+		if err != nil {
+			return err
+		}
+
+		// 按记录的执行时间倒序回滚，多个模块的迁移交错执行时注册顺序不代表执行顺序；
+		// 同一次执行的迁移时间可能相同，此时按注册顺序倒序
+		type applied struct {
+			index  int
+			record *Record
+		}
+		var candidates []applied
+		for i, migration := range m.migrations {
+			if record, ok := records[migration.Key()]; ok && !migration.Repeatable {
+				candidates = append(candidates, applied{index: i, record: record})
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			a, b := candidates[i], candidates[j]
+			if !a.record.AppliedAt.Equal(b.record.AppliedAt) {
+				return a.record.AppliedAt.After(b.record.AppliedAt)
+			}
+			return a.index > b.index
+		})
+
+		steps = nil
+		for _, candidate := range candidates {
+			if len(steps) >= n {
+				break
+			}
+			migration := m.migrations[candidate.index]
+			steps = append(steps, &Step{
+				Name:        migration.Key(),
+				Description: migration.Description,
+				Action:      ActionRollback,
+				Checksum:    candidate.record.Checksum,
+				migration:   migration,
+			})
+		}
+		// 任何一步不支持回滚都不执行，避免只回滚一半
+		for _, step := range steps {
+			if step.migration.Down == nil {
+				return fmt.Errorf("%w: %s", ErrIrreversible, step.Name)
+			}
+		}
+		if dryRun {
+			return nil
+		}
+
+		for _, step := range steps {
+			err := m.store.InTx(ctx, func(ctx context.Context) error {
+				if err := step.migration.Down(ctx); err != nil {
+					return err
+				}
+				return m.store.Remove(ctx, step.Name)
+			})
+			if err != nil {
+				m.logger.Error("[Migrator] rollback fail", zap.String("name", step.Name), zap.Error(err))
+				return fmt.Errorf("rollback %s: %w", step.Name, err)
+			}
+			m.logger.Info("[Migrator] migration rollback", zap.String("name", step.Name))
+		}
+		return nil
+	}
+
+	if dryRun {
+		err := rollback(ctx)
+		return steps, err
+	}
+	if err := m.store.Lock(ctx, rollback); err != nil {
+		return steps, err
+	}
+	if len(steps) > 0 {
+		return steps, m.afterMigrate(ctx)
+	}
+	return steps, nil
+}
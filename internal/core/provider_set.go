@@ -3,11 +3,18 @@ package core
 import (
 	"server/internal/core/config"
 	"server/internal/core/logger"
+	"server/internal/core/migrate"
 	"server/internal/core/mysql"
 	"server/internal/core/redis"
 	"server/internal/core/router"
 	"server/internal/core/server"
+	"server/internal/core/storage"
+	"server/internal/middleware"
+	imBiz "server/internal/module/im/biz"
+	"server/internal/module/im/gateway"
 	"server/internal/module/system/biz"
+	"server/internal/module/system/biz/repo"
+	"server/pkg/response"
 
 	"github.com/google/wire"
 )
@@ -15,20 +22,31 @@ import (
 var ProviderSet = wire.NewSet(
 	server.NewHTTPServer,
 	NewInitManagerProvider,
+	NewMigratorProvider,
 
 	router.NewRouter,
 	wire.Bind(new(server.EngineProvider), new(*router.Router)),
 
 	config.LoadConfig,
+	NewConfigReloaderProvider,
+	NewErrorSinksProvider,
 	config.ProvideLoggerConfig,
 	config.ProvideHttpServerConfig,
 	config.ProviderCorsConfig,
 	config.ProvideJwtConfig,
 	config.ProvideRedisConfig,
+	config.ProvideGatewayConfig,
+	config.ProvideGroupConfig,
+	config.ProvidePresenceConfig,
+	config.ProvideMessageConfig,
+	config.ProvideAttachmentConfig,
+	config.ProvideModerationConfig,
+	config.ProvideStorageConfig,
 
 	NewSystemDBProvider,
 	NewImDBProvider,
 	redis.NewRedis,
+	storage.NewStorage,
 
 	logger.NewZapLogger,
 	wire.Bind(new(logger.Logger), new(*logger.ZapLogger)),
@@ -44,11 +62,58 @@ func NewImDBProvider(cfg *config.Config) (*mysql.ImDB, error) {
 	return mysql.NewImDB(cfg.ImMySQL)
 }
 
+// NewMigratorProvider 迁移执行器，各模块在此注册迁移
+func NewMigratorProvider(
+	logger logger.Logger,
+	initRepo repo.InitRepo,
+	initUsecase *biz.InitUsecase,
+	imInitUsecase *imBiz.InitUsecase,
+) (*migrate.Migrator, error) {
+	return migrate.NewMigrator(logger, initRepo, []migrate.Source{
+		initUsecase,
+		imInitUsecase,
+	})
+}
+
+// NewConfigReloaderProvider 配置热更新，在此注册订阅可热更新配置项的组件
+func NewConfigReloaderProvider(
+	path string,
+	cfg *config.Config,
+	zapLogger *logger.ZapLogger,
+	corsMiddleware *middleware.CorsMiddleware,
+	jwtUsecase *biz.JwtUsecase,
+) *config.Reloader {
+	reloader := config.NewReloader(path, cfg)
+	reloader.Subscribe(func(cfg *config.Config) {
+		zapLogger.SetLevel(cfg.Logger.Level)
+		response.SetExposeErrors(cfg.Http.ExposeErrors)
+		corsMiddleware.Update(cfg.Http.Cors)
+		jwtUsecase.UpdateConfig(cfg.Jwt)
+	})
+	return reloader
+}
+
+// NewErrorSinksProvider panic 上报，接入 Sentry 等错误收集服务时在此注册
+func NewErrorSinksProvider() []middleware.ErrorSink {
+	return nil
+}
+
 // NewInitManagerProvider 初始化管理器
-func NewInitManagerProvider(router *router.Router, initUsecase *biz.InitUsecase, cronUsecase *biz.CronUsecase) []server.InitManager {
+func NewInitManagerProvider(
+	router *router.Router,
+	migrator *migrate.Migrator,
+	cronUsecase *biz.CronUsecase,
+	presenceUsecase *imBiz.PresenceUsecase,
+	wordFilter *imBiz.WordFilter,
+	hub *gateway.Hub,
+) []server.InitManager {
 	return []server.InitManager{
 		router,
-		initUsecase,
+		migrator,
+		// 敏感词表由迁移创建，需在迁移之后加载
+		wordFilter,
 		cronUsecase,
+		presenceUsecase,
+		hub,
 	}
 }
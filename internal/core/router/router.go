@@ -16,7 +16,10 @@ type (
 )
 
 func NewRouter(provider Provider) *Router {
-	engine := gin.Default()
+	// 不使用 gin 默认的文本日志和 panic 恢复，由 AccessLogMiddleware、RecoveryMiddleware 写入 zap
+	engine := gin.New()
+	// 业务代码以 *gin.Context 作为 ctx 传递时，可以读取到请求 ctx 中的请求 ID
+	engine.ContextWithFallback = true
 	return &Router{engine: engine, Provider: provider}
 }
 
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"server/internal/core/config"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("storage: object not found")
+
+type (
+	// Storage 文件存储，key 为以 / 分隔的相对路径
+	Storage interface {
+		Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+		// Get 读取对象，不存在时返回 ErrNotFound
+		Get(ctx context.Context, key string) (io.ReadCloser, error)
+		// Delete 删除对象，对象不存在时不报错
+		Delete(ctx context.Context, key string) error
+		// SignURL 生成在 expire 内有效的下载地址
+		SignURL(ctx context.Context, key string, expire time.Duration) (string, error)
+	}
+
+	// URLVerifier 由服务自身提供下载的存储实现，用于校验 SignURL 生成的下载地址
+	URLVerifier interface {
+		Verify(key string, query url.Values) bool
+	}
+)
+
+// NewStorage 按 storage.driver 创建存储，本地存储未配置签名密钥时使用 jwt.secret
+func NewStorage(cfg *config.Storage, jwtCfg *config.Jwt) (Storage, error) {
+	switch cfg.Driver {
+	case config.StorageDriverLocal:
+		secret := cfg.Local.Secret
+		if secret == "" {
+			secret = jwtCfg.Secret
+		}
+		return NewLocalStorage(cfg.Local.Dir, cfg.Local.BaseURL, secret), nil
+	case config.StorageDriverS3:
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("storage: unsupported driver %q", cfg.Driver)
+	}
+}
+
+// cleanKey 拒绝绝对路径和包含 .. 的 key，避免越出存储目录
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if key == "" || strings.HasSuffix(key, "/") || cleaned != key {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,25 @@
+package di
+
+import (
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/internal/core/migrate"
+	"server/internal/core/router"
+	"server/internal/core/server"
+	"server/internal/module/im/gateway"
+	"server/internal/module/system/biz"
+)
+
+// App 命令行各子命令共享的依赖，由 wire 统一构建
+type App struct {
+	Server        *server.HTTPServer
+	Logger        logger.Logger
+	Reloader      *config.Reloader
+	Hub           *gateway.Hub
+	Router        *router.Router
+	Migrator      *migrate.Migrator
+	InitUsecase   *biz.InitUsecase
+	UserUsecase   *biz.UserUsecase
+	RoleUsecase   *biz.RoleUsecase
+	CasbinUsecase *biz.CasbinUsecase
+}
@@ -6,9 +6,11 @@ package di
 
 import (
 	"server/internal/core"
-	"server/internal/core/server"
 	"server/internal/middleware"
 	imApi "server/internal/module/im/api"
+	imBiz "server/internal/module/im/biz"
+	"server/internal/module/im/gateway"
+	imRepo "server/internal/module/im/repo"
 	systemApi "server/internal/module/system/api"
 	"server/internal/module/system/biz"
 	"server/internal/module/system/repo"
@@ -17,13 +19,17 @@ import (
 	wire "github.com/google/wire"
 )
 
-func InitApp(path string) (*server.HTTPServer, error) {
+func InitApp(path string) (*App, error) {
 	wire.Build(
+		wire.Struct(new(App), "*"),
 		core.ProviderSet,
 		router.ProviderSet,
 		middleware.ProviderSet,
 		systemApi.ProviderSet,
 		imApi.ProviderSet,
+		gateway.ProviderSet,
+		imBiz.ProviderSet,
+		imRepo.ProviderSet,
 		biz.ProviderSet,
 		repo.ProviderSet,
 	)
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/pkg"
+	"server/pkg/errorx"
+	"server/pkg/response"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLen = 64
+	maxLogBodySize  = 4 << 10 // 超过该大小的请求体不记录内容
+	redactedValue   = "******"
+)
+
+// sensitiveKeys 字段名包含这些关键字（不区分大小写）时，请求体和查询参数中的值会被脱敏
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "captcha"}
+
+type AccessLogMiddleware struct {
+	logger      logger.Logger
+	maxBodySize int64
+}
+
+func NewAccessLogMiddleware(logger logger.Logger, cfg *config.HTTPServer) *AccessLogMiddleware {
+	return &AccessLogMiddleware{
+		logger:      logger,
+		maxBodySize: cfg.MaxBodySize << 20,
+	}
+}
+
+func (am *AccessLogMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		// 沿用上游传入的请求 ID，便于跨服务串联日志
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.NewRequestIDContext(c.Request.Context(), requestID))
+		log := am.logger.WithContext(c.Request.Context())
+
+		if c.Request.ContentLength > am.maxBodySize {
+			log.Warn("[AccessLog] request body too large", zap.String("path", c.Request.URL.Path), zap.Int64("size", c.Request.ContentLength))
+			response.Fail(c, errorx.ErrRequestTooLarge)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, am.maxBodySize)
+		body := readLogBody(c)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("query", redactQuery(c.Request.URL.RawQuery)),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("user_agent", c.Request.UserAgent()),
+			zap.Int("response_size", c.Writer.Size()),
+		}
+		if userID := pkg.GetUserID(c); userID != 0 {
+			fields = append(fields, zap.Uint("user_id", userID))
+		}
+		if body != "" {
+			fields = append(fields, zap.String("body", body))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Error("[AccessLog]", fields...)
+		case status >= http.StatusBadRequest:
+			log.Warn("[AccessLog]", fields...)
+		default:
+			log.Info("[AccessLog]", fields...)
+		}
+	}
+}
+
+// readLogBody 读取较小的 JSON 请求体用于记录日志，读取后重新放回供后续处理使用
+func readLogBody(c *gin.Context) string {
+	req := c.Request
+	if req.Body == nil || req.ContentLength <= 0 || req.ContentLength > maxLogBodySize {
+		return ""
+	}
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return ""
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	return redactBody(data)
+}
+
+// redactBody 脱敏 JSON 中的敏感字段，无法解析时不记录内容
+func redactBody(data []byte) string {
+	var body any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return ""
+	}
+	result, err := json.Marshal(redactValue(body))
+	if err != nil {
+		return ""
+	}
+	return string(result)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	for key := range values {
+		if isSensitiveKey(key) {
+			values[key] = []string{redactedValue}
+		}
+	}
+	return values.Encode()
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range requestID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
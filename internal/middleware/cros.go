@@ -5,25 +5,41 @@ import (
 	"net/http"
 	"server/internal/core/config"
 	"strings"
+	"sync/atomic"
 )
 
 type corsOptions struct {
+	enabled      bool
 	allowOrigins []string
 }
 
 type CorsMiddleware struct {
-	options *corsOptions
+	options atomic.Pointer[corsOptions] // 配置热更新时整体替换
 }
 
 func NewCorsMiddleware(cfg *config.Cors) *CorsMiddleware {
-	return &CorsMiddleware{options: &corsOptions{allowOrigins: cfg.AllowOrigins}}
+	cm := &CorsMiddleware{}
+	cm.Update(cfg)
+	return cm
+}
+
+// Update 替换跨域配置，配置热更新时调用
+func (cm *CorsMiddleware) Update(cfg *config.Cors) {
+	cm.options.Store(&corsOptions{enabled: cfg.Enabled, allowOrigins: cfg.AllowOrigins})
+}
+
+// AllowOrigin 跨域来源是否在白名单中，未启用跨域时不允许任何跨域来源
+func (cm *CorsMiddleware) AllowOrigin(origin string) bool {
+	options := cm.options.Load()
+	return options.enabled && matchOrigin(origin, options.allowOrigins)
 }
 
 func (cm *CorsMiddleware) Handler() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
+		options := cm.options.Load()
 		origin := c.GetHeader("Origin")
-		if origin == "" || !matchOrigin(origin, cm.options.allowOrigins) {
+		if !options.enabled || origin == "" || !matchOrigin(origin, options.allowOrigins) {
 			c.Next()
 			return
 		}
@@ -27,6 +27,12 @@ func (jm *JwtMiddleware) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从 Header 获取 Authorization: Bearer <token>
 		authHeader := c.GetHeader("Authorization")
+		// 浏览器建立 WebSocket 连接时无法设置请求头，允许通过 token 查询参数传递
+		if authHeader == "" && c.IsWebsocket() {
+			if token := c.Query("token"); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
 		if authHeader == "" {
 			response.Fail(c, errorx.ErrAuthHeaderMissing)
 			c.Abort()
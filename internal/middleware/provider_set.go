@@ -4,6 +4,8 @@ import "github.com/google/wire"
 
 var ProviderSet = wire.NewSet(
 	NewCorsMiddleware,
+	NewAccessLogMiddleware,
+	NewRecoveryMiddleware,
 	NewJwtMiddleware,
 	NewCasbinMiddleware,
 )
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"server/internal/core/logger"
+	"server/pkg"
+	"server/pkg/errorx"
+	"server/pkg/response"
+	"strings"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type (
+	// PanicEvent 一次请求处理中发生的 panic
+	PanicEvent struct {
+		RequestID string
+		Method    string
+		Path      string
+		UserID    uint
+		Err       error
+		Stack     []byte
+	}
+
+	// ErrorSink 错误上报接口，比如 Sentry，上报应当异步进行，避免拖慢响应
+	ErrorSink interface {
+		Report(ctx context.Context, event *PanicEvent)
+	}
+
+	RecoveryMiddleware struct {
+		logger logger.Logger
+		sinks  []ErrorSink
+	}
+)
+
+func NewRecoveryMiddleware(logger logger.Logger, sinks []ErrorSink) *RecoveryMiddleware {
+	return &RecoveryMiddleware{
+		logger: logger,
+		sinks:  sinks,
+	}
+}
+
+func (rm *RecoveryMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// http.ErrAbortHandler 用于主动中断响应，按 net/http 的约定继续向上抛出
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+			ctx := c.Request.Context()
+			log := rm.logger.WithContext(ctx)
+
+			// 客户端已断开连接时无法写入响应，只记录日志
+			if isBrokenPipe(err) {
+				log.Warn("[Recovery] connection broken", zap.String("path", c.Request.URL.Path), zap.Error(err))
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+
+			event := &PanicEvent{
+				RequestID: logger.RequestIDFromContext(ctx),
+				Method:    c.Request.Method,
+				Path:      c.Request.URL.Path,
+				UserID:    pkg.GetUserID(c),
+				Err:       err,
+				Stack:     debug.Stack(),
+			}
+			log.Error("[Recovery] panic recovered",
+				zap.String("method", event.Method),
+				zap.String("path", event.Path),
+				zap.Uint("user_id", event.UserID),
+				zap.Error(err),
+				zap.ByteString("stack", event.Stack),
+			)
+			for _, sink := range rm.sinks {
+				sink.Report(ctx, event)
+			}
+
+			response.Fail(c, errorx.ErrInternal.Wrap(err))
+		}()
+		c.Next()
+	}
+}
+
+func isBrokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if errors.As(opErr, &syscallErr) {
+		if errors.Is(syscallErr.Err, syscall.EPIPE) || errors.Is(syscallErr.Err, syscall.ECONNRESET) {
+			return true
+		}
+		msg := strings.ToLower(syscallErr.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+	return false
+}
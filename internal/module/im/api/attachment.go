@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz"
+	_ "server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg"
+	"server/pkg/response"
+	"server/pkg/validatex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AttachmentApi struct {
+	logger            logger.Logger
+	attachmentUsecase *biz.AttachmentUsecase
+}
+
+func NewAttachmentApi(logger logger.Logger, attachmentUsecase *biz.AttachmentUsecase) *AttachmentApi {
+	return &AttachmentApi{
+		logger:            logger,
+		attachmentUsecase: attachmentUsecase,
+	}
+}
+
+func (a *AttachmentApi) InitAttachmentApi(router *gin.RouterGroup) {
+	router.POST("", a.Upload)
+	router.GET("url", a.URL)
+}
+
+// InitAttachmentFileApi 本地存储的文件下载，通过签名校验访问权限，不需要登录
+func (a *AttachmentApi) InitAttachmentFileApi(router *gin.RouterGroup) {
+	router.GET("*key", a.File)
+}
+
+// Upload godoc
+// @Summary 上传附件
+// @Description 上传图片、文件或语音，返回的 attachmentId 作为图片、文件、语音消息的内容发送：{"attachmentId":1}；
+// @Description 文件类型按内容识别，图片超过缩略图尺寸时生成缩略图
+// @Tags IM
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param kind formData string true "附件类型" Enums(image, file, voice)
+// @Param duration formData int false "语音时长（秒）"
+// @Param file formData file true "文件"
+// @Success 200 {object} server_internal_module_im_model_reply.AttachmentReply
+// @Router /api/im/attachment [post]
+func (a *AttachmentApi) Upload(c *gin.Context) {
+	var req request.UploadAttachmentReq
+	if err := validatex.BindForm(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.attachmentUsecase.Upload(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[AttachmentApi] Upload error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// URL godoc
+// @Summary 获取消息附件的下载地址
+// @Description 下载地址过期后调用，重新生成图片、文件、语音消息的下载地址和缩略图地址
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param conversationId query int true "会话ID"
+// @Param seq query int true "消息序号"
+// @Success 200 {object} server_internal_module_im_model_reply.AttachmentReply
+// @Router /api/im/attachment/url [get]
+func (a *AttachmentApi) URL(c *gin.Context) {
+	var req request.MessageTargetReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.attachmentUsecase.URL(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[AttachmentApi] URL error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// File godoc
+// @Summary 下载附件
+// @Description 本地存储时由服务提供下载，地址由上传或获取下载地址接口返回，过期后不能访问
+// @Tags IM
+// @Produce octet-stream
+// @Param key path string true "存储路径"
+// @Param expires query int true "过期时间戳（秒）"
+// @Param sign query string true "签名"
+// @Success 200 {file} file
+// @Router /api/im/attachment/file/{key} [get]
+func (a *AttachmentApi) File(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	file, contentType, err := a.attachmentUsecase.Open(c, key, c.Request.URL.Query())
+	if err != nil {
+		a.logger.WithContext(c).Error("[AttachmentApi] File error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	defer file.Close()
+
+	headers := map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "sandbox; default-src 'none'",
+		"Cache-Control":           "private, max-age=3600",
+	}
+	// 图片和语音可直接在客户端展示，其他文件一律作为下载处理，避免上传的 HTML 等内容在站点域名下执行
+	if !strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "audio/") {
+		headers["Content-Disposition"] = "attachment"
+	}
+	c.DataFromReader(http.StatusOK, -1, contentType, file, headers)
+}
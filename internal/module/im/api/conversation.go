@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz"
+	"server/internal/module/im/gateway"
+	_ "server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg"
+	"server/pkg/response"
+	"server/pkg/validatex"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ConversationApi struct {
+	logger              logger.Logger
+	conversationUsecase *biz.ConversationUsecase
+}
+
+func NewConversationApi(logger logger.Logger, conversationUsecase *biz.ConversationUsecase, hub *gateway.Hub) *ConversationApi {
+	a := &ConversationApi{
+		logger:              logger,
+		conversationUsecase: conversationUsecase,
+	}
+	hub.Handle("conversation.list", a.wsList)
+	return a
+}
+
+func (a *ConversationApi) InitConversationApi(router *gin.RouterGroup) {
+	router.GET("list", a.List)
+}
+
+// List godoc
+// @Summary 会话列表
+// @Description 携带未读数和最后一条消息，按更新时间升序排列；重连时传入上次同步的 updatedAfter 只拉取有变化的会话
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param updatedAfter query int false "毫秒时间戳，只返回之后有变化的会话"
+// @Param afterConversationId query int false "分页游标，上一页最后一条的 conversationId"
+// @Param limit query int false "返回条数，默认 100，最大 500"
+// @Success 200 {object} server_internal_module_im_model_reply.ConversationListReply
+// @Router /api/im/conversation/list [get]
+func (a *ConversationApi) List(c *gin.Context) {
+	var req request.ListConversationReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.conversationUsecase.List(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[ConversationApi] List error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+func (a *ConversationApi) wsList(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.ListConversationReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.conversationUsecase.List(ctx, uint64(conn.UserID()), &req)
+}
@@ -1,19 +1,94 @@
 package api
 
+import (
+	"github.com/gin-gonic/gin"
+	"server/internal/middleware"
+)
+
 type IMApi struct {
-	UserApi    *UserApi
-	MessageApi *MessageApi
-	GroupApi   *GroupApi
+	jwtMiddleware    *middleware.JwtMiddleware
+	casbinMiddleware *middleware.CasbinMiddleware
+	UserApi          *UserApi
+	MessageApi       *MessageApi
+	GroupApi         *GroupApi
+	ConversationApi  *ConversationApi
+	PresenceApi      *PresenceApi
+	GatewayApi       *GatewayApi
+	AttachmentApi    *AttachmentApi
+	ModerationApi    *ModerationApi
 }
 
 func NewIMApi(
+	jwtMiddleware *middleware.JwtMiddleware,
+	casbinMiddleware *middleware.CasbinMiddleware,
 	userApi *UserApi,
 	messageApi *MessageApi,
 	groupApi *GroupApi,
+	conversationApi *ConversationApi,
+	presenceApi *PresenceApi,
+	gatewayApi *GatewayApi,
+	attachmentApi *AttachmentApi,
+	moderationApi *ModerationApi,
 ) *IMApi {
 	return &IMApi{
-		UserApi:    userApi,
-		MessageApi: messageApi,
-		GroupApi:   groupApi,
+		jwtMiddleware:    jwtMiddleware,
+		casbinMiddleware: casbinMiddleware,
+		UserApi:          userApi,
+		MessageApi:       messageApi,
+		GroupApi:         groupApi,
+		ConversationApi:  conversationApi,
+		PresenceApi:      presenceApi,
+		GatewayApi:       gatewayApi,
+		AttachmentApi:    attachmentApi,
+		ModerationApi:    moderationApi,
+	}
+}
+
+// InitIMApi IM 接口面向全部登录用户，只校验登录状态，不做 Casbin 权限校验；admin 下的后台管理接口需要 Casbin 授权
+func (r *IMApi) InitIMApi(router *gin.RouterGroup) {
+	publicRouter := router.Group("")
+	{
+		// 下载地址带签名，浏览器直接访问时无法携带 token
+		fileRouter := publicRouter.Group("attachment/file")
+		r.AttachmentApi.InitAttachmentFileApi(fileRouter)
+	}
+
+	privateRouter := router.Group("")
+	privateRouter.Use(r.jwtMiddleware.Handler())
+
+	{
+		wsRouter := privateRouter.Group("ws")
+		r.GatewayApi.InitGatewayApi(wsRouter)
+	}
+	{
+		messageRouter := privateRouter.Group("message")
+		r.MessageApi.InitMessageApi(messageRouter)
+	}
+	{
+		conversationRouter := privateRouter.Group("conversation")
+		r.ConversationApi.InitConversationApi(conversationRouter)
+	}
+	{
+		presenceRouter := privateRouter.Group("presence")
+		r.PresenceApi.InitPresenceApi(presenceRouter)
+	}
+	{
+		groupRouter := privateRouter.Group("group")
+		r.GroupApi.InitGroupApi(groupRouter)
+	}
+	{
+		userRouter := privateRouter.Group("user")
+		r.UserApi.InitUserApi(userRouter)
+	}
+	{
+		attachmentRouter := privateRouter.Group("attachment")
+		r.AttachmentApi.InitAttachmentApi(attachmentRouter)
+	}
+
+	adminRouter := router.Group("admin")
+	adminRouter.Use(r.jwtMiddleware.Handler(), r.casbinMiddleware.Handler())
+	{
+		moderationRouter := adminRouter.Group("moderation")
+		r.ModerationApi.InitModerationApi(moderationRouter)
 	}
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"server/internal/core/logger"
+	"server/internal/middleware"
+	"server/internal/module/im/gateway"
+	"server/pkg"
+	"server/pkg/errorx"
+	"server/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+type GatewayApi struct {
+	logger   logger.Logger
+	hub      *gateway.Hub
+	upgrader *websocket.Upgrader
+}
+
+func NewGatewayApi(logger logger.Logger, hub *gateway.Hub, cors *middleware.CorsMiddleware) *GatewayApi {
+	return &GatewayApi{
+		logger: logger,
+		hub:    hub,
+		upgrader: &websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			// 同源请求直接放行，跨域请求按 CORS 白名单校验
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+					return true
+				}
+				return cors.AllowOrigin(origin)
+			},
+		},
+	}
+}
+
+func (a *GatewayApi) InitGatewayApi(router *gin.RouterGroup) {
+	router.GET("", a.Connect)
+}
+
+// Connect
+// @Summary 建立 IM 长连接
+// @Description 升级为 WebSocket 连接，浏览器无法设置请求头时可通过 token 查询参数传递访问令牌；协议说明见 internal/module/im/gateway/frame.go
+// @Tags IM
+// @Security Bearer
+// @Param token query string false "访问令牌，未设置 Authorization 请求头时使用"
+// @Param device query string false "设备类型，比如 web、ios、android"
+// @Success 101 "Switching Protocols"
+// @Router /api/im/ws [get]
+func (a *GatewayApi) Connect(c *gin.Context) {
+	userID := pkg.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorx.ErrUnauthorized)
+		return
+	}
+
+	ws, err := a.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已写入错误响应
+		a.logger.WithContext(c).Warn("[GatewayApi] upgrade error", zap.Uint("userId", userID), zap.Error(err))
+		return
+	}
+
+	device := c.DefaultQuery("device", "web")
+	lang := errorx.MatchLanguage(c.GetHeader("Accept-Language"))
+	a.hub.Serve(c.Request.Context(), ws, userID, device, lang)
+}
@@ -1,8 +1,467 @@
 package api
 
+import (
+	"context"
+	"encoding/json"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz"
+	"server/internal/module/im/gateway"
+	_ "server/internal/module/im/model"
+	_ "server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg"
+	"server/pkg/response"
+	"server/pkg/validatex"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
 type GroupApi struct {
+	logger            logger.Logger
+	groupUsecase      *biz.GroupUsecase
+	messageUsecase    *biz.MessageUsecase
+	moderationUsecase *biz.ModerationUsecase
+}
+
+func NewGroupApi(
+	logger logger.Logger,
+	groupUsecase *biz.GroupUsecase,
+	messageUsecase *biz.MessageUsecase,
+	moderationUsecase *biz.ModerationUsecase,
+	hub *gateway.Hub,
+) *GroupApi {
+	a := &GroupApi{
+		logger:            logger,
+		groupUsecase:      groupUsecase,
+		messageUsecase:    messageUsecase,
+		moderationUsecase: moderationUsecase,
+	}
+	hub.Handle("group.send", a.wsSend)
+	return a
+}
+
+func (a *GroupApi) InitGroupApi(router *gin.RouterGroup) {
+	router.POST("", a.Create)
+	router.PUT("", a.Update)
+	router.GET("list", a.List)
+	router.GET("detail", a.Detail)
+	router.GET("members", a.Members)
+	router.PUT("announcement", a.UpdateAnnouncement)
+	router.POST("invite", a.Invite)
+	router.POST("join", a.Join)
+	router.POST("leave", a.Leave)
+	router.POST("kick", a.Kick)
+	router.PUT("role", a.SetRole)
+	router.PUT("transfer", a.Transfer)
+	router.PUT("mute", a.Mute)
+	router.GET("moderation", a.Moderation)
+	router.PUT("moderation", a.UpdateModeration)
+	router.POST("message", a.SendMessage)
+}
+
+// Create godoc
+// @Summary 创建群组
+// @Description 创建者成为群主，memberIds 中的用户直接加入
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.CreateGroupReq true "请求参数"
+// @Success 200 {object} model.Group
+// @Router /api/im/group [post]
+func (a *GroupApi) Create(c *gin.Context) {
+	var req request.CreateGroupReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	result, err := a.groupUsecase.Create(c, userId, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Create error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Update godoc
+// @Summary 修改群资料
+// @Description 仅群主和管理员可操作
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.UpdateGroupReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group [put]
+func (a *GroupApi) Update(c *gin.Context) {
+	var req request.UpdateGroupReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.Update(c, userId, &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Update error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// List godoc
+// @Summary 我的群组
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} model.Group
+// @Router /api/im/group/list [get]
+func (a *GroupApi) List(c *gin.Context) {
+	userId := uint64(pkg.GetUserID(c))
+	result, err := a.groupUsecase.List(c, userId)
+	if err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] List error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Detail godoc
+// @Summary 群组详情
+// @Description 非成员也可查看，role 为当前用户在群内的角色
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param groupId query int true "群组ID"
+// @Success 200 {object} server_internal_module_im_model_reply.GroupDetailReply
+// @Router /api/im/group/detail [get]
+func (a *GroupApi) Detail(c *gin.Context) {
+	var req request.GroupIdReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	result, err := a.groupUsecase.Detail(c, userId, req.GroupId)
+	if err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Detail error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Members godoc
+// @Summary 群成员列表
+// @Description 仅成员可查看
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param groupId query int true "群组ID"
+// @Success 200 {array} model.GroupMember
+// @Router /api/im/group/members [get]
+func (a *GroupApi) Members(c *gin.Context) {
+	var req request.GroupIdReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	result, err := a.groupUsecase.Members(c, userId, req.GroupId)
+	if err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Members error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// UpdateAnnouncement godoc
+// @Summary 修改群公告
+// @Description 仅群主和管理员可操作
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.UpdateGroupAnnouncementReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/announcement [put]
+func (a *GroupApi) UpdateAnnouncement(c *gin.Context) {
+	var req request.UpdateGroupAnnouncementReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.UpdateAnnouncement(c, userId, &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] UpdateAnnouncement error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Invite godoc
+// @Summary 邀请成员
+// @Description 任何成员都可以邀请，已是成员的用户会被忽略
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.InviteGroupMemberReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/invite [post]
+func (a *GroupApi) Invite(c *gin.Context) {
+	var req request.InviteGroupMemberReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.Invite(c, userId, &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Invite error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Join godoc
+// @Summary 加入群组
+// @Description 仅允许直接加入的群组可用
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.GroupIdReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/join [post]
+func (a *GroupApi) Join(c *gin.Context) {
+	var req request.GroupIdReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.Join(c, userId, req.GroupId); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Join error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Leave godoc
+// @Summary 退出群组
+// @Description 群主需先转让群组
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.GroupIdReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/leave [post]
+func (a *GroupApi) Leave(c *gin.Context) {
+	var req request.GroupIdReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.Leave(c, userId, req.GroupId); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Leave error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Kick godoc
+// @Summary 移出成员
+// @Description 群主可移出任何人，管理员只能移出普通成员
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.GroupMemberReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/kick [post]
+func (a *GroupApi) Kick(c *gin.Context) {
+	var req request.GroupMemberReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.Kick(c, userId, &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Kick error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// SetRole godoc
+// @Summary 设置成员角色
+// @Description 设置或取消管理员，仅群主可操作
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.SetGroupRoleReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/role [put]
+func (a *GroupApi) SetRole(c *gin.Context) {
+	var req request.SetGroupRoleReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.SetRole(c, userId, &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] SetRole error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Transfer godoc
+// @Summary 转让群主
+// @Description 原群主成为管理员
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.GroupMemberReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/transfer [put]
+func (a *GroupApi) Transfer(c *gin.Context) {
+	var req request.GroupMemberReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.Transfer(c, userId, &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Transfer error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Mute godoc
+// @Summary 禁言成员
+// @Description duration 为 0 时解除禁言，只能禁言角色低于自己的成员
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.MuteGroupMemberReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/mute [put]
+func (a *GroupApi) Mute(c *gin.Context) {
+	var req request.MuteGroupMemberReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	if err := a.groupUsecase.Mute(c, userId, &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Mute error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Moderation godoc
+// @Summary 群审核设置
+// @Description 仅群主和管理员可查看，未设置时返回默认值
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param groupId query int true "群组ID"
+// @Success 200 {object} server_internal_module_im_model.GroupModeration
+// @Router /api/im/group/moderation [get]
+func (a *GroupApi) Moderation(c *gin.Context) {
+	var req request.GroupIdReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.moderationUsecase.GroupModeration(c, uint64(pkg.GetUserID(c)), req.GroupId)
+	if err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] Moderation error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// UpdateModeration godoc
+// @Summary 修改群审核设置
+// @Description 仅群主和管理员可操作；全局敏感词对所有群生效，群自定义敏感词和严格模式只能在此基础上加严
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.GroupModerationReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/group/moderation [put]
+func (a *GroupApi) UpdateModeration(c *gin.Context) {
+	var req request.GroupModerationReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.moderationUsecase.UpdateGroupModeration(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] UpdateModeration error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// SendMessage godoc
+// @Summary 发送群聊消息
+// @Description 相同 clientMsgId 重复提交时返回已保存的消息，不会重复发送
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.SendGroupMessageReq true "请求参数"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageReply
+// @Router /api/im/group/message [post]
+func (a *GroupApi) SendMessage(c *gin.Context) {
+	var req request.SendGroupMessageReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	userId := uint64(pkg.GetUserID(c))
+	result, err := a.messageUsecase.SendGroup(c, userId, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[GroupApi] SendMessage error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
 }
 
-func NewGroupApi() *GroupApi {
-	return &GroupApi{}
+// wsSend 处理长连接上的 group.send 事件，请求体与 HTTP 接口一致
+func (a *GroupApi) wsSend(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.SendGroupMessageReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.SendGroup(ctx, uint64(conn.UserID()), &req)
 }
@@ -1,7 +1,394 @@
 package api
 
-type MessageApi struct{}
+import (
+	"context"
+	"encoding/json"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz"
+	"server/internal/module/im/gateway"
+	_ "server/internal/module/im/model"
+	_ "server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg"
+	"server/pkg/response"
+	"server/pkg/validatex"
 
-func NewMessageApi() *MessageApi {
-	return &MessageApi{}
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type MessageApi struct {
+	logger         logger.Logger
+	messageUsecase *biz.MessageUsecase
+}
+
+func NewMessageApi(logger logger.Logger, messageUsecase *biz.MessageUsecase, hub *gateway.Hub) *MessageApi {
+	a := &MessageApi{
+		logger:         logger,
+		messageUsecase: messageUsecase,
+	}
+	hub.Handle("message.send", a.wsSend)
+	hub.Handle("message.delivered", a.wsDelivered)
+	hub.Handle("message.read", a.wsRead)
+	hub.Handle("message.sync", a.wsSync)
+	hub.Handle("message.history", a.wsHistory)
+	hub.Handle("message.recall", a.wsRecall)
+	hub.Handle("message.edit", a.wsEdit)
+	hub.Handle("message.reaction.add", a.wsAddReaction)
+	hub.Handle("message.reaction.remove", a.wsRemoveReaction)
+	// 客户端确认收到 message.new 推送即视为已送达
+	hub.HandleAck(biz.EventMessageNew, a.wsPushAck)
+	return a
+}
+
+func (a *MessageApi) InitMessageApi(router *gin.RouterGroup) {
+	router.POST("", a.Send)
+	router.POST("delivered", a.Delivered)
+	router.POST("read", a.Read)
+	router.GET("sync", a.Sync)
+	router.GET("history", a.History)
+	router.POST("recall", a.Recall)
+	router.POST("edit", a.Edit)
+	router.GET("edits", a.Edits)
+	router.POST("reaction", a.AddReaction)
+	router.DELETE("reaction", a.RemoveReaction)
+}
+
+// Send godoc
+// @Summary 发送单聊消息
+// @Description 相同 clientMsgId 重复提交时返回已保存的消息，不会重复发送
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.SendMessageReq true "消息内容"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageReply
+// @Router /api/im/message [post]
+func (a *MessageApi) Send(c *gin.Context) {
+	var req request.SendMessageReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.Send(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] Send error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Delivered godoc
+// @Summary 消息送达回执
+// @Description 标记会话中 seq 及之前的消息已送达
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.MessageAckReq true "回执"
+// @Success 200 {string} string "success"
+// @Router /api/im/message/delivered [post]
+func (a *MessageApi) Delivered(c *gin.Context) {
+	var req request.MessageAckReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.messageUsecase.Delivered(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] Delivered error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Read godoc
+// @Summary 消息已读回执
+// @Description 标记会话中 seq 及之前的消息已读
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.MessageAckReq true "回执"
+// @Success 200 {string} string "success"
+// @Router /api/im/message/read [post]
+func (a *MessageApi) Read(c *gin.Context) {
+	var req request.MessageAckReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.messageUsecase.Read(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] Read error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Sync godoc
+// @Summary 同步离线消息
+// @Description 返回会话中 afterSeq 之后的消息，hasMore 为 true 时以最后一条的 seq 继续拉取；
+// @Description 传入 changedAfter 时 changes 返回已有消息在此之后的撤回、编辑和表情回应变化
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param conversationId query int true "会话ID"
+// @Param afterSeq query int false "客户端已有的最新消息序号"
+// @Param changedAfter query int false "毫秒时间戳，上次同步到的变化的 updatedAt"
+// @Param changedAfterSeq query int false "上次同步到的变化的 seq，与 changedAfter 组成分页游标"
+// @Param limit query int false "返回条数，默认 100，最大 500"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageListReply
+// @Router /api/im/message/sync [get]
+func (a *MessageApi) Sync(c *gin.Context) {
+	var req request.SyncMessageReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.Sync(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] Sync error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// History godoc
+// @Summary 历史消息
+// @Description 从 beforeSeq 向前分页，结果按 seq 升序排列，hasMore 为 true 时以第一条的 seq 继续向前翻页
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param conversationId query int true "会话ID"
+// @Param beforeSeq query int false "返回该序号之前的消息，为 0 时从最新消息开始"
+// @Param limit query int false "返回条数，默认 20，最大 100"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageListReply
+// @Router /api/im/message/history [get]
+func (a *MessageApi) History(c *gin.Context) {
+	var req request.MessageHistoryReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.History(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] History error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Recall godoc
+// @Summary 撤回消息
+// @Description 只能撤回自己发送且未超过撤回时间的消息，会话成员通过 message.recalled 事件收到撤回
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.MessageTargetReq true "请求参数"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageReply
+// @Router /api/im/message/recall [post]
+func (a *MessageApi) Recall(c *gin.Context) {
+	var req request.MessageTargetReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.Recall(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] Recall error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Edit godoc
+// @Summary 编辑消息
+// @Description 只能编辑自己发送的文本消息，会话成员通过 message.edited 事件收到编辑后的消息
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.EditMessageReq true "请求参数"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageReply
+// @Router /api/im/message/edit [post]
+func (a *MessageApi) Edit(c *gin.Context) {
+	var req request.EditMessageReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.Edit(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] Edit error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Edits godoc
+// @Summary 消息编辑历史
+// @Description 返回每次编辑前的内容，按编辑时间升序排列
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param conversationId query int true "会话ID"
+// @Param seq query int true "消息序号"
+// @Success 200 {array} model.MessageEdit
+// @Router /api/im/message/edits [get]
+func (a *MessageApi) Edits(c *gin.Context) {
+	var req request.MessageTargetReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.Edits(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] Edits error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// AddReaction godoc
+// @Summary 添加表情回应
+// @Description 会话成员通过 message.reaction 事件收到携带最新表情回应的消息
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.MessageReactionReq true "请求参数"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageReply
+// @Router /api/im/message/reaction [post]
+func (a *MessageApi) AddReaction(c *gin.Context) {
+	var req request.MessageReactionReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.AddReaction(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] AddReaction error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// RemoveReaction godoc
+// @Summary 取消表情回应
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.MessageReactionReq true "请求参数"
+// @Success 200 {object} server_internal_module_im_model_reply.MessageReply
+// @Router /api/im/message/reaction [delete]
+func (a *MessageApi) RemoveReaction(c *gin.Context) {
+	var req request.MessageReactionReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.messageUsecase.RemoveReaction(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MessageApi] RemoveReaction error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// wsSend 处理长连接上的 message.send 事件，请求体与 HTTP 接口一致
+func (a *MessageApi) wsSend(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.SendMessageReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.Send(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsDelivered(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.MessageAckReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return nil, a.messageUsecase.Delivered(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsRead(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.MessageAckReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return nil, a.messageUsecase.Read(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsSync(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.SyncMessageReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.Sync(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsHistory(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.MessageHistoryReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.History(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsRecall(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.MessageTargetReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.Recall(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsEdit(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.EditMessageReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.Edit(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsAddReaction(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.MessageReactionReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.AddReaction(ctx, uint64(conn.UserID()), &req)
+}
+
+func (a *MessageApi) wsRemoveReaction(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.MessageReactionReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.messageUsecase.RemoveReaction(ctx, uint64(conn.UserID()), &req)
+}
+
+// wsPushAck 客户端确认 message.new 推送时携带 {"conversationId":1,"seq":1}
+func (a *MessageApi) wsPushAck(ctx context.Context, conn *gateway.Conn, _ uint64, data json.RawMessage) {
+	var req request.MessageAckReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return
+	}
+	if err := a.messageUsecase.Delivered(ctx, uint64(conn.UserID()), &req); err != nil {
+		a.logger.WithContext(ctx).Warn("[MessageApi] push ack error", zap.Uint("userId", conn.UserID()), zap.Error(err))
+	}
 }
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"server/internal/core/logger"
+	"server/internal/module/im/biz"
+	_ "server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg"
+	"server/pkg/response"
+	"server/pkg/validatex"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ModerationApi 内容审核的后台管理接口，需要 Casbin 授权
+type ModerationApi struct {
+	logger            logger.Logger
+	moderationUsecase *biz.ModerationUsecase
+}
+
+func NewModerationApi(logger logger.Logger, moderationUsecase *biz.ModerationUsecase) *ModerationApi {
+	return &ModerationApi{
+		logger:            logger,
+		moderationUsecase: moderationUsecase,
+	}
+}
+
+func (a *ModerationApi) InitModerationApi(router *gin.RouterGroup) {
+	router.GET("word/list", a.ListWords)
+	router.POST("word", a.CreateWords)
+	router.PUT("word", a.UpdateWord)
+	router.DELETE("word/:id", a.DeleteWord)
+	router.GET("review/list", a.ListReviews)
+	router.POST("review", a.ResolveReview)
+}
+
+// ListWords godoc
+// @Summary 获取敏感词列表
+// @Tags IM 内容审核
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param current query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param keyword query string false "敏感词"
+// @Param category query string false "分类"
+// @Param action query string false "动作" Enums(mask, review, block)
+// @Success 200 {object} server_internal_module_system_model_reply.PageReply
+// @Router /api/im/admin/moderation/word/list [get]
+func (a *ModerationApi) ListWords(c *gin.Context) {
+	var req request.SensitiveWordListReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.moderationUsecase.ListWords(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[ModerationApi] ListWords error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// CreateWords godoc
+// @Summary 添加敏感词
+// @Description 支持批量导入，敏感词按归一化后的形式（小写、全角转半角、去除空白和标点）保存，已存在的词会跳过
+// @Tags IM 内容审核
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.CreateSensitiveWordReq true "敏感词"
+// @Success 200 {object} server_internal_module_im_model_reply.CreateSensitiveWordReply
+// @Router /api/im/admin/moderation/word [post]
+func (a *ModerationApi) CreateWords(c *gin.Context) {
+	var req request.CreateSensitiveWordReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.moderationUsecase.CreateWords(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[ModerationApi] CreateWords error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// UpdateWord godoc
+// @Summary 修改敏感词
+// @Tags IM 内容审核
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.UpdateSensitiveWordReq true "敏感词"
+// @Success 200 {string} string "success"
+// @Router /api/im/admin/moderation/word [put]
+func (a *ModerationApi) UpdateWord(c *gin.Context) {
+	var req request.UpdateSensitiveWordReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.moderationUsecase.UpdateWord(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[ModerationApi] UpdateWord error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// DeleteWord godoc
+// @Summary 删除敏感词
+// @Tags IM 内容审核
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "敏感词ID"
+// @Success 200 {string} string "success"
+// @Router /api/im/admin/moderation/word/{id} [delete]
+func (a *ModerationApi) DeleteWord(c *gin.Context) {
+	var req request.SensitiveWordIdReq
+	if err := validatex.BindUri(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.moderationUsecase.DeleteWord(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[ModerationApi] DeleteWord error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// ListReviews godoc
+// @Summary 获取人工审核队列
+// @Description 待审核的记录按提交顺序排列，其他状态按时间倒序排列
+// @Tags IM 内容审核
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param current query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param status query int false "状态（1待审核 2通过 3不通过）"
+// @Success 200 {object} server_internal_module_system_model_reply.PageReply
+// @Router /api/im/admin/moderation/review/list [get]
+func (a *ModerationApi) ListReviews(c *gin.Context) {
+	var req request.ModerationReviewListReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.moderationUsecase.ListReviews(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[ModerationApi] ListReviews error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// ResolveReview godoc
+// @Summary 处理审核记录
+// @Description 不通过时撤回消息并通知会话成员，每条记录只能处理一次
+// @Tags IM 内容审核
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.ResolveReviewReq true "审核结果"
+// @Success 200 {string} string "success"
+// @Router /api/im/admin/moderation/review [post]
+func (a *ModerationApi) ResolveReview(c *gin.Context) {
+	var req request.ResolveReviewReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.moderationUsecase.ResolveReview(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[ModerationApi] ResolveReview error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
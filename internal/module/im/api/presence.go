@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz"
+	"server/internal/module/im/gateway"
+	_ "server/internal/module/im/model"
+	"server/internal/module/im/model/request"
+	"server/pkg"
+	"server/pkg/response"
+	"server/pkg/validatex"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type PresenceApi struct {
+	logger          logger.Logger
+	presenceUsecase *biz.PresenceUsecase
+}
+
+func NewPresenceApi(logger logger.Logger, presenceUsecase *biz.PresenceUsecase, hub *gateway.Hub) *PresenceApi {
+	a := &PresenceApi{
+		logger:          logger,
+		presenceUsecase: presenceUsecase,
+	}
+	hub.Handle("presence.set", a.wsSet)
+	hub.Handle("presence.subscribe", a.wsSubscribe)
+	hub.Handle("presence.unsubscribe", a.wsUnsubscribe)
+	hub.Handle("typing", a.wsTyping)
+	return a
+}
+
+func (a *PresenceApi) InitPresenceApi(router *gin.RouterGroup) {
+	router.GET("", a.Find)
+}
+
+// Find godoc
+// @Summary 查询在线状态
+// @Description 只返回好友或同在某个会话中、且未拉黑当前用户的用户的状态；订阅在线状态变化及输入状态请通过长连接的 presence.subscribe、typing 事件
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param userIds query []int true "用户ID列表" collectionFormat(multi)
+// @Success 200 {array} model.Presence
+// @Router /api/im/presence [get]
+func (a *PresenceApi) Find(c *gin.Context) {
+	var req request.PresenceReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.presenceUsecase.Find(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[PresenceApi] Find error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// wsSet 设置在线时展示的状态（online、away）
+func (a *PresenceApi) wsSet(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.SetPresenceReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return nil, a.presenceUsecase.SetStatus(ctx, uint64(conn.UserID()), &req)
+}
+
+// wsSubscribe 订阅用户在线状态，ack 返回当前状态，之后的变化以 presence.changed 推送
+func (a *PresenceApi) wsSubscribe(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.PresenceReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return a.presenceUsecase.Subscribe(ctx, conn, &req)
+}
+
+func (a *PresenceApi) wsUnsubscribe(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.PresenceReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	a.presenceUsecase.Unsubscribe(ctx, conn, &req)
+	return nil, nil
+}
+
+// wsTyping 输入状态只在长连接上转发，不提供 HTTP 接口
+func (a *PresenceApi) wsTyping(ctx context.Context, conn *gateway.Conn, data json.RawMessage) (any, error) {
+	var req request.TypingReq
+	if err := validatex.DecodeJSON(data, &req, conn.Lang()); err != nil {
+		return nil, err
+	}
+	return nil, a.presenceUsecase.Typing(ctx, conn, &req)
+}
@@ -7,4 +7,9 @@ var ProviderSet = wire.NewSet(
 	NewUserApi,
 	NewGroupApi,
 	NewMessageApi,
+	NewConversationApi,
+	NewPresenceApi,
+	NewGatewayApi,
+	NewAttachmentApi,
+	NewModerationApi,
 )
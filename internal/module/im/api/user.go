@@ -1,7 +1,317 @@
 package api
 
-type UserApi struct{}
+import (
+	"server/internal/core/logger"
+	"server/internal/module/im/biz"
+	_ "server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg"
+	"server/pkg/response"
+	"server/pkg/validatex"
 
-func NewUserApi() *UserApi {
-	return &UserApi{}
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type UserApi struct {
+	logger         logger.Logger
+	contactUsecase *biz.ContactUsecase
+}
+
+func NewUserApi(logger logger.Logger, contactUsecase *biz.ContactUsecase) *UserApi {
+	return &UserApi{
+		logger:         logger,
+		contactUsecase: contactUsecase,
+	}
+}
+
+func (a *UserApi) InitUserApi(router *gin.RouterGroup) {
+	router.GET("search", a.Search)
+	router.GET("profiles", a.Profiles)
+	router.POST("friend/request", a.SendFriendRequest)
+	router.GET("friend/request/list", a.ListFriendRequests)
+	router.POST("friend/request/accept", a.AcceptFriendRequest)
+	router.POST("friend/request/reject", a.RejectFriendRequest)
+	router.GET("friend/list", a.ListFriends)
+	router.PUT("friend", a.UpdateFriend)
+	router.DELETE("friend/:userId", a.DeleteFriend)
+	router.GET("block/list", a.ListBlocks)
+	router.POST("block", a.Block)
+	router.DELETE("block/:userId", a.Unblock)
+}
+
+// Search godoc
+// @Summary 搜索用户
+// @Description 按用户名精确匹配或昵称前缀匹配，只返回公开资料，最多 20 条
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param keyword query string true "关键字"
+// @Success 200 {array} server_internal_module_im_model_reply.SearchUserReply
+// @Router /api/im/user/search [get]
+func (a *UserApi) Search(c *gin.Context) {
+	var req request.SearchUserReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.contactUsecase.Search(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[UserApi] Search error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Profiles godoc
+// @Summary 批量查询用户资料
+// @Description 只返回已启用用户的公开资料
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param userIds query []int true "用户ID列表" collectionFormat(multi)
+// @Success 200 {array} server_internal_module_im_model_reply.UserProfileReply
+// @Router /api/im/user/profiles [get]
+func (a *UserApi) Profiles(c *gin.Context) {
+	var req request.PresenceReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.contactUsecase.Profiles(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[UserApi] Profiles error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// SendFriendRequest godoc
+// @Summary 发送好友申请
+// @Description 重复申请会更新附言；对方已向自己发出申请时直接成为好友；对方通过 friend.request 事件收到申请
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.FriendRequestReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/user/friend/request [post]
+func (a *UserApi) SendFriendRequest(c *gin.Context) {
+	var req request.FriendRequestReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.contactUsecase.SendRequest(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] SendFriendRequest error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// ListFriendRequests godoc
+// @Summary 好友申请列表
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param type query string false "received 收到的（默认），sent 发出的" Enums(received, sent)
+// @Success 200 {array} server_internal_module_im_model_reply.FriendRequestReply
+// @Router /api/im/user/friend/request/list [get]
+func (a *UserApi) ListFriendRequests(c *gin.Context) {
+	var req request.ListFriendRequestReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.contactUsecase.ListRequests(c, uint64(pkg.GetUserID(c)), &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[UserApi] ListFriendRequests error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// AcceptFriendRequest godoc
+// @Summary 同意好友申请
+// @Description 双方通过 friend.added 事件收到新好友
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.HandleFriendRequestReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/user/friend/request/accept [post]
+func (a *UserApi) AcceptFriendRequest(c *gin.Context) {
+	var req request.HandleFriendRequestReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.contactUsecase.Accept(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] AcceptFriendRequest error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// RejectFriendRequest godoc
+// @Summary 拒绝好友申请
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.HandleFriendRequestReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/user/friend/request/reject [post]
+func (a *UserApi) RejectFriendRequest(c *gin.Context) {
+	var req request.HandleFriendRequestReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.contactUsecase.Reject(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] RejectFriendRequest error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// ListFriends godoc
+// @Summary 好友列表
+// @Description 按联系人分组排序，携带备注和公开资料
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} server_internal_module_im_model_reply.FriendReply
+// @Router /api/im/user/friend/list [get]
+func (a *UserApi) ListFriends(c *gin.Context) {
+	result, err := a.contactUsecase.ListFriends(c, uint64(pkg.GetUserID(c)))
+	if err != nil {
+		a.logger.WithContext(c).Error("[UserApi] ListFriends error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// UpdateFriend godoc
+// @Summary 修改好友备注和分组
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.UpdateFriendReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/user/friend [put]
+func (a *UserApi) UpdateFriend(c *gin.Context) {
+	var req request.UpdateFriendReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.contactUsecase.UpdateFriend(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] UpdateFriend error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// DeleteFriend godoc
+// @Summary 删除好友
+// @Description 双方的好友关系同时解除，不通知对方
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param userId path int true "好友用户ID"
+// @Success 200 {string} string "success"
+// @Router /api/im/user/friend/{userId} [delete]
+func (a *UserApi) DeleteFriend(c *gin.Context) {
+	var req request.ContactUserReq
+	if err := validatex.BindUri(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.contactUsecase.DeleteFriend(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] DeleteFriend error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// ListBlocks godoc
+// @Summary 黑名单列表
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} server_internal_module_im_model_reply.BlockReply
+// @Router /api/im/user/block/list [get]
+func (a *UserApi) ListBlocks(c *gin.Context) {
+	result, err := a.contactUsecase.ListBlocks(c, uint64(pkg.GetUserID(c)))
+	if err != nil {
+		a.logger.WithContext(c).Error("[UserApi] ListBlocks error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Block godoc
+// @Summary 拉黑用户
+// @Description 被拉黑的用户无法再发送单聊消息和好友申请，好友关系保留
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.ContactUserReq true "请求参数"
+// @Success 200 {string} string "success"
+// @Router /api/im/user/block [post]
+func (a *UserApi) Block(c *gin.Context) {
+	var req request.ContactUserReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.contactUsecase.Block(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] Block error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Unblock godoc
+// @Summary 移出黑名单
+// @Tags IM
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param userId path int true "用户ID"
+// @Success 200 {string} string "success"
+// @Router /api/im/user/block/{userId} [delete]
+func (a *UserApi) Unblock(c *gin.Context) {
+	var req request.ContactUserReq
+	if err := validatex.BindUri(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.contactUsecase.Unblock(c, uint64(pkg.GetUserID(c)), &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] Unblock error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
 }
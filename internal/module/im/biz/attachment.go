@@ -0,0 +1,339 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/internal/core/storage"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg/errorx"
+	"server/pkg/imagex"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAttachmentName = 255
+	thumbnailSuffix   = ".thumb.jpg"
+)
+
+// attachmentExts 按内容识别出的 MIME 类型对应的存储扩展名，下载时按扩展名还原 Content-Type，未列出的类型使用 .bin
+var attachmentExts = map[string]string{
+	"image/jpeg":                   ".jpg",
+	"image/png":                    ".png",
+	"image/gif":                    ".gif",
+	"image/webp":                   ".webp",
+	"image/bmp":                    ".bmp",
+	"audio/mpeg":                   ".mp3",
+	"audio/wave":                   ".wav",
+	"audio/aiff":                   ".aiff",
+	"audio/basic":                  ".au",
+	"audio/midi":                   ".mid",
+	"application/ogg":              ".ogg",
+	"video/webm":                   ".webm",
+	"video/mp4":                    ".mp4",
+	"video/avi":                    ".avi",
+	"application/pdf":              ".pdf",
+	"application/zip":              ".zip",
+	"application/x-gzip":           ".gz",
+	"application/x-rar-compressed": ".rar",
+	"text/plain":                   ".txt",
+}
+
+// AttachmentUsecase 附件上传与下载地址签名，文件保存在 storage 中，数据库只保存附件信息和存储路径
+type AttachmentUsecase struct {
+	logger           logger.Logger
+	cfg              *config.Attachment
+	storage          storage.Storage
+	attachmentRepo   repo.AttachmentRepo
+	conversationRepo repo.ConversationRepo
+	messageRepo      repo.MessageRepo
+}
+
+func NewAttachmentUsecase(
+	logger logger.Logger,
+	cfg *config.Attachment,
+	storage storage.Storage,
+	attachmentRepo repo.AttachmentRepo,
+	conversationRepo repo.ConversationRepo,
+	messageRepo repo.MessageRepo,
+) *AttachmentUsecase {
+	return &AttachmentUsecase{
+		logger:           logger,
+		cfg:              cfg,
+		storage:          storage,
+		attachmentRepo:   attachmentRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+	}
+}
+
+// Upload 上传附件，文件类型按内容识别而不是按扩展名或客户端声明的类型；图片超过缩略图尺寸时生成缩略图
+func (u *AttachmentUsecase) Upload(ctx context.Context, userId uint64, req *request.UploadAttachmentReq) (*reply.AttachmentReply, error) {
+	maxSize, types := u.limits(req.Kind)
+	if req.File.Size > maxSize {
+		return nil, errorx.ErrImAttachmentTooLarge
+	}
+	data, err := readUpload(req, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !matchMimeType(types, mimeType) {
+		return nil, errorx.ErrImAttachmentType
+	}
+
+	attachment := &model.Attachment{
+		UserID:     userId,
+		Kind:       req.Kind,
+		StorageKey: newStorageKey(time.Now(), mimeType),
+		Name:       attachmentName(req.File.Filename),
+		Size:       int64(len(data)),
+		MimeType:   mimeType,
+	}
+	if req.Kind == model.MessageTypeVoice {
+		attachment.Duration = req.Duration
+	}
+	var thumbnail []byte
+	if req.Kind == model.MessageTypeImage {
+		if thumbnail, err = u.inspectImage(ctx, attachment, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.storage.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, mimeType); err != nil {
+		u.logger.WithContext(ctx).Error("[AttachmentUsecase] storage.Put error", zap.String("key", attachment.StorageKey), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	if thumbnail != nil {
+		attachment.ThumbKey = attachment.StorageKey + thumbnailSuffix
+		if err := u.storage.Put(ctx, attachment.ThumbKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+			u.logger.WithContext(ctx).Error("[AttachmentUsecase] storage.Put thumbnail error", zap.String("key", attachment.ThumbKey), zap.Error(err))
+			u.remove(ctx, attachment.StorageKey)
+			return nil, errorx.ErrInternal.Wrap(err)
+		}
+	}
+	if err := u.attachmentRepo.Create(ctx, attachment); err != nil {
+		u.logger.WithContext(ctx).Error("[AttachmentUsecase] attachmentRepo.Create error", zap.Error(err))
+		u.remove(ctx, attachment.StorageKey, attachment.ThumbKey)
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	return u.newReply(ctx, attachment)
+}
+
+// URL 为会话中的图片、文件、语音消息重新生成下载地址，客户端在地址过期后调用
+func (u *AttachmentUsecase) URL(ctx context.Context, userId uint64, req *request.MessageTargetReq) (*reply.AttachmentReply, error) {
+	member, err := u.conversationRepo.FindMember(ctx, req.ConversationId, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.ErrImNotConversationMember
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[AttachmentUsecase] conversationRepo.FindMember error", zap.Uint64("conversationId", req.ConversationId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	// 成员加入会话之前的消息视为不存在
+	if req.Seq <= member.StartSeq {
+		return nil, errorx.ErrImMessageNotFound
+	}
+	message, err := u.messageRepo.FindBySeq(ctx, req.ConversationId, req.Seq)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.ErrImMessageNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[AttachmentUsecase] messageRepo.FindBySeq error", zap.Uint64("conversationId", req.ConversationId), zap.Uint64("seq", req.Seq), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	if message.Recalled() {
+		return nil, errorx.ErrImMessageRecalled
+	}
+	if !model.IsAttachmentType(message.Type) {
+		return nil, errorx.ErrImAttachmentNotFound
+	}
+	var content model.AttachmentContent
+	if err := json.Unmarshal([]byte(message.Content), &content); err != nil {
+		return nil, errorx.ErrImAttachmentNotFound
+	}
+
+	attachment, err := u.attachmentRepo.Find(ctx, content.AttachmentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.ErrImAttachmentNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[AttachmentUsecase] attachmentRepo.Find error", zap.Uint64("attachmentId", content.AttachmentID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	return u.newReply(ctx, attachment)
+}
+
+// Open 读取签名下载地址对应的文件，仅用于由服务自身提供下载的存储（本地存储），返回文件内容和 Content-Type
+func (u *AttachmentUsecase) Open(ctx context.Context, key string, query url.Values) (io.ReadCloser, string, error) {
+	verifier, ok := u.storage.(storage.URLVerifier)
+	if !ok {
+		return nil, "", errorx.ErrImAttachmentNotFound
+	}
+	if !verifier.Verify(key, query) {
+		return nil, "", errorx.ErrImAttachmentURL
+	}
+	file, err := u.storage.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, "", errorx.ErrImAttachmentNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[AttachmentUsecase] storage.Get error", zap.String("key", key), zap.Error(err))
+		return nil, "", errorx.ErrInternal.Wrap(err)
+	}
+	return file, attachmentContentType(path.Ext(key)), nil
+}
+
+func (u *AttachmentUsecase) limits(kind string) (int64, []string) {
+	switch kind {
+	case model.MessageTypeImage:
+		return u.cfg.MaxImageSize << 20, u.cfg.ImageTypes
+	case model.MessageTypeVoice:
+		return u.cfg.MaxVoiceSize << 20, u.cfg.VoiceTypes
+	default:
+		return u.cfg.MaxFileSize << 20, u.cfg.FileTypes
+	}
+}
+
+// inspectImage 读取图片尺寸并生成缩略图，标准库无法解码的格式（比如 webp）按普通图片保存，不生成缩略图
+func (u *AttachmentUsecase) inspectImage(ctx context.Context, attachment *model.Attachment, data []byte) ([]byte, error) {
+	cfg, _, err := imagex.DecodeConfig(data)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errorx.ErrImAttachmentInvalid.Wrap(err)
+	}
+	attachment.Width, attachment.Height = cfg.Width, cfg.Height
+
+	thumbnail, err := imagex.Thumbnail(data, u.cfg.ThumbnailSize)
+	if errors.Is(err, imagex.ErrTooManyPixels) {
+		return nil, errorx.ErrImAttachmentInvalid.Wrap(err)
+	}
+	if err != nil {
+		// 缩略图只影响展示，生成失败时客户端使用原图
+		u.logger.WithContext(ctx).Warn("[AttachmentUsecase] imagex.Thumbnail error", zap.Error(err))
+		return nil, nil
+	}
+	return thumbnail, nil
+}
+
+func (u *AttachmentUsecase) newReply(ctx context.Context, attachment *model.Attachment) (*reply.AttachmentReply, error) {
+	expire := time.Duration(u.cfg.URLExpire) * time.Second
+	result := &reply.AttachmentReply{
+		AttachmentContent: model.NewAttachmentContent(attachment),
+		Kind:              attachment.Kind,
+		ExpiresIn:         u.cfg.URLExpire,
+	}
+	var err error
+	if result.URL, err = u.storage.SignURL(ctx, attachment.StorageKey, expire); err != nil {
+		u.logger.WithContext(ctx).Error("[AttachmentUsecase] storage.SignURL error", zap.String("key", attachment.StorageKey), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	if attachment.ThumbKey != "" {
+		if result.ThumbnailURL, err = u.storage.SignURL(ctx, attachment.ThumbKey, expire); err != nil {
+			u.logger.WithContext(ctx).Error("[AttachmentUsecase] storage.SignURL error", zap.String("key", attachment.ThumbKey), zap.Error(err))
+			return nil, errorx.ErrInternal.Wrap(err)
+		}
+	}
+	return result, nil
+}
+
+// remove 清理已上传但未能保存附件信息的文件
+func (u *AttachmentUsecase) remove(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := u.storage.Delete(ctx, key); err != nil {
+			u.logger.WithContext(ctx).Error("[AttachmentUsecase] storage.Delete error", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// readUpload 读取上传的文件，客户端声明的大小不可信，按实际读取的字节数再次校验
+func readUpload(req *request.UploadAttachmentReq, maxSize int64) ([]byte, error) {
+	file, err := req.File.Open()
+	if err != nil {
+		return nil, errorx.ErrImAttachmentInvalid.Wrap(err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, errorx.ErrImAttachmentInvalid.Wrap(err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errorx.ErrImAttachmentTooLarge
+	}
+	if len(data) == 0 {
+		return nil, errorx.ErrImAttachmentInvalid
+	}
+	return data, nil
+}
+
+// matchMimeType 支持 image/* 形式的通配，types 为空表示不限制
+func matchMimeType(types []string, mimeType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == mimeType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// newStorageKey 按日期分目录，文件名随机生成，不使用客户端提供的文件名
+func newStorageKey(now time.Time, mimeType string) string {
+	ext, ok := attachmentExts[mimeType]
+	if !ok {
+		ext = ".bin"
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "im/" + now.Format("2006/01/02") + "/" + hex.EncodeToString(b) + ext
+}
+
+// attachmentContentType 按存储扩展名还原上传时识别出的 MIME 类型，缩略图以 .jpg 结尾
+func attachmentContentType(ext string) string {
+	for mimeType, e := range attachmentExts {
+		if e == ext {
+			return mimeType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// attachmentName 只保留文件名部分并截断到字段长度
+func attachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		name = ""
+	}
+	name = strings.ToValidUTF8(name, "")
+	for len(name) > maxAttachmentName {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
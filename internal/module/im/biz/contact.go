@@ -0,0 +1,360 @@
+package biz
+
+import (
+	"context"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/gateway"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	systemRepo "server/internal/module/system/biz/repo"
+	systemModel "server/internal/module/system/model"
+	"server/pkg/errorx"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	EventFriendRequest = "friend.request" // 收到好友申请
+	EventFriendAdded   = "friend.added"   // 成为好友，推送给双方
+
+	searchUserLimit    = 20
+	friendRequestLimit = 200
+)
+
+// ContactUsecase 好友、好友申请与黑名单，用户身份复用系统库中的 sys_user
+type ContactUsecase struct {
+	logger     logger.Logger
+	tx         repo.Transaction
+	friendRepo repo.FriendRepo
+	blockRepo  repo.BlockRepo
+	userRepo   systemRepo.UserRepo
+	hub        *gateway.Hub
+}
+
+func NewContactUsecase(
+	logger logger.Logger,
+	tx repo.Transaction,
+	friendRepo repo.FriendRepo,
+	blockRepo repo.BlockRepo,
+	userRepo systemRepo.UserRepo,
+	hub *gateway.Hub,
+) *ContactUsecase {
+	return &ContactUsecase{
+		logger:     logger,
+		tx:         tx,
+		friendRepo: friendRepo,
+		blockRepo:  blockRepo,
+		userRepo:   userRepo,
+		hub:        hub,
+	}
+}
+
+// Search 查找用户，只按用户名和昵称匹配，只返回公开资料
+func (u *ContactUsecase) Search(ctx context.Context, userId uint64, req *request.SearchUserReq) ([]*reply.SearchUserReply, error) {
+	users, err := u.userRepo.Search(ctx, req.Keyword, searchUserLimit)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ContactUsecase] userRepo.Search error", zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	ids := make([]uint64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	friendIds, err := u.friendRepo.FindFriendIds(ctx, userId, ids)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ContactUsecase] friendRepo.FindFriendIds error", zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	friends := make(map[uint64]struct{}, len(friendIds))
+	for _, id := range friendIds {
+		friends[id] = struct{}{}
+	}
+
+	result := make([]*reply.SearchUserReply, 0, len(users))
+	for _, user := range users {
+		_, isFriend := friends[user.ID]
+		result = append(result, &reply.SearchUserReply{UserProfileReply: reply.NewUserProfileReply(user), IsFriend: isFriend})
+	}
+	return result, nil
+}
+
+// Profiles 批量查询已启用用户的公开资料
+func (u *ContactUsecase) Profiles(ctx context.Context, req *request.PresenceReq) ([]*reply.UserProfileReply, error) {
+	profiles, err := u.profiles(ctx, req.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*reply.UserProfileReply, 0, len(profiles))
+	for _, id := range uniqueIds(req.UserIds, 0) {
+		if profile, ok := profiles[id]; ok {
+			result = append(result, profile)
+		}
+	}
+	return result, nil
+}
+
+// SendRequest 发送好友申请；对方已向自己发出待处理申请时直接成为好友
+func (u *ContactUsecase) SendRequest(ctx context.Context, userId uint64, req *request.FriendRequestReq) error {
+	if req.UserId == userId {
+		return errorx.ErrImFriendSelf
+	}
+	profiles, err := u.profiles(ctx, []uint64{req.UserId})
+	if err != nil {
+		return err
+	}
+	if _, ok := profiles[req.UserId]; !ok {
+		return errorx.ErrImPeerInvalid
+	}
+	if blocked, err := u.blockRepo.Exists(ctx, req.UserId, userId); err != nil {
+		return u.wrapError(ctx, "blockRepo.Exists", err)
+	} else if blocked {
+		return errorx.ErrImBlocked
+	}
+	if _, err := u.friendRepo.Find(ctx, userId, req.UserId); err == nil {
+		return errorx.ErrImAlreadyFriend
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return u.wrapError(ctx, "friendRepo.Find", err)
+	}
+
+	// 双方互相申请时视为同意对方的申请
+	if reverse, err := u.friendRepo.FindPendingRequest(ctx, req.UserId, userId); err == nil {
+		return u.accept(ctx, reverse)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return u.wrapError(ctx, "friendRepo.FindPendingRequest", err)
+	}
+
+	friendRequest, err := u.friendRepo.FindPendingRequest(ctx, userId, req.UserId)
+	switch {
+	case err == nil:
+		friendRequest.Message = req.Message
+		err = u.friendRepo.UpdateRequest(ctx, friendRequest.ID, map[string]any{"message": req.Message})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		friendRequest = &model.FriendRequest{FromID: userId, ToID: req.UserId, Message: req.Message, Status: model.FriendRequestPending}
+		err = u.friendRepo.CreateRequest(ctx, friendRequest)
+	}
+	if err != nil {
+		return u.wrapError(ctx, "save friend request", err)
+	}
+
+	if sender, err := u.profiles(ctx, []uint64{userId}); err == nil {
+		u.hub.PushToUser(uint(req.UserId), EventFriendRequest, &reply.FriendRequestReply{FriendRequest: friendRequest, User: sender[userId]})
+	}
+	return nil
+}
+
+// ListRequests 收到或发出的好友申请
+func (u *ContactUsecase) ListRequests(ctx context.Context, userId uint64, req *request.ListFriendRequestReq) ([]*reply.FriendRequestReply, error) {
+	received := req.Type != "sent"
+	requests, err := u.friendRepo.ListRequests(ctx, userId, received, friendRequestLimit)
+	if err != nil {
+		return nil, u.wrapError(ctx, "friendRepo.ListRequests", err)
+	}
+	otherIds := make([]uint64, 0, len(requests))
+	for _, r := range requests {
+		otherIds = append(otherIds, otherParty(r, userId))
+	}
+	profiles, err := u.profiles(ctx, otherIds)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*reply.FriendRequestReply, 0, len(requests))
+	for _, r := range requests {
+		result = append(result, &reply.FriendRequestReply{FriendRequest: r, User: profiles[otherParty(r, userId)]})
+	}
+	return result, nil
+}
+
+// Accept 同意好友申请
+func (u *ContactUsecase) Accept(ctx context.Context, userId uint64, req *request.HandleFriendRequestReq) error {
+	friendRequest, err := u.pendingRequest(ctx, userId, req.RequestId)
+	if err != nil {
+		return err
+	}
+	return u.accept(ctx, friendRequest)
+}
+
+// Reject 拒绝好友申请，不通知申请人
+func (u *ContactUsecase) Reject(ctx context.Context, userId uint64, req *request.HandleFriendRequestReq) error {
+	friendRequest, err := u.pendingRequest(ctx, userId, req.RequestId)
+	if err != nil {
+		return err
+	}
+	if err := u.friendRepo.UpdateRequest(ctx, friendRequest.ID, map[string]any{"status": model.FriendRequestRejected}); err != nil {
+		return u.wrapError(ctx, "friendRepo.UpdateRequest", err)
+	}
+	return nil
+}
+
+func (u *ContactUsecase) pendingRequest(ctx context.Context, userId, requestId uint64) (*model.FriendRequest, error) {
+	friendRequest, err := u.friendRepo.FindRequest(ctx, requestId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.ErrImFriendRequestNotFound
+	}
+	if err != nil {
+		return nil, u.wrapError(ctx, "friendRepo.FindRequest", err)
+	}
+	if friendRequest.ToID != userId || friendRequest.Status != model.FriendRequestPending {
+		return nil, errorx.ErrImFriendRequestNotFound
+	}
+	return friendRequest, nil
+}
+
+func (u *ContactUsecase) accept(ctx context.Context, friendRequest *model.FriendRequest) error {
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := u.friendRepo.UpdateRequest(ctx, friendRequest.ID, map[string]any{"status": model.FriendRequestAccepted}); err != nil {
+			return err
+		}
+		return u.friendRepo.AddPair(ctx, friendRequest.FromID, friendRequest.ToID)
+	})
+	if err != nil {
+		return u.wrapError(ctx, "accept friend request", err)
+	}
+
+	profiles, err := u.profiles(ctx, []uint64{friendRequest.FromID, friendRequest.ToID})
+	if err != nil {
+		return nil
+	}
+	u.hub.PushToUser(uint(friendRequest.FromID), EventFriendAdded, profiles[friendRequest.ToID])
+	u.hub.PushToUser(uint(friendRequest.ToID), EventFriendAdded, profiles[friendRequest.FromID])
+	return nil
+}
+
+// ListFriends 好友列表，按联系人分组排序
+func (u *ContactUsecase) ListFriends(ctx context.Context, userId uint64) ([]*reply.FriendReply, error) {
+	friends, err := u.friendRepo.List(ctx, userId)
+	if err != nil {
+		return nil, u.wrapError(ctx, "friendRepo.List", err)
+	}
+	ids := make([]uint64, 0, len(friends))
+	for _, friend := range friends {
+		ids = append(ids, friend.FriendID)
+	}
+	profiles, err := u.profiles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*reply.FriendReply, 0, len(friends))
+	for _, friend := range friends {
+		// 已删除或禁用的用户不再出现在好友列表中
+		if profile, ok := profiles[friend.FriendID]; ok {
+			result = append(result, &reply.FriendReply{Friend: friend, User: profile})
+		}
+	}
+	return result, nil
+}
+
+// UpdateFriend 修改好友备注和分组，只对自己可见
+func (u *ContactUsecase) UpdateFriend(ctx context.Context, userId uint64, req *request.UpdateFriendReq) error {
+	if err := u.checkFriend(ctx, userId, req.UserId); err != nil {
+		return err
+	}
+	err := u.friendRepo.Update(ctx, userId, req.UserId, map[string]any{"remark": req.Remark, "contact_group": req.ContactGroup})
+	if err != nil {
+		return u.wrapError(ctx, "friendRepo.Update", err)
+	}
+	return nil
+}
+
+// DeleteFriend 删除好友，双方的好友关系同时解除
+func (u *ContactUsecase) DeleteFriend(ctx context.Context, userId uint64, req *request.ContactUserReq) error {
+	if err := u.checkFriend(ctx, userId, req.UserId); err != nil {
+		return err
+	}
+	if err := u.friendRepo.RemovePair(ctx, userId, req.UserId); err != nil {
+		return u.wrapError(ctx, "friendRepo.RemovePair", err)
+	}
+	return nil
+}
+
+func (u *ContactUsecase) checkFriend(ctx context.Context, userId, friendId uint64) error {
+	_, err := u.friendRepo.Find(ctx, userId, friendId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorx.ErrImNotFriend
+	}
+	if err != nil {
+		return u.wrapError(ctx, "friendRepo.Find", err)
+	}
+	return nil
+}
+
+// Block 拉黑用户，对方无法再给自己发送单聊消息和好友申请，好友关系保留
+func (u *ContactUsecase) Block(ctx context.Context, userId uint64, req *request.ContactUserReq) error {
+	if req.UserId == userId {
+		return errorx.ErrImBlockSelf
+	}
+	if err := u.blockRepo.Create(ctx, userId, req.UserId); err != nil {
+		return u.wrapError(ctx, "blockRepo.Create", err)
+	}
+	return nil
+}
+
+// Unblock 移出黑名单
+func (u *ContactUsecase) Unblock(ctx context.Context, userId uint64, req *request.ContactUserReq) error {
+	if err := u.blockRepo.Delete(ctx, userId, req.UserId); err != nil {
+		return u.wrapError(ctx, "blockRepo.Delete", err)
+	}
+	return nil
+}
+
+// ListBlocks 黑名单列表
+func (u *ContactUsecase) ListBlocks(ctx context.Context, userId uint64) ([]*reply.BlockReply, error) {
+	blocks, err := u.blockRepo.List(ctx, userId)
+	if err != nil {
+		return nil, u.wrapError(ctx, "blockRepo.List", err)
+	}
+	ids := make([]uint64, 0, len(blocks))
+	for _, block := range blocks {
+		ids = append(ids, block.BlockedID)
+	}
+	profiles, err := u.profiles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*reply.BlockReply, 0, len(blocks))
+	for _, block := range blocks {
+		result = append(result, &reply.BlockReply{Block: block, User: profiles[block.BlockedID]})
+	}
+	return result, nil
+}
+
+// profiles 查询已启用用户的公开资料，按用户ID索引
+func (u *ContactUsecase) profiles(ctx context.Context, userIds []uint64) (map[uint64]*reply.UserProfileReply, error) {
+	result := make(map[uint64]*reply.UserProfileReply, len(userIds))
+	if len(userIds) == 0 {
+		return result, nil
+	}
+	ids := make([]int64, 0, len(userIds))
+	for _, id := range userIds {
+		ids = append(ids, int64(id))
+	}
+	users, err := u.userRepo.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, u.wrapError(ctx, "userRepo.FindByIds", err)
+	}
+	for _, user := range users {
+		if user.Status == systemModel.UserStatusEnable {
+			result[user.ID] = reply.NewUserProfileReply(user)
+		}
+	}
+	return result, nil
+}
+
+func (u *ContactUsecase) wrapError(ctx context.Context, action string, err error) error {
+	var bizErr *errorx.BizError
+	if errors.As(err, &bizErr) {
+		return bizErr
+	}
+	u.logger.WithContext(ctx).Error("[ContactUsecase] "+action+" error", zap.Error(err))
+	return errorx.ErrInternal.Wrap(err)
+}
+
+func otherParty(friendRequest *model.FriendRequest, userId uint64) uint64 {
+	if friendRequest.FromID == userId {
+		return friendRequest.ToID
+	}
+	return friendRequest.FromID
+}
@@ -0,0 +1,70 @@
+package biz
+
+import (
+	"context"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg/errorx"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultConversationLimit = 100
+
+type ConversationUsecase struct {
+	logger           logger.Logger
+	conversationRepo repo.ConversationRepo
+	messageRepo      repo.MessageRepo
+}
+
+func NewConversationUsecase(logger logger.Logger, conversationRepo repo.ConversationRepo, messageRepo repo.MessageRepo) *ConversationUsecase {
+	return &ConversationUsecase{
+		logger:           logger,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+	}
+}
+
+// List 会话列表，携带未读数和最后一条消息；传入 updatedAfter 时只返回之后有新消息或已读位置变化的会话
+func (u *ConversationUsecase) List(ctx context.Context, userId uint64, req *request.ListConversationReq) (*reply.ConversationListReply, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultConversationLimit
+	}
+	conversations, err := u.conversationRepo.ListByUser(ctx, userId, time.UnixMilli(req.UpdatedAfter), req.AfterConversationId, limit+1)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ConversationUsecase] conversationRepo.ListByUser error", zap.Uint64("userId", userId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	result := &reply.ConversationListReply{List: make([]*reply.ConversationReply, 0, len(conversations))}
+	if len(conversations) > limit {
+		conversations = conversations[:limit]
+		result.HasMore = true
+	}
+
+	// 只查询成员可见的最后一条消息
+	lastSeqs := make(map[uint64]uint64, len(conversations))
+	for _, conversation := range conversations {
+		if conversation.LastSeq > conversation.StartSeq {
+			lastSeqs[conversation.ConversationID] = conversation.LastSeq
+		}
+	}
+	messages, err := u.messageRepo.FindBySeqs(ctx, lastSeqs)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ConversationUsecase] messageRepo.FindBySeqs error", zap.Uint64("userId", userId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	lastMessages := make(map[uint64]*model.Message, len(messages))
+	for _, message := range messages {
+		lastMessages[message.ConversationID] = message
+	}
+
+	for _, conversation := range conversations {
+		result.List = append(result.List, reply.NewConversationReply(conversation, lastMessages[conversation.ConversationID]))
+	}
+	return result, nil
+}
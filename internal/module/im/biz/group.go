@@ -0,0 +1,522 @@
+package biz
+
+import (
+	"context"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/gateway"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	systemRepo "server/internal/module/system/biz/repo"
+	systemModel "server/internal/module/system/model"
+	"server/pkg/errorx"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// EventGroupNotice 群组变更推送
+const EventGroupNotice = "group.notice"
+
+type GroupUsecase struct {
+	logger           logger.Logger
+	cfg              *config.Group
+	tx               repo.Transaction
+	groupRepo        repo.GroupRepo
+	conversationRepo repo.ConversationRepo
+	userRepo         systemRepo.UserRepo
+	hub              *gateway.Hub
+}
+
+func NewGroupUsecase(
+	logger logger.Logger,
+	cfg *config.Group,
+	tx repo.Transaction,
+	groupRepo repo.GroupRepo,
+	conversationRepo repo.ConversationRepo,
+	userRepo systemRepo.UserRepo,
+	hub *gateway.Hub,
+) *GroupUsecase {
+	return &GroupUsecase{
+		logger:           logger,
+		cfg:              cfg,
+		tx:               tx,
+		groupRepo:        groupRepo,
+		conversationRepo: conversationRepo,
+		userRepo:         userRepo,
+		hub:              hub,
+	}
+}
+
+// Create 创建群组及对应的群聊会话，创建者成为群主
+func (u *GroupUsecase) Create(ctx context.Context, ownerId uint64, req *request.CreateGroupReq) (*model.Group, error) {
+	maxMembers := req.MaxMembers
+	if maxMembers == 0 {
+		maxMembers = u.cfg.DefaultMaxMembers
+	}
+	if maxMembers > u.cfg.MaxMembers {
+		return nil, errorx.ErrImGroupMaxMembers
+	}
+	joinMode := req.JoinMode
+	if joinMode == 0 {
+		joinMode = model.GroupJoinFree
+	}
+
+	owned, err := u.groupRepo.CountOwned(ctx, ownerId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[GroupUsecase] groupRepo.CountOwned error", zap.Uint64("ownerId", ownerId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	if owned >= int64(u.cfg.MaxOwnedGroups) {
+		return nil, errorx.ErrImGroupOwnedLimit
+	}
+
+	memberIds := uniqueIds(req.MemberIds, ownerId)
+	if err := u.checkUsers(ctx, memberIds); err != nil {
+		return nil, err
+	}
+	if len(memberIds)+1 > maxMembers {
+		return nil, errorx.ErrImGroupFull
+	}
+
+	var group *model.Group
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		conversation, err := u.conversationRepo.CreateGroup(ctx)
+		if err != nil {
+			return err
+		}
+		group = &model.Group{
+			ID:          conversation.ID,
+			Name:        req.Name,
+			Avatar:      req.Avatar,
+			OwnerID:     ownerId,
+			JoinMode:    joinMode,
+			MaxMembers:  maxMembers,
+			MemberCount: len(memberIds) + 1,
+		}
+		if err := u.groupRepo.Create(ctx, group); err != nil {
+			return err
+		}
+
+		members := []*model.GroupMember{{GroupID: group.ID, UserID: ownerId, Role: model.GroupRoleOwner}}
+		for _, userId := range memberIds {
+			members = append(members, &model.GroupMember{GroupID: group.ID, UserID: userId, Role: model.GroupRoleMember, InviterID: ownerId})
+		}
+		if _, err := u.groupRepo.AddMembers(ctx, members); err != nil {
+			return err
+		}
+		return u.conversationRepo.AddMembers(ctx, conversation.ID, append([]uint64{ownerId}, memberIds...), 0)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[GroupUsecase] create group error", zap.Uint64("ownerId", ownerId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: group.ID, Type: reply.GroupNoticeCreated, OperatorId: ownerId, UserIds: memberIds, Group: group})
+	return group, nil
+}
+
+// Update 修改群资料，仅群主和管理员可操作
+func (u *GroupUsecase) Update(ctx context.Context, operatorId uint64, req *request.UpdateGroupReq) error {
+	fields := map[string]any{"name": req.Name, "avatar": req.Avatar, "join_mode": req.JoinMode}
+	group, err := u.updateGroup(ctx, operatorId, req.GroupId, fields)
+	if err != nil {
+		return err
+	}
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: group.ID, Type: reply.GroupNoticeUpdated, OperatorId: operatorId, Group: group})
+	return nil
+}
+
+// UpdateAnnouncement 修改群公告，仅群主和管理员可操作
+func (u *GroupUsecase) UpdateAnnouncement(ctx context.Context, operatorId uint64, req *request.UpdateGroupAnnouncementReq) error {
+	group, err := u.updateGroup(ctx, operatorId, req.GroupId, map[string]any{"announcement": req.Announcement})
+	if err != nil {
+		return err
+	}
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: group.ID, Type: reply.GroupNoticeAnnouncement, OperatorId: operatorId, Group: group})
+	return nil
+}
+
+func (u *GroupUsecase) updateGroup(ctx context.Context, operatorId, groupId uint64, fields map[string]any) (*model.Group, error) {
+	var group *model.Group
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		var (
+			operator *model.GroupMember
+			err      error
+		)
+		group, operator, err = u.lockGroup(ctx, groupId, operatorId)
+		if err != nil {
+			return err
+		}
+		if operator.Role > model.GroupRoleAdmin {
+			return errorx.ErrImGroupPermission
+		}
+		if err := u.groupRepo.Update(ctx, groupId, fields); err != nil {
+			return err
+		}
+		group, err = u.groupRepo.Find(ctx, groupId)
+		return err
+	})
+	if err != nil {
+		return nil, u.wrapError(ctx, "update group", groupId, err)
+	}
+	return group, nil
+}
+
+// Invite 邀请用户加入群组，任何成员都可以邀请，已是成员的用户会被忽略
+func (u *GroupUsecase) Invite(ctx context.Context, operatorId uint64, req *request.InviteGroupMemberReq) error {
+	userIds := uniqueIds(req.UserIds, operatorId)
+	if err := u.checkUsers(ctx, userIds); err != nil {
+		return err
+	}
+
+	var added []uint64
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		group, _, err := u.lockGroup(ctx, req.GroupId, operatorId)
+		if err != nil {
+			return err
+		}
+		existing, err := u.groupRepo.FindMembers(ctx, group.ID, userIds)
+		if err != nil {
+			return err
+		}
+		added = excludeMembers(userIds, existing)
+		return u.addMembers(ctx, group, added, operatorId)
+	})
+	if err != nil {
+		return u.wrapError(ctx, "invite", req.GroupId, err)
+	}
+	if len(added) > 0 {
+		u.notify(ctx, &reply.GroupNoticeReply{GroupId: req.GroupId, Type: reply.GroupNoticeInvited, OperatorId: operatorId, UserIds: added})
+	}
+	return nil
+}
+
+// Join 主动加入群组，仅允许直接加入的群组可用，已是成员时直接返回
+func (u *GroupUsecase) Join(ctx context.Context, userId uint64, groupId uint64) error {
+	joined := false
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		group, err := u.groupRepo.FindForUpdate(ctx, groupId)
+		if err != nil {
+			return err
+		}
+		if _, err := u.groupRepo.FindMember(ctx, groupId, userId); err == nil {
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if group.JoinMode != model.GroupJoinFree {
+			return errorx.ErrImGroupInviteOnly
+		}
+		joined = true
+		return u.addMembers(ctx, group, []uint64{userId}, 0)
+	})
+	if err != nil {
+		return u.wrapError(ctx, "join", groupId, err)
+	}
+	if joined {
+		u.notify(ctx, &reply.GroupNoticeReply{GroupId: groupId, Type: reply.GroupNoticeJoined, OperatorId: userId, UserIds: []uint64{userId}})
+	}
+	return nil
+}
+
+// addMembers 在群组行锁内添加成员并校验成员数上限
+func (u *GroupUsecase) addMembers(ctx context.Context, group *model.Group, userIds []uint64, inviterId uint64) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	if group.MemberCount+len(userIds) > group.MaxMembers {
+		return errorx.ErrImGroupFull
+	}
+	conversation, err := u.conversationRepo.Find(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+
+	members := make([]*model.GroupMember, 0, len(userIds))
+	for _, userId := range userIds {
+		members = append(members, &model.GroupMember{GroupID: group.ID, UserID: userId, Role: model.GroupRoleMember, InviterID: inviterId})
+	}
+	if _, err := u.groupRepo.AddMembers(ctx, members); err != nil {
+		return err
+	}
+	if err := u.conversationRepo.AddMembers(ctx, group.ID, userIds, conversation.LastSeq); err != nil {
+		return err
+	}
+	return u.groupRepo.AddMemberCount(ctx, group.ID, len(userIds))
+}
+
+// Leave 退出群组，群主需先转让群组
+func (u *GroupUsecase) Leave(ctx context.Context, userId uint64, groupId uint64) error {
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		_, member, err := u.lockGroup(ctx, groupId, userId)
+		if err != nil {
+			return err
+		}
+		if member.Role == model.GroupRoleOwner {
+			return errorx.ErrImGroupOwnerLeave
+		}
+		return u.removeMember(ctx, groupId, userId)
+	})
+	if err != nil {
+		return u.wrapError(ctx, "leave", groupId, err)
+	}
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: groupId, Type: reply.GroupNoticeLeft, OperatorId: userId, UserIds: []uint64{userId}}, userId)
+	return nil
+}
+
+// Kick 移出成员，群主可移出任何人，管理员只能移出普通成员
+func (u *GroupUsecase) Kick(ctx context.Context, operatorId uint64, req *request.GroupMemberReq) error {
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		_, _, target, err := u.lockTarget(ctx, req.GroupId, operatorId, req.UserId)
+		if err != nil {
+			return err
+		}
+		return u.removeMember(ctx, req.GroupId, target.UserID)
+	})
+	if err != nil {
+		return u.wrapError(ctx, "kick", req.GroupId, err)
+	}
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: req.GroupId, Type: reply.GroupNoticeKicked, OperatorId: operatorId, UserIds: []uint64{req.UserId}}, req.UserId)
+	return nil
+}
+
+func (u *GroupUsecase) removeMember(ctx context.Context, groupId, userId uint64) error {
+	if err := u.groupRepo.RemoveMember(ctx, groupId, userId); err != nil {
+		return err
+	}
+	if err := u.conversationRepo.RemoveMembers(ctx, groupId, []uint64{userId}); err != nil {
+		return err
+	}
+	return u.groupRepo.AddMemberCount(ctx, groupId, -1)
+}
+
+// SetRole 设置或取消管理员，仅群主可操作
+func (u *GroupUsecase) SetRole(ctx context.Context, operatorId uint64, req *request.SetGroupRoleReq) error {
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		_, operator, target, err := u.lockTarget(ctx, req.GroupId, operatorId, req.UserId)
+		if err != nil {
+			return err
+		}
+		if operator.Role != model.GroupRoleOwner {
+			return errorx.ErrImGroupPermission
+		}
+		return u.groupRepo.UpdateMember(ctx, req.GroupId, target.UserID, map[string]any{"role": req.Role})
+	})
+	if err != nil {
+		return u.wrapError(ctx, "set role", req.GroupId, err)
+	}
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: req.GroupId, Type: reply.GroupNoticeRole, OperatorId: operatorId, UserIds: []uint64{req.UserId}, Role: req.Role})
+	return nil
+}
+
+// Transfer 转让群主，原群主成为管理员
+func (u *GroupUsecase) Transfer(ctx context.Context, operatorId uint64, req *request.GroupMemberReq) error {
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		_, operator, target, err := u.lockTarget(ctx, req.GroupId, operatorId, req.UserId)
+		if err != nil {
+			return err
+		}
+		if operator.Role != model.GroupRoleOwner {
+			return errorx.ErrImGroupPermission
+		}
+		if err := u.groupRepo.UpdateMember(ctx, req.GroupId, target.UserID, map[string]any{"role": model.GroupRoleOwner, "muted_until": nil}); err != nil {
+			return err
+		}
+		if err := u.groupRepo.UpdateMember(ctx, req.GroupId, operatorId, map[string]any{"role": model.GroupRoleAdmin}); err != nil {
+			return err
+		}
+		return u.groupRepo.Update(ctx, req.GroupId, map[string]any{"owner_id": target.UserID})
+	})
+	if err != nil {
+		return u.wrapError(ctx, "transfer", req.GroupId, err)
+	}
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: req.GroupId, Type: reply.GroupNoticeTransferred, OperatorId: operatorId, UserIds: []uint64{req.UserId}, Role: model.GroupRoleOwner})
+	return nil
+}
+
+// Mute 禁言成员，Duration 为 0 时解除禁言，只能禁言角色低于自己的成员
+func (u *GroupUsecase) Mute(ctx context.Context, operatorId uint64, req *request.MuteGroupMemberReq) error {
+	var mutedUntil *time.Time
+	if req.Duration > 0 {
+		until := time.Now().Add(time.Duration(req.Duration) * time.Second)
+		mutedUntil = &until
+	}
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		_, _, target, err := u.lockTarget(ctx, req.GroupId, operatorId, req.UserId)
+		if err != nil {
+			return err
+		}
+		return u.groupRepo.UpdateMember(ctx, req.GroupId, target.UserID, map[string]any{"muted_until": mutedUntil})
+	})
+	if err != nil {
+		return u.wrapError(ctx, "mute", req.GroupId, err)
+	}
+	u.notify(ctx, &reply.GroupNoticeReply{GroupId: req.GroupId, Type: reply.GroupNoticeMuted, OperatorId: operatorId, UserIds: []uint64{req.UserId}, MutedUntil: mutedUntil})
+	return nil
+}
+
+// Detail 群组详情，非成员也可查看，用于加群前展示
+func (u *GroupUsecase) Detail(ctx context.Context, userId, groupId uint64) (*reply.GroupDetailReply, error) {
+	group, err := u.groupRepo.Find(ctx, groupId)
+	if err != nil {
+		return nil, u.wrapError(ctx, "find group", groupId, err)
+	}
+	result := &reply.GroupDetailReply{Group: group}
+	member, err := u.groupRepo.FindMember(ctx, groupId, userId)
+	if err == nil {
+		result.Role = member.Role
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, u.wrapError(ctx, "find member", groupId, err)
+	}
+	return result, nil
+}
+
+// List 当前用户加入的群组
+func (u *GroupUsecase) List(ctx context.Context, userId uint64) ([]*model.Group, error) {
+	groups, err := u.groupRepo.ListByUser(ctx, userId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[GroupUsecase] groupRepo.ListByUser error", zap.Uint64("userId", userId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	return groups, nil
+}
+
+// Members 群成员列表，仅成员可查看
+func (u *GroupUsecase) Members(ctx context.Context, userId, groupId uint64) ([]*model.GroupMember, error) {
+	if _, err := u.groupRepo.FindMember(ctx, groupId, userId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = errorx.ErrImNotGroupMember
+		}
+		return nil, u.wrapError(ctx, "find member", groupId, err)
+	}
+	members, err := u.groupRepo.ListMembers(ctx, groupId)
+	if err != nil {
+		return nil, u.wrapError(ctx, "list members", groupId, err)
+	}
+	return members, nil
+}
+
+// lockGroup 加锁读取群组并校验操作者是群成员
+func (u *GroupUsecase) lockGroup(ctx context.Context, groupId, operatorId uint64) (*model.Group, *model.GroupMember, error) {
+	group, err := u.groupRepo.FindForUpdate(ctx, groupId)
+	if err != nil {
+		return nil, nil, err
+	}
+	operator, err := u.groupRepo.FindMember(ctx, groupId, operatorId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errorx.ErrImNotGroupMember
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return group, operator, nil
+}
+
+// lockTarget 在 lockGroup 的基础上校验目标是群成员且角色低于操作者
+func (u *GroupUsecase) lockTarget(ctx context.Context, groupId, operatorId, targetId uint64) (*model.Group, *model.GroupMember, *model.GroupMember, error) {
+	group, operator, err := u.lockGroup(ctx, groupId, operatorId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	target, err := u.groupRepo.FindMember(ctx, groupId, targetId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil, errorx.ErrImGroupTargetNotMember
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !operator.Outranks(target) {
+		return nil, nil, nil, errorx.ErrImGroupPermission
+	}
+	return group, operator, target, nil
+}
+
+// checkUsers 校验用户均存在且已启用
+func (u *GroupUsecase) checkUsers(ctx context.Context, userIds []uint64) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(userIds))
+	for _, id := range userIds {
+		ids = append(ids, int64(id))
+	}
+	users, err := u.userRepo.FindByIds(ctx, ids)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[GroupUsecase] userRepo.FindByIds error", zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	enabled := 0
+	for _, user := range users {
+		if user.Status == systemModel.UserStatusEnable {
+			enabled++
+		}
+	}
+	if enabled != len(userIds) {
+		return errorx.ErrImGroupUserInvalid
+	}
+	return nil
+}
+
+// notify 向群内全部在线成员推送变更，extraUserIds 为已不在群内但需要收到通知的用户
+func (u *GroupUsecase) notify(ctx context.Context, notice *reply.GroupNoticeReply, extraUserIds ...uint64) {
+	members, err := u.groupRepo.ListMembers(ctx, notice.GroupId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[GroupUsecase] notify list members error", zap.Uint64("groupId", notice.GroupId), zap.Error(err))
+		return
+	}
+	userIds := make([]uint, 0, len(members)+len(extraUserIds))
+	for _, member := range members {
+		userIds = append(userIds, uint(member.UserID))
+	}
+	for _, userId := range extraUserIds {
+		userIds = append(userIds, uint(userId))
+	}
+	u.hub.PushToUsers(userIds, EventGroupNotice, notice)
+}
+
+// wrapError 业务错误原样返回，群组不存在转换为业务错误，其余记录日志后包装为内部错误
+func (u *GroupUsecase) wrapError(ctx context.Context, action string, groupId uint64, err error) error {
+	var bizErr *errorx.BizError
+	if errors.As(err, &bizErr) {
+		return bizErr
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorx.ErrImGroupNotFound
+	}
+	u.logger.WithContext(ctx).Error("[GroupUsecase] "+action+" error", zap.Uint64("groupId", groupId), zap.Error(err))
+	return errorx.ErrInternal.Wrap(err)
+}
+
+// uniqueIds 去重并排除指定用户
+func uniqueIds(ids []uint64, exclude uint64) []uint64 {
+	seen := make(map[uint64]struct{}, len(ids))
+	result := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok || id == exclude {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+// excludeMembers 排除已是群成员的用户
+func excludeMembers(userIds []uint64, members []*model.GroupMember) []uint64 {
+	existing := make(map[uint64]struct{}, len(members))
+	for _, member := range members {
+		existing[member.UserID] = struct{}{}
+	}
+	result := make([]uint64, 0, len(userIds))
+	for _, id := range userIds {
+		if _, ok := existing[id]; !ok {
+			result = append(result, id)
+		}
+	}
+	return result
+}
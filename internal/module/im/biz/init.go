@@ -0,0 +1,65 @@
+package biz
+
+import (
+	"context"
+	"server/internal/core/logger"
+	"server/internal/core/migrate"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm/schema"
+)
+
+const initModule = "im"
+
+// imTables IM 数据库中的表，新增模型时在此注册
+var imTables = []schema.Tabler{
+	&model.Conversation{},
+	&model.ConversationMember{},
+	&model.Message{},
+	&model.MessageEdit{},
+	&model.MessageReaction{},
+	&model.Attachment{},
+	&model.Group{},
+	&model.GroupMember{},
+	&model.FriendRequest{},
+	&model.Friend{},
+	&model.Block{},
+	&model.SensitiveWord{},
+	&model.GroupModeration{},
+	&model.ModerationReview{},
+}
+
+type InitUsecase struct {
+	logger   logger.Logger
+	initRepo repo.InitRepo
+}
+
+func NewInitUsecase(logger logger.Logger, initRepo repo.InitRepo) *InitUsecase {
+	return &InitUsecase{
+		logger:   logger,
+		initRepo: initRepo,
+	}
+}
+
+// Migrations IM 模块的迁移，执行记录与系统模块一起保存在 sys_init 中
+func (u *InitUsecase) Migrations() []*migrate.Migration {
+	return []*migrate.Migration{
+		{
+			Module: initModule, Name: "schema", Repeatable: true,
+			Description: "同步 IM 表结构",
+			Source:      migrate.SchemaSource(imTables...),
+			Up:          u.SyncSchema,
+		},
+	}
+}
+
+// SyncSchema 同步表结构，模型变化后自动重新执行
+func (u *InitUsecase) SyncSchema(ctx context.Context) error {
+	if err := u.initRepo.AutoMigrate(imTables); err != nil {
+		u.logger.WithContext(ctx).Error("[ImInitUsecase] failed to initialize database table structure", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,726 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/gateway"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	systemRepo "server/internal/module/system/biz/repo"
+	systemModel "server/internal/module/system/model"
+	"server/pkg/errorx"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	EventMessageNew      = "message.new"      // 新消息推送
+	EventMessageReceipt  = "message.receipt"  // 送达、已读回执推送
+	EventMessageRecalled = "message.recalled" // 消息撤回，data 为撤回后的消息
+	EventMessageEdited   = "message.edited"   // 消息编辑，data 为编辑后的消息
+	EventMessageReaction = "message.reaction" // 表情回应变化，data 为携带最新表情回应的消息
+)
+
+const (
+	defaultSyncLimit    = 100
+	defaultHistoryLimit = 20
+)
+
+// errDuplicateMessage 客户端消息ID重复，回滚事务以撤销已分配的序号
+var errDuplicateMessage = errors.New("duplicate client message id")
+
+type MessageUsecase struct {
+	logger           logger.Logger
+	cfg              *config.Message
+	tx               repo.Transaction
+	conversationRepo repo.ConversationRepo
+	messageRepo      repo.MessageRepo
+	groupRepo        repo.GroupRepo
+	blockRepo        repo.BlockRepo
+	attachmentRepo   repo.AttachmentRepo
+	userRepo         systemRepo.UserRepo
+	moderation       *ModerationChain
+	hub              *gateway.Hub
+}
+
+func NewMessageUsecase(
+	logger logger.Logger,
+	cfg *config.Message,
+	tx repo.Transaction,
+	conversationRepo repo.ConversationRepo,
+	messageRepo repo.MessageRepo,
+	groupRepo repo.GroupRepo,
+	blockRepo repo.BlockRepo,
+	attachmentRepo repo.AttachmentRepo,
+	userRepo systemRepo.UserRepo,
+	moderation *ModerationChain,
+	hub *gateway.Hub,
+) *MessageUsecase {
+	return &MessageUsecase{
+		logger:           logger,
+		cfg:              cfg,
+		tx:               tx,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		groupRepo:        groupRepo,
+		blockRepo:        blockRepo,
+		attachmentRepo:   attachmentRepo,
+		userRepo:         userRepo,
+		moderation:       moderation,
+		hub:              hub,
+	}
+}
+
+// Send 发送单聊消息，同一发送方重复提交相同 clientMsgId 时直接返回已保存的消息
+func (u *MessageUsecase) Send(ctx context.Context, senderId uint64, req *request.SendMessageReq) (*reply.MessageReply, error) {
+	if existing, err := u.findByClientMsgId(ctx, senderId, req.ClientMsgId); err != nil || existing != nil {
+		return existing, err
+	}
+
+	if err := u.checkPeer(ctx, senderId, req.PeerId); err != nil {
+		return nil, err
+	}
+	// 被对方拉黑时拒绝发送，群聊消息不受影响
+	blocked, err := u.blockRepo.Exists(ctx, req.PeerId, senderId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] blockRepo.Exists error", zap.Uint64("senderId", senderId), zap.Uint64("peerId", req.PeerId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	if blocked {
+		return nil, errorx.ErrImBlocked
+	}
+	conversation, err := u.conversationRepo.FindOrCreateSingle(ctx, senderId, req.PeerId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.FindOrCreateSingle error", zap.Uint64("senderId", senderId), zap.Uint64("peerId", req.PeerId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+
+	result, created, err := u.save(ctx, conversation, senderId, req.ClientMsgId, req.Type, req.Content, req.ReplyToSeq)
+	if err != nil || !created {
+		return result, err
+	}
+	u.hub.PushToUser(uint(req.PeerId), EventMessageNew, result)
+	// 同步到发送方的其他设备
+	u.hub.PushToUserExcept(uint(senderId), gateway.ConnIDFromContext(ctx), EventMessageNew, result)
+	return result, nil
+}
+
+// SendGroup 发送群聊消息，推送给群内全部在线成员，被禁言的成员不能发送
+func (u *MessageUsecase) SendGroup(ctx context.Context, senderId uint64, req *request.SendGroupMessageReq) (*reply.MessageReply, error) {
+	if existing, err := u.findByClientMsgId(ctx, senderId, req.ClientMsgId); err != nil || existing != nil {
+		return existing, err
+	}
+
+	member, err := u.groupRepo.FindMember(ctx, req.GroupId, senderId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.ErrImNotGroupMember
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] groupRepo.FindMember error", zap.Uint64("groupId", req.GroupId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	if member.Muted(time.Now()) {
+		return nil, errorx.ErrImGroupMuted
+	}
+	conversation, err := u.conversationRepo.Find(ctx, req.GroupId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.Find error", zap.Uint64("groupId", req.GroupId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+
+	result, created, err := u.save(ctx, conversation, senderId, req.ClientMsgId, req.Type, req.Content, req.ReplyToSeq)
+	if err != nil || !created {
+		return result, err
+	}
+	members, err := u.conversationRepo.ListMembers(ctx, conversation.ID)
+	if err != nil {
+		// 消息已保存，成员可通过离线同步获取
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.ListMembers error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+		return result, nil
+	}
+	userIds := make([]uint, 0, len(members))
+	for _, m := range members {
+		if m.UserID != senderId {
+			userIds = append(userIds, uint(m.UserID))
+		}
+	}
+	u.hub.PushToUsers(userIds, EventMessageNew, result)
+	u.hub.PushToUserExcept(uint(senderId), gateway.ConnIDFromContext(ctx), EventMessageNew, result)
+	return result, nil
+}
+
+// save 分配会话序号并保存消息，文本和自定义消息先经过审核链，clientMsgId 重复时返回已保存的消息且 created 为 false
+func (u *MessageUsecase) save(ctx context.Context, conversation *model.Conversation, senderId uint64, clientMsgId, msgType, content string, replyToSeq uint64) (*reply.MessageReply, bool, error) {
+	if replyToSeq > 0 {
+		if _, _, err := u.findMessage(ctx, conversation, senderId, replyToSeq); err != nil {
+			return nil, false, err
+		}
+	}
+	content, err := u.attachmentContent(ctx, senderId, msgType, content)
+	if err != nil {
+		return nil, false, err
+	}
+	var moderation *ModerationResult
+	switch msgType {
+	case model.MessageTypeText:
+		moderation, err = u.moderation.Check(ctx, conversation, senderId, content)
+	case model.MessageTypeCustom:
+		moderation, err = u.moderation.CheckCustom(ctx, conversation, senderId, content)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if moderation != nil {
+		content = moderation.Content
+	}
+	message := &model.Message{
+		ConversationID: conversation.ID,
+		SenderID:       senderId,
+		ClientMsgID:    clientMsgId,
+		Type:           msgType,
+		Content:        content,
+		ReplyToSeq:     replyToSeq,
+	}
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		seq, err := u.conversationRepo.NextSeq(ctx, conversation.ID)
+		if err != nil {
+			return err
+		}
+		message.Seq = seq
+		created, err := u.messageRepo.Create(ctx, message)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return errDuplicateMessage
+		}
+		if err := u.moderation.Flag(ctx, conversation, message, moderation); err != nil {
+			return err
+		}
+		// 发送方自己的消息视为已读
+		_, err = u.conversationRepo.AdvanceRead(ctx, conversation.ID, senderId, seq)
+		return err
+	})
+	if errors.Is(err, errDuplicateMessage) {
+		// 并发重发，另一个请求已保存该消息
+		result, err := u.findByClientMsgId(ctx, senderId, clientMsgId)
+		return result, false, err
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] save message error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+		return nil, false, errorx.ErrInternal.Wrap(err)
+	}
+
+	conversation.LastSeq = message.Seq
+	return reply.NewMessageReply(conversation, message), true, nil
+}
+
+// attachmentContent 校验图片、文件、语音消息引用的附件，只能发送自己上传的附件，返回补全附件信息后的消息内容
+func (u *MessageUsecase) attachmentContent(ctx context.Context, senderId uint64, msgType, content string) (string, error) {
+	if !model.IsAttachmentType(msgType) {
+		return content, nil
+	}
+	var ref model.AttachmentContent
+	if err := json.Unmarshal([]byte(content), &ref); err != nil || ref.AttachmentID == 0 {
+		return "", errorx.ErrImAttachmentInvalid
+	}
+	attachment, err := u.attachmentRepo.Find(ctx, ref.AttachmentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", errorx.ErrImAttachmentNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] attachmentRepo.Find error", zap.Uint64("attachmentId", ref.AttachmentID), zap.Error(err))
+		return "", errorx.ErrInternal.Wrap(err)
+	}
+	if attachment.UserID != senderId {
+		return "", errorx.ErrImAttachmentNotFound
+	}
+	if attachment.Kind != msgType {
+		return "", errorx.ErrImAttachmentInvalid
+	}
+	data, err := json.Marshal(model.NewAttachmentContent(attachment))
+	if err != nil {
+		return "", errorx.ErrInternal.Wrap(err)
+	}
+	return string(data), nil
+}
+
+// Delivered 标记会话中 seq 及之前的消息已送达，并通知其他成员
+func (u *MessageUsecase) Delivered(ctx context.Context, userId uint64, req *request.MessageAckReq) error {
+	return u.ack(ctx, userId, req, reply.ReceiptDelivered)
+}
+
+// Read 标记会话中 seq 及之前的消息已读，并通知其他成员及自己的其他设备
+func (u *MessageUsecase) Read(ctx context.Context, userId uint64, req *request.MessageAckReq) error {
+	return u.ack(ctx, userId, req, reply.ReceiptRead)
+}
+
+func (u *MessageUsecase) ack(ctx context.Context, userId uint64, req *request.MessageAckReq, receiptType string) error {
+	conversation, err := u.conversationRepo.Find(ctx, req.ConversationId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorx.ErrImConversationNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.Find error", zap.Uint64("conversationId", req.ConversationId), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	members, err := u.conversationRepo.ListMembers(ctx, conversation.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.ListMembers error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if !isMember(members, userId) {
+		return errorx.ErrImNotConversationMember
+	}
+
+	// 客户端上报的序号不能超过会话最新序号
+	seq := min(req.Seq, conversation.LastSeq)
+	var advanced bool
+	if receiptType == reply.ReceiptRead {
+		advanced, err = u.conversationRepo.AdvanceRead(ctx, conversation.ID, userId, seq)
+	} else {
+		advanced, err = u.conversationRepo.AdvanceDelivered(ctx, conversation.ID, userId, seq)
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] advance receipt error", zap.Uint64("conversationId", conversation.ID), zap.String("type", receiptType), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if !advanced {
+		return nil
+	}
+
+	receipt := &reply.ReceiptReply{ConversationId: conversation.ID, UserId: userId, Type: receiptType, Seq: seq}
+	// 群聊回执只同步到自己的其他设备，不通知其他成员
+	if conversation.Type == model.ConversationTypeSingle {
+		for _, member := range members {
+			if member.UserID != userId {
+				u.hub.PushToUser(uint(member.UserID), EventMessageReceipt, receipt)
+			}
+		}
+	}
+	if receiptType == reply.ReceiptRead {
+		u.hub.PushToUserExcept(uint(userId), gateway.ConnIDFromContext(ctx), EventMessageReceipt, receipt)
+	}
+	return nil
+}
+
+// Sync 拉取会话中 afterSeq 之后的消息，用于重连后补齐离线消息
+func (u *MessageUsecase) Sync(ctx context.Context, userId uint64, req *request.SyncMessageReq) (*reply.MessageListReply, error) {
+	conversation, member, err := u.findMember(ctx, req.ConversationId, userId)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSyncLimit
+	}
+	messages, err := u.messageRepo.ListAfter(ctx, conversation.ID, max(req.AfterSeq, member.StartSeq), limit+1)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.ListAfter error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	result := newMessageListReply(conversation, messages, limit)
+
+	// 客户端已有的消息只返回撤回、编辑和表情回应的变化
+	if req.ChangedAfter > 0 && req.AfterSeq > member.StartSeq {
+		changed, err := u.messageRepo.ListChanged(ctx, conversation.ID, time.UnixMilli(req.ChangedAfter), req.ChangedAfterSeq, member.StartSeq, min(req.AfterSeq, conversation.LastSeq), limit+1)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.ListChanged error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+			return nil, errorx.ErrInternal.Wrap(err)
+		}
+		if len(changed) > limit {
+			changed = changed[:limit]
+			result.ChangesHasMore = true
+		}
+		for _, message := range changed {
+			result.Changes = append(result.Changes, reply.NewMessageReply(conversation, message))
+		}
+		if len(changed) > 0 {
+			last := changed[len(changed)-1]
+			result.ChangesCursor = &reply.ChangesCursor{ChangedAfter: last.UpdatedAt.UnixMilli(), ChangedAfterSeq: last.Seq}
+		}
+	}
+	if err := u.fillReactions(ctx, result.List, result.Changes); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// History 从 beforeSeq 向前分页查询历史消息，成员只能查看加入会话之后的消息
+func (u *MessageUsecase) History(ctx context.Context, userId uint64, req *request.MessageHistoryReq) (*reply.MessageListReply, error) {
+	conversation, member, err := u.findMember(ctx, req.ConversationId, userId)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultHistoryLimit
+	}
+	beforeSeq := req.BeforeSeq
+	if beforeSeq == 0 {
+		beforeSeq = conversation.LastSeq + 1
+	}
+	messages, err := u.messageRepo.ListBefore(ctx, conversation.ID, beforeSeq, member.StartSeq, limit+1)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.ListBefore error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	// 查询结果为降序，截断多查的一条后转为升序返回
+	result := newMessageListReply(conversation, messages, limit)
+	slices.Reverse(result.List)
+	if err := u.fillReactions(ctx, result.List); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Recall 撤回自己发送的消息，超过撤回时间后不能撤回；撤回后清空内容、编辑历史和表情回应
+func (u *MessageUsecase) Recall(ctx context.Context, userId uint64, req *request.MessageTargetReq) (*reply.MessageReply, error) {
+	conversation, message, members, err := u.findTarget(ctx, userId, req.ConversationId, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != userId {
+		return nil, errorx.ErrImMessageNotSender
+	}
+	if message.Recalled() {
+		return reply.NewMessageReply(conversation, message), nil
+	}
+	now := time.Now()
+	if now.Sub(message.CreatedAt) > time.Duration(u.cfg.RecallWindow)*time.Second {
+		return nil, errorx.ErrImRecallExpired
+	}
+
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := u.messageRepo.Update(ctx, message.ID, map[string]any{"content": "", "recalled_at": now}); err != nil {
+			return err
+		}
+		if err := u.messageRepo.DeleteEdits(ctx, message.ID); err != nil {
+			return err
+		}
+		if err := u.messageRepo.DeleteReactions(ctx, message.ID); err != nil {
+			return err
+		}
+		return u.conversationRepo.Touch(ctx, conversation.ID)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] recall message error", zap.Uint64("messageId", message.ID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+
+	message.Content, message.RecalledAt, message.UpdatedAt = "", &now, now
+	result := reply.NewMessageReply(conversation, message)
+	u.broadcast(ctx, members, userId, message.Seq, EventMessageRecalled, result)
+	return result, nil
+}
+
+// Edit 编辑自己发送的文本消息，新内容同样经过审核链，编辑前的内容保存到编辑历史
+func (u *MessageUsecase) Edit(ctx context.Context, userId uint64, req *request.EditMessageReq) (*reply.MessageReply, error) {
+	conversation, message, members, err := u.findTarget(ctx, userId, req.ConversationId, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != userId {
+		return nil, errorx.ErrImMessageNotSender
+	}
+	if message.Recalled() {
+		return nil, errorx.ErrImMessageRecalled
+	}
+	if message.Type != model.MessageTypeText {
+		return nil, errorx.ErrImMessageNotEditable
+	}
+	now := time.Now()
+	if u.cfg.EditWindow > 0 && now.Sub(message.CreatedAt) > time.Duration(u.cfg.EditWindow)*time.Second {
+		return nil, errorx.ErrImEditExpired
+	}
+	moderation, err := u.moderation.Check(ctx, conversation, userId, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	content := moderation.Content
+	if message.Content == content {
+		return u.withReactions(ctx, conversation, message)
+	}
+
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := u.messageRepo.CreateEdit(ctx, &model.MessageEdit{MessageID: message.ID, Content: message.Content}); err != nil {
+			return err
+		}
+		if err := u.messageRepo.Update(ctx, message.ID, map[string]any{"content": content, "edited_at": now}); err != nil {
+			return err
+		}
+		if err := u.moderation.Flag(ctx, conversation, message, moderation); err != nil {
+			return err
+		}
+		return u.conversationRepo.Touch(ctx, conversation.ID)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] edit message error", zap.Uint64("messageId", message.ID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+
+	message.Content, message.EditedAt, message.UpdatedAt = content, &now, now
+	result, err := u.withReactions(ctx, conversation, message)
+	if err != nil {
+		return nil, err
+	}
+	u.broadcast(ctx, members, userId, message.Seq, EventMessageEdited, result)
+	return result, nil
+}
+
+// Edits 消息的编辑历史，按编辑时间升序排列，已撤回的消息没有编辑历史
+func (u *MessageUsecase) Edits(ctx context.Context, userId uint64, req *request.MessageTargetReq) ([]*model.MessageEdit, error) {
+	_, message, _, err := u.findTarget(ctx, userId, req.ConversationId, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	edits, err := u.messageRepo.ListEdits(ctx, message.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.ListEdits error", zap.Uint64("messageId", message.ID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	return edits, nil
+}
+
+// AddReaction 添加表情回应，重复添加不报错
+func (u *MessageUsecase) AddReaction(ctx context.Context, userId uint64, req *request.MessageReactionReq) (*reply.MessageReply, error) {
+	return u.react(ctx, userId, req, true)
+}
+
+// RemoveReaction 取消自己的表情回应
+func (u *MessageUsecase) RemoveReaction(ctx context.Context, userId uint64, req *request.MessageReactionReq) (*reply.MessageReply, error) {
+	return u.react(ctx, userId, req, false)
+}
+
+func (u *MessageUsecase) react(ctx context.Context, userId uint64, req *request.MessageReactionReq, add bool) (*reply.MessageReply, error) {
+	conversation, message, members, err := u.findTarget(ctx, userId, req.ConversationId, req.Seq)
+	if err != nil {
+		return nil, err
+	}
+	if message.Recalled() {
+		return nil, errorx.ErrImMessageRecalled
+	}
+	if add {
+		reactions, err := u.messageRepo.ListReactions(ctx, []uint64{message.ID})
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.ListReactions error", zap.Uint64("messageId", message.ID), zap.Error(err))
+			return nil, errorx.ErrInternal.Wrap(err)
+		}
+		kinds := make(map[string]struct{}, len(reactions))
+		for _, reaction := range reactions {
+			kinds[reaction.Emoji] = struct{}{}
+		}
+		if _, ok := kinds[req.Emoji]; !ok && len(kinds) >= u.cfg.MaxReactionKind {
+			return nil, errorx.ErrImReactionLimit
+		}
+	}
+
+	now := time.Now()
+	changed := false
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if add {
+			changed, err = u.messageRepo.AddReaction(ctx, &model.MessageReaction{MessageID: message.ID, UserID: userId, Emoji: req.Emoji})
+		} else {
+			changed, err = u.messageRepo.RemoveReaction(ctx, message.ID, userId, req.Emoji)
+		}
+		if err != nil || !changed {
+			return err
+		}
+		if err := u.messageRepo.Update(ctx, message.ID, map[string]any{"updated_at": now}); err != nil {
+			return err
+		}
+		return u.conversationRepo.Touch(ctx, conversation.ID)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] update reaction error", zap.Uint64("messageId", message.ID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+
+	if changed {
+		message.UpdatedAt = now
+	}
+	result, err := u.withReactions(ctx, conversation, message)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		u.broadcast(ctx, members, userId, message.Seq, EventMessageReaction, result)
+	}
+	return result, nil
+}
+
+// findTarget 查询会话成员可见的消息，返回会话全部成员用于推送变化
+func (u *MessageUsecase) findTarget(ctx context.Context, userId, conversationId, seq uint64) (*model.Conversation, *model.Message, []*model.ConversationMember, error) {
+	conversation, err := u.conversationRepo.Find(ctx, conversationId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil, errorx.ErrImConversationNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.Find error", zap.Uint64("conversationId", conversationId), zap.Error(err))
+		return nil, nil, nil, errorx.ErrInternal.Wrap(err)
+	}
+	message, members, err := u.findMessage(ctx, conversation, userId, seq)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return conversation, message, members, nil
+}
+
+// findMessage 查询会话中的消息，成员加入会话之前的消息视为不存在
+func (u *MessageUsecase) findMessage(ctx context.Context, conversation *model.Conversation, userId, seq uint64) (*model.Message, []*model.ConversationMember, error) {
+	members, err := u.conversationRepo.ListMembers(ctx, conversation.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.ListMembers error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+		return nil, nil, errorx.ErrInternal.Wrap(err)
+	}
+	var self *model.ConversationMember
+	for _, member := range members {
+		if member.UserID == userId {
+			self = member
+			break
+		}
+	}
+	if self == nil {
+		return nil, nil, errorx.ErrImNotConversationMember
+	}
+	if seq <= self.StartSeq || seq > conversation.LastSeq {
+		return nil, nil, errorx.ErrImMessageNotFound
+	}
+	message, err := u.messageRepo.FindBySeq(ctx, conversation.ID, seq)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errorx.ErrImMessageNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.FindBySeq error", zap.Uint64("conversationId", conversation.ID), zap.Uint64("seq", seq), zap.Error(err))
+		return nil, nil, errorx.ErrInternal.Wrap(err)
+	}
+	return message, members, nil
+}
+
+// broadcast 推送消息变化给能看到该消息的会话成员，以及操作者自己的其他设备
+func (u *MessageUsecase) broadcast(ctx context.Context, members []*model.ConversationMember, userId, seq uint64, event string, data *reply.MessageReply) {
+	userIds := make([]uint, 0, len(members))
+	for _, member := range members {
+		if member.UserID != userId && member.StartSeq < seq {
+			userIds = append(userIds, uint(member.UserID))
+		}
+	}
+	u.hub.PushToUsers(userIds, event, data)
+	u.hub.PushToUserExcept(uint(userId), gateway.ConnIDFromContext(ctx), event, data)
+}
+
+func (u *MessageUsecase) withReactions(ctx context.Context, conversation *model.Conversation, message *model.Message) (*reply.MessageReply, error) {
+	result := reply.NewMessageReply(conversation, message)
+	if err := u.fillReactions(ctx, []*reply.MessageReply{result}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fillReactions 批量查询并填充消息的表情回应
+func (u *MessageUsecase) fillReactions(ctx context.Context, lists ...[]*reply.MessageReply) error {
+	var ids []uint64
+	for _, list := range lists {
+		for _, message := range list {
+			if !message.Recalled {
+				ids = append(ids, message.ID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	reactions, err := u.messageRepo.ListReactions(ctx, ids)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.ListReactions error", zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	grouped := reply.NewReactionReplies(reactions)
+	for _, list := range lists {
+		for _, message := range list {
+			message.Reactions = grouped[message.ID]
+		}
+	}
+	return nil
+}
+
+func (u *MessageUsecase) findMember(ctx context.Context, conversationId, userId uint64) (*model.Conversation, *model.ConversationMember, error) {
+	member, err := u.conversationRepo.FindMember(ctx, conversationId, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errorx.ErrImNotConversationMember
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.FindMember error", zap.Uint64("conversationId", conversationId), zap.Error(err))
+		return nil, nil, errorx.ErrInternal.Wrap(err)
+	}
+	conversation, err := u.conversationRepo.Find(ctx, conversationId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.Find error", zap.Uint64("conversationId", conversationId), zap.Error(err))
+		return nil, nil, errorx.ErrInternal.Wrap(err)
+	}
+	return conversation, member, nil
+}
+
+// newMessageListReply messages 按 limit+1 查询，多出的一条用于判断是否还有更多
+func newMessageListReply(conversation *model.Conversation, messages []*model.Message, limit int) *reply.MessageListReply {
+	result := &reply.MessageListReply{List: make([]*reply.MessageReply, 0, len(messages))}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		result.HasMore = true
+	}
+	for _, message := range messages {
+		result.List = append(result.List, reply.NewMessageReply(conversation, message))
+	}
+	return result
+}
+
+func (u *MessageUsecase) findByClientMsgId(ctx context.Context, senderId uint64, clientMsgId string) (*reply.MessageReply, error) {
+	message, err := u.messageRepo.FindByClientMsgId(ctx, senderId, clientMsgId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] messageRepo.FindByClientMsgId error", zap.Uint64("senderId", senderId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	conversation, err := u.conversationRepo.Find(ctx, message.ConversationID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] conversationRepo.Find error", zap.Uint64("conversationId", message.ConversationID), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	return reply.NewMessageReply(conversation, message), nil
+}
+
+// checkPeer 接收方必须是已启用的其他用户
+func (u *MessageUsecase) checkPeer(ctx context.Context, senderId, peerId uint64) error {
+	if peerId == senderId {
+		return errorx.ErrImPeerInvalid
+	}
+	users, err := u.userRepo.FindByIds(ctx, []int64{int64(peerId)})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MessageUsecase] userRepo.FindByIds error", zap.Uint64("peerId", peerId), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if len(users) == 0 || users[0].Status != systemModel.UserStatusEnable {
+		return errorx.ErrImPeerInvalid
+	}
+	return nil
+}
+
+func isMember(members []*model.ConversationMember, userId uint64) bool {
+	for _, member := range members {
+		if member.UserID == userId {
+			return true
+		}
+	}
+	return false
+}
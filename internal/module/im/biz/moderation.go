@@ -0,0 +1,483 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/gateway"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	systemReply "server/internal/module/system/model/reply"
+	"server/pkg/errorx"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errReviewDone 审核记录已被其他管理员处理，回滚事务
+var errReviewDone = errors.New("review already resolved")
+
+type (
+	// Moderator 审核环节，比如敏感词过滤、第三方内容审核服务
+	Moderator interface {
+		Moderate(ctx context.Context, input *ModerationInput) (*ModerationResult, error)
+	}
+
+	// ModerationInput 待审核的文本消息，GroupID 为 0 表示单聊
+	ModerationInput struct {
+		SenderID       uint64
+		ConversationID uint64
+		GroupID        uint64
+		Content        string
+	}
+
+	// ModerationResult 审核结果，Content 为替换敏感词后的内容，Words 为命中的敏感词
+	ModerationResult struct {
+		Action  string
+		Content string
+		Words   []string
+	}
+)
+
+// NewModerators 审核链上的审核环节，按顺序执行，接入第三方内容审核服务时在此注册
+func NewModerators(wordFilter *WordFilter) []Moderator {
+	return []Moderator{wordFilter}
+}
+
+// ModerationChain 在消息发送和编辑时依次执行各审核环节
+type ModerationChain struct {
+	logger     logger.Logger
+	cfg        *config.Moderation
+	moderators []Moderator
+	reviewRepo repo.ModerationReviewRepo
+}
+
+func NewModerationChain(
+	logger logger.Logger,
+	cfg *config.Moderation,
+	moderators []Moderator,
+	reviewRepo repo.ModerationReviewRepo,
+) *ModerationChain {
+	return &ModerationChain{
+		logger:     logger,
+		cfg:        cfg,
+		moderators: moderators,
+		reviewRepo: reviewRepo,
+	}
+}
+
+// Check 审核会话中的文本消息，前一环节替换后的内容交给下一环节，任一环节拒绝时返回 ErrImMessageBlocked；
+// 审核环节出错时拒绝发送，不放行未经审核的内容
+func (c *ModerationChain) Check(ctx context.Context, conversation *model.Conversation, senderId uint64, content string) (*ModerationResult, error) {
+	result := &ModerationResult{Action: model.ModerationActionPass, Content: content}
+	if !c.cfg.Enabled {
+		return result, nil
+	}
+	input := &ModerationInput{SenderID: senderId, ConversationID: conversation.ID, Content: content}
+	if conversation.Type == model.ConversationTypeGroup {
+		input.GroupID = conversation.ID
+	}
+
+	for _, moderator := range c.moderators {
+		r, err := moderator.Moderate(ctx, input)
+		if err != nil {
+			c.logger.WithContext(ctx).Error("[ModerationChain] moderate error", zap.Uint64("conversationId", conversation.ID), zap.Error(err))
+			return nil, errorx.ErrInternal.Wrap(err)
+		}
+		result.merge(r)
+		if r.Action == model.ModerationActionBlock {
+			c.logger.WithContext(ctx).Info("[ModerationChain] message blocked", zap.Uint64("senderId", senderId), zap.Uint64("conversationId", conversation.ID), zap.Strings("words", result.Words))
+			return nil, errorx.ErrImMessageBlocked
+		}
+		result.Content = r.Content
+		input.Content = r.Content
+	}
+	return result, nil
+}
+
+// CheckCustom 审核自定义消息，避免通过自定义消息绕过审核：内容为 JSON 时逐个审核其中的字符串值并原位替换，
+// 否则按文本审核
+func (c *ModerationChain) CheckCustom(ctx context.Context, conversation *model.Conversation, senderId uint64, content string) (*ModerationResult, error) {
+	if !c.cfg.Enabled {
+		return &ModerationResult{Action: model.ModerationActionPass, Content: content}, nil
+	}
+	// UseNumber 保留数字原样，避免大整数重新编码后丢失精度
+	decoder := json.NewDecoder(bytes.NewReader([]byte(content)))
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err != nil || decoder.More() {
+		return c.Check(ctx, conversation, senderId, content)
+	}
+
+	result := &ModerationResult{Action: model.ModerationActionPass, Content: content}
+	changed := false
+	payload, err := c.checkValue(ctx, conversation, senderId, payload, result, &changed)
+	if err != nil {
+		return nil, err
+	}
+	// 没有替换时保留原始内容，重新编码会改变字段顺序和格式
+	if changed {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, errorx.ErrInternal.Wrap(err)
+		}
+		result.Content = string(data)
+	}
+	return result, nil
+}
+
+// checkValue 递归审核 JSON 中的字符串值，对象的键视为结构不审核
+func (c *ModerationChain) checkValue(ctx context.Context, conversation *model.Conversation, senderId uint64, value any, result *ModerationResult, changed *bool) (any, error) {
+	switch v := value.(type) {
+	case string:
+		r, err := c.Check(ctx, conversation, senderId, v)
+		if err != nil {
+			return nil, err
+		}
+		result.merge(r)
+		if r.Content != v {
+			*changed = true
+		}
+		return r.Content, nil
+	case []any:
+		for i, item := range v {
+			checked, err := c.checkValue(ctx, conversation, senderId, item, result, changed)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = checked
+		}
+	case map[string]any:
+		for key, item := range v {
+			checked, err := c.checkValue(ctx, conversation, senderId, item, result, changed)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = checked
+		}
+	}
+	return value, nil
+}
+
+// merge 合并审核环节的结果：命中的敏感词去重累加，处理方式取更严格的一个
+func (r *ModerationResult) merge(other *ModerationResult) {
+	for _, word := range other.Words {
+		if !containsWord(r.Words, word) {
+			r.Words = append(r.Words, word)
+		}
+	}
+	if model.ModerationSeverity(other.Action) > model.ModerationSeverity(r.Action) {
+		r.Action = other.Action
+	}
+}
+
+// Flag 需要人工审核时创建审核记录，在保存消息的事务中调用
+func (c *ModerationChain) Flag(ctx context.Context, conversation *model.Conversation, message *model.Message, result *ModerationResult) error {
+	if result == nil || result.Action != model.ModerationActionReview {
+		return nil
+	}
+	review := &model.ModerationReview{
+		ConversationID: conversation.ID,
+		Seq:            message.Seq,
+		SenderID:       message.SenderID,
+		Content:        result.Content,
+		Words:          result.Words,
+		Status:         model.ReviewPending,
+	}
+	if conversation.Type == model.ConversationTypeGroup {
+		review.GroupID = conversation.ID
+	}
+	return c.reviewRepo.Create(ctx, review)
+}
+
+// ModerationUsecase 敏感词与人工审核队列的后台管理，以及群主、管理员维护的群审核设置
+type ModerationUsecase struct {
+	logger              logger.Logger
+	cfg                 *config.Moderation
+	tx                  repo.Transaction
+	wordRepo            repo.SensitiveWordRepo
+	groupModerationRepo repo.GroupModerationRepo
+	reviewRepo          repo.ModerationReviewRepo
+	groupRepo           repo.GroupRepo
+	conversationRepo    repo.ConversationRepo
+	messageRepo         repo.MessageRepo
+	wordFilter          *WordFilter
+	hub                 *gateway.Hub
+}
+
+func NewModerationUsecase(
+	logger logger.Logger,
+	cfg *config.Moderation,
+	tx repo.Transaction,
+	wordRepo repo.SensitiveWordRepo,
+	groupModerationRepo repo.GroupModerationRepo,
+	reviewRepo repo.ModerationReviewRepo,
+	groupRepo repo.GroupRepo,
+	conversationRepo repo.ConversationRepo,
+	messageRepo repo.MessageRepo,
+	wordFilter *WordFilter,
+	hub *gateway.Hub,
+) *ModerationUsecase {
+	return &ModerationUsecase{
+		logger:              logger,
+		cfg:                 cfg,
+		tx:                  tx,
+		wordRepo:            wordRepo,
+		groupModerationRepo: groupModerationRepo,
+		reviewRepo:          reviewRepo,
+		groupRepo:           groupRepo,
+		conversationRepo:    conversationRepo,
+		messageRepo:         messageRepo,
+		wordFilter:          wordFilter,
+		hub:                 hub,
+	}
+}
+
+// ListWords 分页查询全局敏感词
+func (u *ModerationUsecase) ListWords(ctx context.Context, req *request.SensitiveWordListReq) (*systemReply.PageReply, error) {
+	words, total, err := u.wordRepo.List(ctx, req)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] wordRepo.List error", zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	return systemReply.BuilderPageReply(words, total, offset, limit), nil
+}
+
+// CreateWords 批量添加敏感词，词按归一化后的形式保存，已存在的词跳过且不修改其动作
+func (u *ModerationUsecase) CreateWords(ctx context.Context, req *request.CreateSensitiveWordReq) (*reply.CreateSensitiveWordReply, error) {
+	words, err := normalizeWords(req.Words)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := u.wordRepo.FindByWords(ctx, words)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] wordRepo.FindByWords error", zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	existSet := make(map[string]struct{}, len(exists))
+	for _, word := range exists {
+		existSet[word.Word] = struct{}{}
+	}
+	var missing []*model.SensitiveWord
+	for _, word := range words {
+		if _, ok := existSet[word]; !ok {
+			missing = append(missing, &model.SensitiveWord{Word: word, Category: req.Category, Action: req.Action})
+		}
+	}
+	if err := u.wordRepo.BatchCreate(ctx, missing); err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] wordRepo.BatchCreate error", zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	u.reload(ctx)
+	return &reply.CreateSensitiveWordReply{Created: len(missing), Skipped: len(words) - len(missing)}, nil
+}
+
+// UpdateWord 修改敏感词的分类和动作
+func (u *ModerationUsecase) UpdateWord(ctx context.Context, req *request.UpdateSensitiveWordReq) error {
+	if _, err := u.wordRepo.Find(ctx, req.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.ErrImSensitiveWordNotFound
+		}
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] wordRepo.Find error", zap.Uint64("id", req.ID), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if err := u.wordRepo.Update(ctx, req.ID, map[string]any{"category": req.Category, "action": req.Action}); err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] wordRepo.Update error", zap.Uint64("id", req.ID), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	u.reload(ctx)
+	return nil
+}
+
+func (u *ModerationUsecase) DeleteWord(ctx context.Context, req *request.SensitiveWordIdReq) error {
+	deleted, err := u.wordRepo.Delete(ctx, req.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] wordRepo.Delete error", zap.Uint64("id", req.ID), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if !deleted {
+		return errorx.ErrImSensitiveWordNotFound
+	}
+	u.reload(ctx)
+	return nil
+}
+
+// reload 修改已保存，本实例重新加载失败时由定期检查重试
+func (u *ModerationUsecase) reload(ctx context.Context) {
+	if err := u.wordFilter.Reload(ctx); err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] wordFilter.Reload error", zap.Error(err))
+	}
+}
+
+// ListReviews 分页查询人工审核队列
+func (u *ModerationUsecase) ListReviews(ctx context.Context, req *request.ModerationReviewListReq) (*systemReply.PageReply, error) {
+	reviews, total, err := u.reviewRepo.List(ctx, req)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] reviewRepo.List error", zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	return systemReply.BuilderPageReply(reviews, total, offset, limit), nil
+}
+
+// ResolveReview 处理审核记录，不通过时撤回消息并通知会话成员；每条记录只能处理一次
+func (u *ModerationUsecase) ResolveReview(ctx context.Context, reviewerId uint64, req *request.ResolveReviewReq) error {
+	review, err := u.reviewRepo.Find(ctx, req.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorx.ErrImReviewNotFound
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] reviewRepo.Find error", zap.Uint64("id", req.ID), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if review.Status != model.ReviewPending {
+		return errorx.ErrImReviewDone
+	}
+
+	now := time.Now()
+	status := model.ReviewApproved
+	if !req.Approve {
+		status = model.ReviewRejected
+	}
+	var recalled *model.Message
+	err = u.tx.InTx(ctx, func(ctx context.Context) error {
+		resolved, err := u.reviewRepo.Resolve(ctx, review.ID, map[string]any{"status": status, "reviewer_id": reviewerId, "reviewed_at": now})
+		if err != nil {
+			return err
+		}
+		if !resolved {
+			return errReviewDone
+		}
+		if req.Approve {
+			return nil
+		}
+		message, err := u.messageRepo.FindBySeq(ctx, review.ConversationID, review.Seq)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil || message.Recalled() {
+			return err
+		}
+		if err := u.messageRepo.Update(ctx, message.ID, map[string]any{"content": "", "recalled_at": now}); err != nil {
+			return err
+		}
+		if err := u.messageRepo.DeleteEdits(ctx, message.ID); err != nil {
+			return err
+		}
+		if err := u.messageRepo.DeleteReactions(ctx, message.ID); err != nil {
+			return err
+		}
+		message.Content, message.RecalledAt, message.UpdatedAt = "", &now, now
+		recalled = message
+		return u.conversationRepo.Touch(ctx, review.ConversationID)
+	})
+	if errors.Is(err, errReviewDone) {
+		return errorx.ErrImReviewDone
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] resolve review error", zap.Uint64("id", review.ID), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if recalled != nil {
+		u.notifyRecalled(ctx, recalled)
+	}
+	return nil
+}
+
+// notifyRecalled 推送撤回给能看到该消息的会话成员，推送失败时成员可通过同步变化获取
+func (u *ModerationUsecase) notifyRecalled(ctx context.Context, message *model.Message) {
+	conversation, err := u.conversationRepo.Find(ctx, message.ConversationID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] conversationRepo.Find error", zap.Uint64("conversationId", message.ConversationID), zap.Error(err))
+		return
+	}
+	members, err := u.conversationRepo.ListMembers(ctx, message.ConversationID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] conversationRepo.ListMembers error", zap.Uint64("conversationId", message.ConversationID), zap.Error(err))
+		return
+	}
+	result := reply.NewMessageReply(conversation, message)
+	userIds := make([]uint, 0, len(members))
+	for _, member := range members {
+		if member.StartSeq < message.Seq {
+			userIds = append(userIds, uint(member.UserID))
+		}
+	}
+	u.hub.PushToUsers(userIds, EventMessageRecalled, result)
+}
+
+// GroupModeration 查询群审核设置，仅群主和管理员可查看
+func (u *ModerationUsecase) GroupModeration(ctx context.Context, userId, groupId uint64) (*model.GroupModeration, error) {
+	if err := u.checkGroupAdmin(ctx, groupId, userId); err != nil {
+		return nil, err
+	}
+	setting, err := u.groupModerationRepo.Find(ctx, groupId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.GroupModeration{GroupID: groupId, Words: []string{}, Action: model.ModerationActionMask}, nil
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] groupModerationRepo.Find error", zap.Uint64("groupId", groupId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	return setting, nil
+}
+
+// UpdateGroupModeration 修改群审核设置，群自定义敏感词整体替换，仅群主和管理员可操作
+func (u *ModerationUsecase) UpdateGroupModeration(ctx context.Context, userId uint64, req *request.GroupModerationReq) error {
+	if err := u.checkGroupAdmin(ctx, req.GroupId, userId); err != nil {
+		return err
+	}
+	words, err := normalizeWords(req.Words)
+	if err != nil {
+		return err
+	}
+	if len(words) > u.cfg.MaxGroupWords {
+		return errorx.ErrImGroupWordLimit
+	}
+	setting := &model.GroupModeration{GroupID: req.GroupId, Words: words, Action: req.Action, Strict: req.Strict}
+	if err := u.groupModerationRepo.Save(ctx, setting); err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] groupModerationRepo.Save error", zap.Uint64("groupId", req.GroupId), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	u.wordFilter.InvalidateGroup(req.GroupId)
+	return nil
+}
+
+func (u *ModerationUsecase) checkGroupAdmin(ctx context.Context, groupId, userId uint64) error {
+	member, err := u.groupRepo.FindMember(ctx, groupId, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorx.ErrImNotGroupMember
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ModerationUsecase] groupRepo.FindMember error", zap.Uint64("groupId", groupId), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if member.Role > model.GroupRoleAdmin {
+		return errorx.ErrImGroupPermission
+	}
+	return nil
+}
+
+// normalizeWords 归一化并去重，归一化后为空或过长的词视为无效
+func normalizeWords(words []string) ([]string, error) {
+	result := make([]string, 0, len(words))
+	seen := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		normalized := NormalizeWord(word)
+		if normalized == "" || len([]rune(normalized)) > maxWordLength {
+			return nil, errorx.ErrImSensitiveWordInvalid
+		}
+		if _, ok := seen[normalized]; !ok {
+			seen[normalized] = struct{}{}
+			result = append(result, normalized)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,335 @@
+package biz
+
+import (
+	"context"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/gateway"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/reply"
+	"server/internal/module/im/model/request"
+	"server/pkg/errorx"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	EventPresenceChanged = "presence.changed" // 订阅用户的在线状态变化
+	EventTyping          = "typing"           // 会话成员正在输入
+)
+
+// PresenceUsecase 维护在线状态与输入状态，两者都不落库
+type PresenceUsecase struct {
+	logger           logger.Logger
+	cfg              *config.Presence
+	presenceRepo     repo.PresenceRepo
+	conversationRepo repo.ConversationRepo
+	friendRepo       repo.FriendRepo
+	blockRepo        repo.BlockRepo
+	hub              *gateway.Hub
+
+	startOnce sync.Once
+	mu        sync.RWMutex
+	// watchers 被订阅用户 -> 订阅该用户的本实例连接
+	watchers map[uint64]map[string]*gateway.Conn
+	// subscriptions 连接ID -> 该连接订阅的用户，用于连接关闭时清理
+	subscriptions map[string]map[uint64]struct{}
+}
+
+func NewPresenceUsecase(
+	logger logger.Logger,
+	cfg *config.Presence,
+	presenceRepo repo.PresenceRepo,
+	conversationRepo repo.ConversationRepo,
+	friendRepo repo.FriendRepo,
+	blockRepo repo.BlockRepo,
+	hub *gateway.Hub,
+) *PresenceUsecase {
+	u := &PresenceUsecase{
+		logger:           logger,
+		cfg:              cfg,
+		presenceRepo:     presenceRepo,
+		conversationRepo: conversationRepo,
+		friendRepo:       friendRepo,
+		blockRepo:        blockRepo,
+		hub:              hub,
+		watchers:         make(map[uint64]map[string]*gateway.Conn),
+		subscriptions:    make(map[string]map[uint64]struct{}),
+	}
+	hub.OnConnect(u.connected)
+	hub.OnHeartbeat(u.heartbeat)
+	hub.OnDisconnect(u.disconnected)
+	return u
+}
+
+// InitIfNeeded 订阅其他实例广播的在线状态变化，服务启动时执行
+func (u *PresenceUsecase) InitIfNeeded() error {
+	u.startOnce.Do(func() {
+		go func() {
+			for {
+				err := u.presenceRepo.Subscribe(context.Background(), u.dispatch)
+				u.logger.Error("[PresenceUsecase] subscribe presence interrupted, retrying", zap.Error(err))
+				time.Sleep(time.Second)
+			}
+		}()
+	})
+	return nil
+}
+
+func (u *PresenceUsecase) ttl() time.Duration {
+	return time.Duration(u.cfg.TTL) * time.Second
+}
+
+func (u *PresenceUsecase) connected(conn *gateway.Conn) {
+	ctx := conn.Context()
+	userId := uint64(conn.UserID())
+	first, err := u.presenceRepo.Touch(ctx, userId, conn.ID(), u.ttl())
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] presenceRepo.Touch error", zap.Uint64("userId", userId), zap.Error(err))
+		return
+	}
+	if !first {
+		return
+	}
+	// 重新上线时恢复为在线，清除上次设置的离开状态
+	if err := u.presenceRepo.SetStatus(ctx, userId, model.PresenceOnline); err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] presenceRepo.SetStatus error", zap.Uint64("userId", userId), zap.Error(err))
+	}
+	u.publish(ctx, &model.Presence{UserID: userId, Status: model.PresenceOnline})
+}
+
+func (u *PresenceUsecase) heartbeat(conn *gateway.Conn) {
+	if _, err := u.presenceRepo.Touch(conn.Context(), uint64(conn.UserID()), conn.ID(), u.ttl()); err != nil {
+		u.logger.WithContext(conn.Context()).Error("[PresenceUsecase] presenceRepo.Touch error", zap.Uint("userId", conn.UserID()), zap.Error(err))
+	}
+}
+
+func (u *PresenceUsecase) disconnected(conn *gateway.Conn) {
+	u.unsubscribeAll(conn.ID())
+
+	ctx := conn.Context()
+	userId := uint64(conn.UserID())
+	offline, err := u.presenceRepo.Remove(ctx, userId, conn.ID())
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] presenceRepo.Remove error", zap.Uint64("userId", userId), zap.Error(err))
+		return
+	}
+	if offline {
+		now := time.Now()
+		u.publish(ctx, &model.Presence{UserID: userId, Status: model.PresenceOffline, LastSeen: &now})
+	}
+}
+
+// SetStatus 设置在线时展示的状态，用户全部连接断开后恢复为离线
+func (u *PresenceUsecase) SetStatus(ctx context.Context, userId uint64, req *request.SetPresenceReq) error {
+	if err := u.presenceRepo.SetStatus(ctx, userId, req.Status); err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] presenceRepo.SetStatus error", zap.Uint64("userId", userId), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	u.publish(ctx, &model.Presence{UserID: userId, Status: req.Status})
+	return nil
+}
+
+// Find 批量查询在线状态，只返回 userId 可见的用户，见 visible
+func (u *PresenceUsecase) Find(ctx context.Context, userId uint64, req *request.PresenceReq) ([]*model.Presence, error) {
+	userIds, err := u.visible(ctx, userId, uniqueIds(req.UserIds, userId))
+	if err != nil {
+		return nil, err
+	}
+	// 自己的状态总是可见
+	if slices.Contains(req.UserIds, userId) {
+		userIds = append(userIds, userId)
+	}
+	return u.find(ctx, userIds)
+}
+
+func (u *PresenceUsecase) find(ctx context.Context, userIds []uint64) ([]*model.Presence, error) {
+	presences, err := u.presenceRepo.Find(ctx, userIds)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] presenceRepo.Find error", zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	return presences, nil
+}
+
+// visible 过滤出 viewerId 可以查看在线状态的用户：好友或同在某个会话中的用户，且未拉黑 viewerId
+func (u *PresenceUsecase) visible(ctx context.Context, viewerId uint64, userIds []uint64) ([]uint64, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+	friendIds, err := u.friendRepo.FindFriendIds(ctx, viewerId, userIds)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] friendRepo.FindFriendIds error", zap.Uint64("userId", viewerId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	allowed := make(map[uint64]struct{}, len(userIds))
+	var others []uint64
+	for _, id := range friendIds {
+		allowed[id] = struct{}{}
+	}
+	for _, id := range userIds {
+		if _, ok := allowed[id]; !ok {
+			others = append(others, id)
+		}
+	}
+	peerIds, err := u.conversationRepo.FindPeerIds(ctx, viewerId, others)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] conversationRepo.FindPeerIds error", zap.Uint64("userId", viewerId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	for _, id := range peerIds {
+		allowed[id] = struct{}{}
+	}
+
+	candidates := make([]uint64, 0, len(allowed))
+	for _, id := range userIds {
+		if _, ok := allowed[id]; ok {
+			candidates = append(candidates, id)
+		}
+	}
+	blockerIds, err := u.blockRepo.FindBlockerIds(ctx, viewerId, candidates)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] blockRepo.FindBlockerIds error", zap.Uint64("userId", viewerId), zap.Error(err))
+		return nil, errorx.ErrInternal.Wrap(err)
+	}
+	for _, id := range blockerIds {
+		delete(allowed, id)
+	}
+
+	result := make([]uint64, 0, len(allowed))
+	for _, id := range candidates {
+		if _, ok := allowed[id]; ok {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// Subscribe 当前连接订阅用户的在线状态变化，返回这些用户的当前状态，连接关闭后订阅自动失效；
+// 不可见的用户（见 visible）直接忽略，不会出现在返回结果中
+func (u *PresenceUsecase) Subscribe(ctx context.Context, conn *gateway.Conn, req *request.PresenceReq) ([]*model.Presence, error) {
+	userIds, err := u.visible(ctx, uint64(conn.UserID()), uniqueIds(req.UserIds, uint64(conn.UserID())))
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	subscribed := u.subscriptions[conn.ID()]
+	if subscribed == nil {
+		subscribed = make(map[uint64]struct{})
+	}
+	added := 0
+	for _, userId := range userIds {
+		if _, ok := subscribed[userId]; !ok {
+			added++
+		}
+	}
+	if len(subscribed)+added > u.cfg.MaxSubscribes {
+		u.mu.Unlock()
+		return nil, errorx.ErrImPresenceSubscribeLimit
+	}
+	u.subscriptions[conn.ID()] = subscribed
+	for _, userId := range userIds {
+		subscribed[userId] = struct{}{}
+		conns := u.watchers[userId]
+		if conns == nil {
+			conns = make(map[string]*gateway.Conn)
+			u.watchers[userId] = conns
+		}
+		conns[conn.ID()] = conn
+	}
+	u.mu.Unlock()
+
+	return u.find(ctx, userIds)
+}
+
+// Unsubscribe 取消当前连接对指定用户的订阅
+func (u *PresenceUsecase) Unsubscribe(_ context.Context, conn *gateway.Conn, req *request.PresenceReq) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, userId := range req.UserIds {
+		u.unwatch(conn.ID(), userId)
+	}
+}
+
+func (u *PresenceUsecase) unsubscribeAll(connId string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for userId := range u.subscriptions[connId] {
+		u.unwatch(connId, userId)
+	}
+	delete(u.subscriptions, connId)
+}
+
+func (u *PresenceUsecase) unwatch(connId string, userId uint64) {
+	if subscribed, ok := u.subscriptions[connId]; ok {
+		delete(subscribed, userId)
+	}
+	if conns, ok := u.watchers[userId]; ok {
+		delete(conns, connId)
+		if len(conns) == 0 {
+			delete(u.watchers, userId)
+		}
+	}
+}
+
+func (u *PresenceUsecase) publish(ctx context.Context, presence *model.Presence) {
+	if err := u.presenceRepo.Publish(ctx, presence); err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] presenceRepo.Publish error", zap.Uint64("userId", presence.UserID), zap.Error(err))
+	}
+}
+
+// dispatch 将在线状态变化推送给本实例上订阅了该用户的连接，订阅后被该用户拉黑的连接不再推送
+func (u *PresenceUsecase) dispatch(presence *model.Presence) {
+	u.mu.RLock()
+	conns := make([]*gateway.Conn, 0, len(u.watchers[presence.UserID]))
+	watcherIds := make([]uint64, 0, len(u.watchers[presence.UserID]))
+	for _, conn := range u.watchers[presence.UserID] {
+		conns = append(conns, conn)
+		watcherIds = append(watcherIds, uint64(conn.UserID()))
+	}
+	u.mu.RUnlock()
+	if len(conns) == 0 {
+		return
+	}
+
+	blockedIds, err := u.blockRepo.FindBlockedIds(context.Background(), presence.UserID, uniqueIds(watcherIds, 0))
+	if err != nil {
+		// 无法确认拉黑关系时不推送，订阅方可重新订阅获取最新状态
+		u.logger.Error("[PresenceUsecase] blockRepo.FindBlockedIds error", zap.Uint64("userId", presence.UserID), zap.Error(err))
+		return
+	}
+	for _, conn := range conns {
+		if slices.Contains(blockedIds, uint64(conn.UserID())) {
+			continue
+		}
+		_ = conn.Push(EventPresenceChanged, presence)
+	}
+}
+
+// Typing 向会话其他成员广播输入状态，只推送给在线连接，不落库；客户端应自行节流
+func (u *PresenceUsecase) Typing(ctx context.Context, conn *gateway.Conn, req *request.TypingReq) error {
+	userId := uint64(conn.UserID())
+	members, err := u.conversationRepo.ListMembers(ctx, req.ConversationId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[PresenceUsecase] conversationRepo.ListMembers error", zap.Uint64("conversationId", req.ConversationId), zap.Error(err))
+		return errorx.ErrInternal.Wrap(err)
+	}
+	if !isMember(members, userId) {
+		return errorx.ErrImNotConversationMember
+	}
+
+	typing := &reply.TypingReply{ConversationId: req.ConversationId, UserId: userId, Typing: req.Typing}
+	userIds := make([]uint, 0, len(members))
+	for _, member := range members {
+		if member.UserID != userId {
+			userIds = append(userIds, uint(member.UserID))
+		}
+	}
+	u.hub.PushToUsers(userIds, EventTyping, typing)
+	return nil
+}
@@ -0,0 +1,19 @@
+package biz
+
+import (
+	"github.com/google/wire"
+)
+
+var ProviderSet = wire.NewSet(
+	NewInitUsecase,
+	NewMessageUsecase,
+	NewGroupUsecase,
+	NewConversationUsecase,
+	NewPresenceUsecase,
+	NewContactUsecase,
+	NewAttachmentUsecase,
+	NewModerationUsecase,
+	NewModerationChain,
+	NewModerators,
+	NewWordFilter,
+)
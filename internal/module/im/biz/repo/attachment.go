@@ -0,0 +1,11 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/im/model"
+)
+
+type AttachmentRepo interface {
+	Create(ctx context.Context, attachment *model.Attachment) error
+	Find(ctx context.Context, id uint64) (*model.Attachment, error)
+}
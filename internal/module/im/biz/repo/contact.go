@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/im/model"
+)
+
+type FriendRepo interface {
+	// FindPendingRequest 查询 fromId 发给 toId 的待处理申请
+	FindPendingRequest(ctx context.Context, fromId, toId uint64) (*model.FriendRequest, error)
+	FindRequest(ctx context.Context, id uint64) (*model.FriendRequest, error)
+	CreateRequest(ctx context.Context, request *model.FriendRequest) error
+	UpdateRequest(ctx context.Context, id uint64, fields map[string]any) error
+	// ListRequests 查询收到（received 为 true）或发出的申请，按更新时间倒序
+	ListRequests(ctx context.Context, userId uint64, received bool, limit int) ([]*model.FriendRequest, error)
+
+	// AddPair 建立双向好友关系，已存在的记录保持不变
+	AddPair(ctx context.Context, userId, friendId uint64) error
+	// RemovePair 解除双向好友关系
+	RemovePair(ctx context.Context, userId, friendId uint64) error
+	Find(ctx context.Context, userId, friendId uint64) (*model.Friend, error)
+	List(ctx context.Context, userId uint64) ([]*model.Friend, error)
+	// FindFriendIds 查询 friendIds 中是 userId 好友的用户
+	FindFriendIds(ctx context.Context, userId uint64, friendIds []uint64) ([]uint64, error)
+	Update(ctx context.Context, userId, friendId uint64, fields map[string]any) error
+}
+
+type BlockRepo interface {
+	Create(ctx context.Context, userId, blockedId uint64) error
+	Delete(ctx context.Context, userId, blockedId uint64) error
+	// Exists userId 是否拉黑了 blockedId
+	Exists(ctx context.Context, userId, blockedId uint64) (bool, error)
+	List(ctx context.Context, userId uint64) ([]*model.Block, error)
+	// FindBlockerIds 查询 userIds 中拉黑了 blockedId 的用户
+	FindBlockerIds(ctx context.Context, blockedId uint64, userIds []uint64) ([]uint64, error)
+	// FindBlockedIds 查询 blockedIds 中被 userId 拉黑的用户
+	FindBlockedIds(ctx context.Context, userId uint64, blockedIds []uint64) ([]uint64, error)
+}
@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/im/model"
+	"time"
+)
+
+type ConversationRepo interface {
+	// FindOrCreateSingle 查找或创建两个用户之间的单聊会话及双方成员记录
+	FindOrCreateSingle(ctx context.Context, userId, peerId uint64) (*model.Conversation, error)
+	// CreateGroup 创建群聊会话，成员通过 AddMembers 加入
+	CreateGroup(ctx context.Context) (*model.Conversation, error)
+	Find(ctx context.Context, id uint64) (*model.Conversation, error)
+	// Touch 更新会话的更新时间，使消息变化出现在成员的增量会话列表中
+	Touch(ctx context.Context, id uint64) error
+	// NextSeq 递增并返回会话的消息序号，必须在事务中调用，行锁持续到事务结束
+	NextSeq(ctx context.Context, id uint64) (uint64, error)
+	FindMember(ctx context.Context, conversationId, userId uint64) (*model.ConversationMember, error)
+	ListMembers(ctx context.Context, conversationId uint64) ([]*model.ConversationMember, error)
+	// FindPeerIds 查询 peerIds 中与 userId 同在某个会话（单聊或群聊）的用户
+	FindPeerIds(ctx context.Context, userId uint64, peerIds []uint64) ([]uint64, error)
+	// ListByUser 按 (更新时间, 会话ID) 升序查询用户在游标 (updatedAfter, afterId) 之后的会话，
+	// 同一毫秒内更新的多个会话按会话ID继续分页，不会因时间戳相同而被跳过
+	ListByUser(ctx context.Context, userId uint64, updatedAfter time.Time, afterId uint64, limit int) ([]*model.UserConversation, error)
+	// AddMembers 添加会话成员，已送达、已读位置从 startSeq 开始，新成员看不到加入前的消息
+	AddMembers(ctx context.Context, conversationId uint64, userIds []uint64, startSeq uint64) error
+	RemoveMembers(ctx context.Context, conversationId uint64, userIds []uint64) error
+	// AdvanceDelivered 将已送达位置推进到 seq，不会回退，返回是否发生变化
+	AdvanceDelivered(ctx context.Context, conversationId, userId, seq uint64) (bool, error)
+	// AdvanceRead 将已读位置推进到 seq，同时推进已送达位置，不会回退，返回是否发生变化
+	AdvanceRead(ctx context.Context, conversationId, userId, seq uint64) (bool, error)
+}
@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/im/model"
+)
+
+type GroupRepo interface {
+	Create(ctx context.Context, group *model.Group) error
+	Find(ctx context.Context, id uint64) (*model.Group, error)
+	// FindForUpdate 加行锁读取群组，必须在事务中调用，用于串行化成员变更
+	FindForUpdate(ctx context.Context, id uint64) (*model.Group, error)
+	Update(ctx context.Context, id uint64, fields map[string]any) error
+	// AddMemberCount 调整成员数，delta 可为负数
+	AddMemberCount(ctx context.Context, id uint64, delta int) error
+	CountOwned(ctx context.Context, ownerId uint64) (int64, error)
+	// ListByUser 查询用户加入的群组
+	ListByUser(ctx context.Context, userId uint64) ([]*model.Group, error)
+
+	// AddMembers 添加群成员，已是成员的用户会被忽略，返回实际添加的数量
+	AddMembers(ctx context.Context, members []*model.GroupMember) (int, error)
+	FindMember(ctx context.Context, groupId, userId uint64) (*model.GroupMember, error)
+	// FindMembers 查询指定用户中属于该群的成员
+	FindMembers(ctx context.Context, groupId uint64, userIds []uint64) ([]*model.GroupMember, error)
+	ListMembers(ctx context.Context, groupId uint64) ([]*model.GroupMember, error)
+	UpdateMember(ctx context.Context, groupId, userId uint64, fields map[string]any) error
+	RemoveMember(ctx context.Context, groupId, userId uint64) error
+}
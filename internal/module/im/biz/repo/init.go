@@ -0,0 +1,8 @@
+package repo
+
+import "gorm.io/gorm/schema"
+
+type InitRepo interface {
+	// AutoMigrate 同步 IM 数据库表结构
+	AutoMigrate(tables []schema.Tabler) error
+}
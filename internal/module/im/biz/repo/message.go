@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/im/model"
+	"time"
+)
+
+type MessageRepo interface {
+	// Create 创建消息，(SenderID, ClientMsgID) 已存在时返回 false
+	Create(ctx context.Context, message *model.Message) (bool, error)
+	FindByClientMsgId(ctx context.Context, senderId uint64, clientMsgId string) (*model.Message, error)
+	// ListAfter 按序号升序查询会话中序号大于 afterSeq 的消息
+	ListAfter(ctx context.Context, conversationId, afterSeq uint64, limit int) ([]*model.Message, error)
+	// ListBefore 按序号降序查询会话中序号在 (minSeq, beforeSeq) 区间内的消息
+	ListBefore(ctx context.Context, conversationId, beforeSeq, minSeq uint64, limit int) ([]*model.Message, error)
+	// FindBySeqs 按会话ID和序号批量查询消息，seqs 的键为会话ID
+	FindBySeqs(ctx context.Context, seqs map[uint64]uint64) ([]*model.Message, error)
+	FindBySeq(ctx context.Context, conversationId, seq uint64) (*model.Message, error)
+	// ListChanged 按 (更新时间, 序号) 升序查询序号在 (minSeq, maxSeq] 区间内、位于游标 (changedAfter, afterSeq) 之后的消息
+	ListChanged(ctx context.Context, conversationId uint64, changedAfter time.Time, afterSeq, minSeq, maxSeq uint64, limit int) ([]*model.Message, error)
+	// Update 更新消息，values 中未指定 updated_at 时由 GORM 自动更新
+	Update(ctx context.Context, id uint64, values map[string]any) error
+
+	CreateEdit(ctx context.Context, edit *model.MessageEdit) error
+	// ListEdits 按编辑时间升序查询消息的编辑历史
+	ListEdits(ctx context.Context, messageId uint64) ([]*model.MessageEdit, error)
+	DeleteEdits(ctx context.Context, messageId uint64) error
+
+	// AddReaction 添加表情回应，已存在时返回 false
+	AddReaction(ctx context.Context, reaction *model.MessageReaction) (bool, error)
+	// RemoveReaction 删除表情回应，不存在时返回 false
+	RemoveReaction(ctx context.Context, messageId, userId uint64, emoji string) (bool, error)
+	// ListReactions 按添加时间升序查询消息的表情回应
+	ListReactions(ctx context.Context, messageIds []uint64) ([]*model.MessageReaction, error)
+	DeleteReactions(ctx context.Context, messageId uint64) error
+}
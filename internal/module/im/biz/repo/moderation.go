@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/request"
+)
+
+type SensitiveWordRepo interface {
+	List(ctx context.Context, req *request.SensitiveWordListReq) ([]*model.SensitiveWord, int64, error)
+	ListAll(ctx context.Context) ([]*model.SensitiveWord, error)
+	// Version 敏感词数量与最后更新时间组成的版本号，增删改都会使其变化，用于判断是否需要重新加载
+	Version(ctx context.Context) (string, error)
+	Find(ctx context.Context, id uint64) (*model.SensitiveWord, error)
+	FindByWords(ctx context.Context, words []string) ([]*model.SensitiveWord, error)
+	BatchCreate(ctx context.Context, words []*model.SensitiveWord) error
+	Update(ctx context.Context, id uint64, fields map[string]any) error
+	// Delete 删除敏感词，返回是否存在
+	Delete(ctx context.Context, id uint64) (bool, error)
+}
+
+type GroupModerationRepo interface {
+	Find(ctx context.Context, groupId uint64) (*model.GroupModeration, error)
+	// Save 保存群审核设置，不存在时创建
+	Save(ctx context.Context, setting *model.GroupModeration) error
+}
+
+type ModerationReviewRepo interface {
+	Create(ctx context.Context, review *model.ModerationReview) error
+	Find(ctx context.Context, id uint64) (*model.ModerationReview, error)
+	List(ctx context.Context, req *request.ModerationReviewListReq) ([]*model.ModerationReview, int64, error)
+	// Resolve 将待审核的记录更新为审核结果，记录已被审核时返回 false
+	Resolve(ctx context.Context, id uint64, fields map[string]any) (bool, error)
+}
@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/im/model"
+	"time"
+)
+
+// PresenceRepo 在线状态存储，配置了 Redis 时多实例共享，否则保存在进程内存中
+type PresenceRepo interface {
+	// Touch 登记或续期用户的一个连接，返回该用户此前是否没有任何在线连接
+	Touch(ctx context.Context, userId uint64, connId string, ttl time.Duration) (bool, error)
+	// Remove 移除用户的一个连接并记录最后在线时间，返回该用户是否已没有在线连接
+	Remove(ctx context.Context, userId uint64, connId string) (bool, error)
+	// SetStatus 设置用户在线时展示的状态（online 或 away）
+	SetStatus(ctx context.Context, userId uint64, status string) error
+	// Find 批量查询在线状态，没有未过期连接的用户为 offline
+	Find(ctx context.Context, userIds []uint64) ([]*model.Presence, error)
+
+	// Publish 广播在线状态变化，所有实例的 Subscribe 回调都会收到
+	Publish(ctx context.Context, presence *model.Presence) error
+	// Subscribe 订阅在线状态变化，阻塞直到 ctx 取消
+	Subscribe(ctx context.Context, handler func(presence *model.Presence)) error
+}
@@ -0,0 +1,271 @@
+package biz
+
+import (
+	"context"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+	"server/pkg/ahocorasick"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxWordLength 归一化后敏感词的最大长度（字符数）
+const maxWordLength = 64
+
+// wordSet 一组敏感词构建的自动机，actions 与构建时的词一一对应
+type wordSet struct {
+	matcher *ahocorasick.Matcher
+	words   []string
+	actions []string
+}
+
+func newWordSet(words, actions []string) *wordSet {
+	return &wordSet{matcher: ahocorasick.Build(words), words: words, actions: actions}
+}
+
+// groupWordSet 群审核设置，words 为空表示群没有自定义敏感词
+type groupWordSet struct {
+	words    *wordSet
+	strict   bool
+	loadedAt time.Time
+}
+
+// WordFilter 基于 Aho-Corasick 自动机的敏感词过滤。全局词库定期检查版本，变化后整体重建；
+// 群审核设置按需加载并缓存 reload_interval 秒，本实例修改后立即失效
+type WordFilter struct {
+	logger              logger.Logger
+	cfg                 *config.Moderation
+	wordRepo            repo.SensitiveWordRepo
+	groupModerationRepo repo.GroupModerationRepo
+
+	startOnce sync.Once
+	global    atomic.Pointer[wordSet]
+	reloadMu  sync.Mutex
+	version   string
+
+	mu     sync.Mutex
+	groups map[uint64]*groupWordSet
+}
+
+func NewWordFilter(
+	logger logger.Logger,
+	cfg *config.Moderation,
+	wordRepo repo.SensitiveWordRepo,
+	groupModerationRepo repo.GroupModerationRepo,
+) *WordFilter {
+	f := &WordFilter{
+		logger:              logger,
+		cfg:                 cfg,
+		wordRepo:            wordRepo,
+		groupModerationRepo: groupModerationRepo,
+		groups:              make(map[uint64]*groupWordSet),
+	}
+	f.global.Store(newWordSet(nil, nil))
+	return f
+}
+
+// InitIfNeeded 加载敏感词并定期检查变化，服务启动时在迁移之后执行，加载失败时拒绝启动
+func (f *WordFilter) InitIfNeeded() error {
+	var err error
+	f.startOnce.Do(func() {
+		if err = f.Reload(context.Background()); err != nil {
+			return
+		}
+		go f.watch()
+	})
+	return err
+}
+
+func (f *WordFilter) interval() time.Duration {
+	return time.Duration(f.cfg.ReloadInterval) * time.Second
+}
+
+func (f *WordFilter) watch() {
+	ticker := time.NewTicker(f.interval())
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx := context.Background()
+		version, err := f.wordRepo.Version(ctx)
+		if err != nil {
+			f.logger.Error("[WordFilter] wordRepo.Version error", zap.Error(err))
+			continue
+		}
+		f.reloadMu.Lock()
+		changed := version != f.version
+		f.reloadMu.Unlock()
+		if changed {
+			if err := f.Reload(ctx); err != nil {
+				f.logger.Error("[WordFilter] reload sensitive words error", zap.Error(err))
+			}
+		}
+		f.pruneGroups()
+	}
+}
+
+// Reload 重新加载全局敏感词，先读取版本再读取词库，期间发生的修改会在下一次检查时重新加载
+func (f *WordFilter) Reload(ctx context.Context) error {
+	f.reloadMu.Lock()
+	defer f.reloadMu.Unlock()
+	version, err := f.wordRepo.Version(ctx)
+	if err != nil {
+		return err
+	}
+	list, err := f.wordRepo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	words := make([]string, 0, len(list))
+	actions := make([]string, 0, len(list))
+	for _, word := range list {
+		words = append(words, word.Word)
+		actions = append(actions, word.Action)
+	}
+	f.global.Store(newWordSet(words, actions))
+	f.version = version
+	f.logger.WithContext(ctx).Info("[WordFilter] sensitive words loaded", zap.Int("count", len(words)))
+	return nil
+}
+
+// InvalidateGroup 群审核设置修改后清除缓存，其他实例在缓存过期后生效
+func (f *WordFilter) InvalidateGroup(groupId uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.groups, groupId)
+}
+
+func (f *WordFilter) pruneGroups() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for groupId, group := range f.groups {
+		if time.Since(group.loadedAt) >= f.interval() {
+			delete(f.groups, groupId)
+		}
+	}
+}
+
+// group 查询群审核设置，加载失败时只使用全局敏感词，不影响消息发送
+func (f *WordFilter) group(ctx context.Context, groupId uint64) *groupWordSet {
+	f.mu.Lock()
+	group, ok := f.groups[groupId]
+	f.mu.Unlock()
+	if ok && time.Since(group.loadedAt) < f.interval() {
+		return group
+	}
+
+	group = &groupWordSet{loadedAt: time.Now()}
+	setting, err := f.groupModerationRepo.Find(ctx, groupId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		f.logger.WithContext(ctx).Error("[WordFilter] groupModerationRepo.Find error", zap.Uint64("groupId", groupId), zap.Error(err))
+		return group
+	}
+	if setting != nil {
+		group.strict = setting.Strict
+		if len(setting.Words) > 0 {
+			actions := make([]string, len(setting.Words))
+			for i := range actions {
+				actions[i] = setting.Action
+			}
+			group.words = newWordSet(setting.Words, actions)
+		}
+	}
+	f.mu.Lock()
+	f.groups[groupId] = group
+	f.mu.Unlock()
+	return group
+}
+
+// Moderate 匹配全局敏感词和群自定义敏感词，取命中动作中最严重的一个；需替换的词替换为 *
+func (f *WordFilter) Moderate(ctx context.Context, input *ModerationInput) (*ModerationResult, error) {
+	text := []rune(input.Content)
+	normalized, positions := normalizeText(text)
+	result := &ModerationResult{Action: model.ModerationActionPass, Content: input.Content}
+	var masked []bool
+
+	hit := func(set *wordSet, match ahocorasick.Match, action string) {
+		word := set.words[match.Pattern]
+		if !containsWord(result.Words, word) {
+			result.Words = append(result.Words, word)
+		}
+		if model.ModerationSeverity(action) > model.ModerationSeverity(result.Action) {
+			result.Action = action
+		}
+		if action == model.ModerationActionMask {
+			if masked == nil {
+				masked = make([]bool, len(text))
+			}
+			// 连同敏感词中间夹杂的空白和标点一起替换
+			for i := positions[match.Start]; i <= positions[match.End-1]; i++ {
+				masked[i] = true
+			}
+		}
+	}
+
+	global := f.global.Load()
+	var group *groupWordSet
+	if input.GroupID > 0 {
+		group = f.group(ctx, input.GroupID)
+	}
+	for _, match := range global.matcher.FindAll(normalized) {
+		action := global.actions[match.Pattern]
+		if group != nil && group.strict && action == model.ModerationActionMask {
+			action = model.ModerationActionBlock
+		}
+		hit(global, match, action)
+	}
+	if group != nil && group.words != nil {
+		for _, match := range group.words.matcher.FindAll(normalized) {
+			hit(group.words, match, group.words.actions[match.Pattern])
+		}
+	}
+
+	if masked != nil && result.Action != model.ModerationActionBlock {
+		for i := range text {
+			if masked[i] {
+				text[i] = '*'
+			}
+		}
+		result.Content = string(text)
+	}
+	return result, nil
+}
+
+// NormalizeWord 敏感词的归一化形式，与匹配时对消息内容的处理一致
+func NormalizeWord(word string) string {
+	normalized, _ := normalizeText([]rune(word))
+	return string(normalized)
+}
+
+// normalizeText 转为小写、全角转半角并去除空白、标点和符号，避免插入空格或符号绕过过滤；
+// positions 为归一化后每个字符在原文中的下标
+func normalizeText(text []rune) ([]rune, []int) {
+	normalized := make([]rune, 0, len(text))
+	positions := make([]int, 0, len(text))
+	for i, r := range text {
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		normalized = append(normalized, unicode.ToLower(r))
+		positions = append(positions, i)
+	}
+	return normalized, positions
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// Envelope 转发给其他实例的推送，由目标实例推送到这些用户在该实例上的连接
+	Envelope struct {
+		UserIDs      []uint          `json:"userIds"`
+		ExceptConnID string          `json:"exceptConnId,omitempty"`
+		Event        string          `json:"event"`
+		Data         json.RawMessage `json:"data,omitempty"`
+	}
+
+	// Broker 跨实例路由，记录用户连接所在的实例并在实例间转发推送
+	Broker interface {
+		// Register 记录用户在该实例上有连接，超过 ttl 未续期视为失效
+		Register(ctx context.Context, userID uint, instanceID string, ttl time.Duration) error
+		// Unregister 用户在该实例上的连接已全部关闭
+		Unregister(ctx context.Context, userID uint, instanceID string) error
+		// Instances 批量返回用户有连接的全部实例，包括当前实例，没有连接的用户不出现在结果中
+		Instances(ctx context.Context, userIDs []uint) (map[uint][]string, error)
+		// Publish 将推送转发到指定实例
+		Publish(ctx context.Context, instanceID string, envelope *Envelope) error
+		// Subscribe 接收转发到指定实例的推送，阻塞直到 ctx 取消或连接中断
+		Subscribe(ctx context.Context, instanceID string, handler func(envelope *Envelope)) error
+	}
+)
+
+// NewBroker 未配置 Redis 时使用进程内实现，只支持单实例部署
+func NewBroker(rdb *redis.Client) Broker {
+	if rdb == nil {
+		return NewLocalBroker()
+	}
+	return &redisBroker{rdb: rdb}
+}
+
+// localBroker 进程内实现，同一进程中的多个 Hub 可以互相转发，用于单实例部署和测试
+type localBroker struct {
+	mu       sync.Mutex
+	routes   map[uint]map[string]time.Time
+	handlers map[string][]func(envelope *Envelope)
+}
+
+func NewLocalBroker() Broker {
+	return &localBroker{
+		routes:   make(map[uint]map[string]time.Time),
+		handlers: make(map[string][]func(envelope *Envelope)),
+	}
+}
+
+func (b *localBroker) Register(_ context.Context, userID uint, instanceID string, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	instances, ok := b.routes[userID]
+	if !ok {
+		instances = make(map[string]time.Time)
+		b.routes[userID] = instances
+	}
+	instances[instanceID] = time.Now().Add(ttl)
+	return nil
+}
+
+func (b *localBroker) Unregister(_ context.Context, userID uint, instanceID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if instances, ok := b.routes[userID]; ok {
+		delete(instances, instanceID)
+		if len(instances) == 0 {
+			delete(b.routes, userID)
+		}
+	}
+	return nil
+}
+
+func (b *localBroker) Instances(_ context.Context, userIDs []uint) (map[uint][]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	result := make(map[uint][]string, len(userIDs))
+	for _, userID := range userIDs {
+		for instanceID, expireAt := range b.routes[userID] {
+			if expireAt.After(now) {
+				result[userID] = append(result[userID], instanceID)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (b *localBroker) Publish(_ context.Context, instanceID string, envelope *Envelope) error {
+	b.mu.Lock()
+	handlers := b.handlers[instanceID]
+	b.mu.Unlock()
+	for _, handler := range handlers {
+		handler(envelope)
+	}
+	return nil
+}
+
+func (b *localBroker) Subscribe(ctx context.Context, instanceID string, handler func(envelope *Envelope)) error {
+	b.mu.Lock()
+	b.handlers[instanceID] = append(b.handlers[instanceID], handler)
+	b.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
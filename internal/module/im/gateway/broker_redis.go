@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	routeUserKey    = "im:route:user:%d"     // zset，member 为实例ID，score 为过期时间（毫秒）
+	routeInstanceCh = "im:route:instance:%s" // 每个实例订阅自己的频道
+)
+
+// redisBroker 路由表按实例记录过期时间，实例异常退出后其路由到期自动失效；
+// 转发使用 pub/sub，目标实例不在线时推送丢失，由客户端重连后通过消息同步补齐
+type redisBroker struct {
+	rdb *redis.Client
+}
+
+func (b *redisBroker) Register(ctx context.Context, userID uint, instanceID string, ttl time.Duration) error {
+	key := fmt.Sprintf(routeUserKey, userID)
+	pipe := b.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(time.Now().Add(ttl).UnixMilli()), Member: instanceID})
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return errors.WithStack(err)
+}
+
+func (b *redisBroker) Unregister(ctx context.Context, userID uint, instanceID string) error {
+	return errors.WithStack(b.rdb.ZRem(ctx, fmt.Sprintf(routeUserKey, userID), instanceID).Err())
+}
+
+// Instances 通过 pipeline 一次往返查询全部用户的路由，群聊推送不必逐个成员访问 Redis
+func (b *redisBroker) Instances(ctx context.Context, userIDs []uint) (map[uint][]string, error) {
+	result := make(map[uint][]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	pipe := b.rdb.Pipeline()
+	cmds := make([]*redis.StringSliceCmd, len(userIDs))
+	for i, userID := range userIDs {
+		cmds[i] = pipe.ZRangeByScore(ctx, fmt.Sprintf(routeUserKey, userID), &redis.ZRangeBy{Min: "(" + now, Max: "+inf"})
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, errors.WithStack(err)
+	}
+	for i, cmd := range cmds {
+		if instances := cmd.Val(); len(instances) > 0 {
+			result[userIDs[i]] = instances
+		}
+	}
+	return result, nil
+}
+
+func (b *redisBroker) Publish(ctx context.Context, instanceID string, envelope *Envelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(b.rdb.Publish(ctx, fmt.Sprintf(routeInstanceCh, instanceID), data).Err())
+}
+
+func (b *redisBroker) Subscribe(ctx context.Context, instanceID string, handler func(envelope *Envelope)) error {
+	sub := b.rdb.Subscribe(ctx, fmt.Sprintf(routeInstanceCh, instanceID))
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return errors.WithStack(err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var envelope Envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+				continue
+			}
+			handler(&envelope)
+		}
+	}
+}
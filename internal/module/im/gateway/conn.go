@@ -0,0 +1,180 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/pkg/errorx"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// ErrSlowConsumer 发送队列已满，连接被关闭
+var ErrSlowConsumer = errors.New("gateway: send buffer full")
+
+// Conn 用户的一个 WebSocket 连接，同一用户的多个设备各自对应一个连接
+type Conn struct {
+	id          string
+	userID      uint
+	device      string
+	lang        string
+	connectedAt time.Time
+
+	ctx    context.Context
+	hub    *Hub
+	ws     *websocket.Conn
+	cfg    *config.Gateway
+	logger logger.Logger
+
+	send      chan []byte
+	pushSeq   atomic.Uint64
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMsg  []byte
+}
+
+func (c *Conn) ID() string {
+	return c.id
+}
+
+func (c *Conn) UserID() uint {
+	return c.userID
+}
+
+func (c *Conn) Device() string {
+	return c.device
+}
+
+// Lang 建立连接时根据 Accept-Language 选择的语言
+func (c *Conn) Lang() string {
+	return c.lang
+}
+
+func (c *Conn) ConnectedAt() time.Time {
+	return c.connectedAt
+}
+
+// Context 连接的上下文，携带建立连接时的请求 ID，连接关闭后取消
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Push 推送事件，不阻塞：发送队列已满时关闭连接，由客户端重连后自行同步
+func (c *Conn) Push(event string, data any) error {
+	return c.write(&Frame{Type: FramePush, Seq: c.pushSeq.Add(1), Event: event}, data)
+}
+
+func (c *Conn) ack(seq uint64, data any, err error) error {
+	frame := &Frame{Type: FrameAck, Seq: seq}
+	if err != nil {
+		frame.Error = newFrameError(err, c.lang)
+		data = nil
+	}
+	return c.write(frame, data)
+}
+
+func (c *Conn) write(frame *Frame, data any) error {
+	payload, err := encodeFrame(frame, data)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-c.done:
+		return websocket.ErrCloseSent
+	default:
+	}
+	select {
+	case c.send <- payload:
+		return nil
+	default:
+		c.logger.WithContext(c.ctx).Warn("[Gateway] slow consumer, closing connection",
+			zap.Uint("userId", c.userID), zap.String("connId", c.id))
+		c.Close(websocket.CloseTryAgainLater, "slow consumer")
+		return ErrSlowConsumer
+	}
+}
+
+// Close 关闭连接，可重复调用
+func (c *Conn) Close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeMsg = websocket.FormatCloseMessage(code, reason)
+		close(c.done)
+	})
+}
+
+func (c *Conn) pongWait() time.Duration {
+	return time.Duration(c.cfg.PongWait) * time.Second
+}
+
+func (c *Conn) writeWait() time.Duration {
+	return time.Duration(c.cfg.WriteWait) * time.Second
+}
+
+// readLoop 读取客户端帧直到连接断开，同一连接的请求按顺序处理
+func (c *Conn) readLoop() {
+	defer c.Close(websocket.CloseNormalClosure, "")
+
+	c.ws.SetReadLimit(c.cfg.MaxMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(c.pongWait()))
+	c.ws.SetPongHandler(func(string) error {
+		c.hub.heartbeat(c)
+		return c.ws.SetReadDeadline(time.Now().Add(c.pongWait()))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				c.logger.WithContext(c.ctx).Info("[Gateway] read error", zap.Uint("userId", c.userID), zap.String("connId", c.id), zap.Error(err))
+			}
+			return
+		}
+		// 收到任何数据都说明连接存活
+		_ = c.ws.SetReadDeadline(time.Now().Add(c.pongWait()))
+
+		var frame Frame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			_ = c.ack(0, nil, errorx.ErrImFrameInvalid.Wrap(err))
+			continue
+		}
+		if frame.V != ProtocolVersion {
+			_ = c.ack(frame.Seq, nil, errorx.ErrImProtocolVersion)
+			continue
+		}
+		c.hub.dispatch(c, &frame)
+	}
+}
+
+// writeLoop 串行写入发送队列中的帧并定时发送 ping，连接关闭时发送关闭帧
+func (c *Conn) writeLoop() {
+	ticker := time.NewTicker(c.pongWait() * 9 / 10)
+	defer func() {
+		ticker.Stop()
+		_ = c.ws.Close()
+	}()
+
+	for {
+		select {
+		case payload := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(c.writeWait()))
+			if err := c.ws.WriteMessage(websocket.TextMessage, payload); err != nil {
+				c.Close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.writeWait())); err != nil {
+				c.Close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-c.done:
+			_ = c.ws.WriteControl(websocket.CloseMessage, c.closeMsg, time.Now().Add(c.writeWait()))
+			return
+		}
+	}
+}
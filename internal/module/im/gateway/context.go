@@ -0,0 +1,11 @@
+package gateway
+
+import "context"
+
+type connIDKey struct{}
+
+// ConnIDFromContext 返回发起请求的连接 ID，HTTP 请求返回空字符串；用于多端同步时跳过发起方连接
+func ConnIDFromContext(ctx context.Context) string {
+	connID, _ := ctx.Value(connIDKey{}).(string)
+	return connID
+}
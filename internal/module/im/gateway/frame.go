@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"encoding/json"
+	"server/pkg/errorx"
+)
+
+// ProtocolVersion 当前协议版本，客户端发送的帧版本不一致时返回 ErrImProtocolVersion
+const ProtocolVersion = 1
+
+// FrameType 帧类型
+type FrameType string
+
+const (
+	FrameSend FrameType = "send" // 客户端请求，Event 为事件名，服务端以相同 Seq 的 ack 帧响应
+	FrameAck  FrameType = "ack"  // 服务端对 send 的响应；客户端对 push 的确认
+	FramePush FrameType = "push" // 服务端推送，Seq 为连接内递增的推送序号
+	FramePing FrameType = "ping" // 应用层心跳，浏览器无法发送 WebSocket ping 控制帧
+	FramePong FrameType = "pong"
+)
+
+// Frame 协议帧，示例：
+//
+//	{"v":1,"type":"send","seq":1,"event":"message.send","data":{...}}
+//	{"v":1,"type":"ack","seq":1,"data":{...}}
+//	{"v":1,"type":"ack","seq":1,"error":{"code":700003,"reason":"IM_EVENT_NOT_FOUND","msg":"不支持的事件"}}
+//	{"v":1,"type":"push","seq":12,"event":"message.new","data":{...}}
+type Frame struct {
+	V     int             `json:"v"`
+	Type  FrameType       `json:"type"`
+	Seq   uint64          `json:"seq,omitempty"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error *FrameError     `json:"error,omitempty"`
+}
+
+// FrameError ack 帧中的错误，与 HTTP 错误响应字段一致
+type FrameError struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Msg     string `json:"msg"`
+	Details any    `json:"details,omitempty"`
+}
+
+func newFrameError(err error, lang string) *FrameError {
+	bizErr := errorx.FromError(err)
+	return &FrameError{
+		Code:    bizErr.Code,
+		Reason:  bizErr.Reason,
+		Msg:     bizErr.Localize(lang),
+		Details: bizErr.Details,
+	}
+}
+
+func encodeFrame(frame *Frame, data any) ([]byte, error) {
+	frame.V = ProtocolVersion
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		frame.Data = raw
+	}
+	return json.Marshal(frame)
+}
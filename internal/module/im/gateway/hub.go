@@ -0,0 +1,418 @@
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"server/internal/core/config"
+	"server/internal/core/logger"
+	"server/pkg/errorx"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+type (
+	// Handler 处理客户端 send 帧，返回值作为 ack 帧的 data，返回错误时 ack 帧携带 error
+	Handler func(ctx context.Context, conn *Conn, data json.RawMessage) (any, error)
+
+	// AckHandler 处理客户端对推送的确认
+	AckHandler func(ctx context.Context, conn *Conn, seq uint64, data json.RawMessage)
+
+	// ConnHook 连接生命周期回调，在连接的读协程中同步执行
+	ConnHook func(conn *Conn)
+
+	// Hub 管理本实例的在线连接，按用户索引，支持同一用户多设备同时在线；
+	// 多实例部署时通过 Broker 把推送转发到用户连接所在的其他实例
+	Hub struct {
+		cfg        *config.Gateway
+		logger     logger.Logger
+		broker     Broker
+		instanceID string
+		startOnce  sync.Once
+
+		mu    sync.RWMutex
+		users map[uint]map[string]*Conn
+
+		handlerMu    sync.RWMutex
+		handlers     map[string]Handler
+		ackHandlers  map[string]AckHandler
+		onConnect    []ConnHook
+		onDisconnect []ConnHook
+		onHeartbeat  []ConnHook
+	}
+)
+
+func NewHub(cfg *config.Gateway, logger logger.Logger, broker Broker) *Hub {
+	return &Hub{
+		cfg:         cfg,
+		logger:      logger,
+		broker:      broker,
+		instanceID:  newInstanceID(),
+		users:       make(map[uint]map[string]*Conn),
+		handlers:    make(map[string]Handler),
+		ackHandlers: make(map[string]AckHandler),
+	}
+}
+
+// InitIfNeeded 订阅其他实例转发到本实例的推送，服务启动时执行
+func (h *Hub) InitIfNeeded() error {
+	h.startOnce.Do(func() {
+		go func() {
+			for {
+				err := h.broker.Subscribe(context.Background(), h.instanceID, h.deliver)
+				h.logger.Error("[Gateway] subscribe broker interrupted, retrying", zap.String("instanceId", h.instanceID), zap.Error(err))
+				time.Sleep(time.Second)
+			}
+		}()
+	})
+	return nil
+}
+
+// InstanceID 当前实例ID，进程启动时生成
+func (h *Hub) InstanceID() string {
+	return h.instanceID
+}
+
+// Handle 注册 send 帧的事件处理函数，各业务在初始化时注册
+func (h *Hub) Handle(event string, handler Handler) {
+	h.handlerMu.Lock()
+	defer h.handlerMu.Unlock()
+	h.handlers[event] = handler
+}
+
+// HandleAck 注册推送确认的处理函数，按推送事件名区分
+func (h *Hub) HandleAck(event string, handler AckHandler) {
+	h.handlerMu.Lock()
+	defer h.handlerMu.Unlock()
+	h.ackHandlers[event] = handler
+}
+
+// OnConnect 注册连接建立后的回调
+func (h *Hub) OnConnect(hook ConnHook) {
+	h.handlerMu.Lock()
+	defer h.handlerMu.Unlock()
+	h.onConnect = append(h.onConnect, hook)
+}
+
+// OnDisconnect 注册连接关闭后的回调
+func (h *Hub) OnDisconnect(hook ConnHook) {
+	h.handlerMu.Lock()
+	defer h.handlerMu.Unlock()
+	h.onDisconnect = append(h.onDisconnect, hook)
+}
+
+// OnHeartbeat 注册心跳回调，收到客户端 ping 帧或 WebSocket pong 时触发
+func (h *Hub) OnHeartbeat(hook ConnHook) {
+	h.handlerMu.Lock()
+	defer h.handlerMu.Unlock()
+	h.onHeartbeat = append(h.onHeartbeat, hook)
+}
+
+// Serve 在已升级的连接上提供服务，阻塞直到连接关闭
+func (h *Hub) Serve(ctx context.Context, ws *websocket.Conn, userID uint, device, lang string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	connID := newConnID()
+	conn := &Conn{
+		id:          connID,
+		userID:      userID,
+		device:      device,
+		lang:        lang,
+		connectedAt: time.Now(),
+		ctx:         context.WithValue(ctx, connIDKey{}, connID),
+		hub:         h,
+		ws:          ws,
+		cfg:         h.cfg,
+		logger:      h.logger,
+		send:        make(chan []byte, h.cfg.SendBuffer),
+		done:        make(chan struct{}),
+	}
+	h.register(conn)
+	defer h.unregister(conn)
+
+	go conn.writeLoop()
+	conn.readLoop()
+}
+
+// PushToUser 推送事件到用户的全部在线连接，返回本实例推送成功的连接数
+func (h *Hub) PushToUser(userID uint, event string, data any) int {
+	return h.pushToUser(userID, "", event, data)
+}
+
+// PushToUserExcept 推送事件到用户除 exceptConnID 之外的在线连接，用于多端同步发送方自己的操作
+func (h *Hub) PushToUserExcept(userID uint, exceptConnID, event string, data any) int {
+	return h.pushToUser(userID, exceptConnID, event, data)
+}
+
+// PushToUsers 推送事件到多个用户的全部在线连接，跨实例路由批量查询，用于群聊等一次推送给大量用户的场景，
+// 返回本实例推送成功的连接数
+func (h *Hub) PushToUsers(userIDs []uint, event string, data any) int {
+	if len(userIDs) == 0 {
+		return 0
+	}
+	count := 0
+	for _, userID := range userIDs {
+		count += h.pushLocal(userID, "", event, data)
+	}
+	h.forward(userIDs, "", event, data)
+	return count
+}
+
+func (h *Hub) pushToUser(userID uint, exceptConnID, event string, data any) int {
+	count := h.pushLocal(userID, exceptConnID, event, data)
+	h.forward([]uint{userID}, exceptConnID, event, data)
+	return count
+}
+
+func (h *Hub) pushLocal(userID uint, exceptConnID, event string, data any) int {
+	count := 0
+	for _, conn := range h.Conns(userID) {
+		if conn.id == exceptConnID {
+			continue
+		}
+		if err := conn.Push(event, data); err == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// forward 将推送转发到用户连接所在的其他实例，一次查询全部用户的路由，每个实例只发布一次；
+// 转发失败只记录日志，由客户端重连后同步
+func (h *Hub) forward(userIDs []uint, exceptConnID, event string, data any) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.cfg.WriteWait)*time.Second)
+	defer cancel()
+
+	routes, err := h.broker.Instances(ctx, userIDs)
+	if err != nil {
+		h.logger.Error("[Gateway] broker.Instances error", zap.Int("users", len(userIDs)), zap.Error(err))
+		return
+	}
+	// 按实例分组，保持用户顺序
+	byInstance := make(map[string][]uint)
+	var instanceIDs []string
+	for _, userID := range userIDs {
+		for _, instanceID := range routes[userID] {
+			if instanceID == h.instanceID {
+				continue
+			}
+			if _, ok := byInstance[instanceID]; !ok {
+				instanceIDs = append(instanceIDs, instanceID)
+			}
+			byInstance[instanceID] = append(byInstance[instanceID], userID)
+		}
+	}
+	if len(instanceIDs) == 0 {
+		return
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		h.logger.Error("[Gateway] marshal push data error", zap.String("event", event), zap.Error(err))
+		return
+	}
+	for _, instanceID := range instanceIDs {
+		envelope := &Envelope{UserIDs: byInstance[instanceID], ExceptConnID: exceptConnID, Event: event, Data: raw}
+		if err := h.broker.Publish(ctx, instanceID, envelope); err != nil {
+			h.logger.Error("[Gateway] broker.Publish error", zap.String("instanceId", instanceID), zap.Int("users", len(envelope.UserIDs)), zap.Error(err))
+		}
+	}
+}
+
+// deliver 推送其他实例转发过来的事件，只推送到本实例的连接，不再继续转发
+func (h *Hub) deliver(envelope *Envelope) {
+	for _, userID := range envelope.UserIDs {
+		h.pushLocal(userID, envelope.ExceptConnID, envelope.Event, envelope.Data)
+	}
+}
+
+// Conns 返回用户的全部在线连接，按建立时间排序
+func (h *Hub) Conns(userID uint) []*Conn {
+	h.mu.RLock()
+	conns := make([]*Conn, 0, len(h.users[userID]))
+	for _, conn := range h.users[userID] {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].connectedAt.Before(conns[j].connectedAt)
+	})
+	return conns
+}
+
+// Online 用户是否有在线连接
+func (h *Hub) Online(userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users[userID]) > 0
+}
+
+// Close 关闭全部连接，服务停止时调用
+func (h *Hub) Close() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, conns := range h.users {
+		for _, conn := range conns {
+			conn.Close(websocket.CloseGoingAway, "server shutdown")
+		}
+	}
+}
+
+func (h *Hub) register(conn *Conn) {
+	h.mu.Lock()
+	conns, ok := h.users[conn.userID]
+	if !ok {
+		conns = make(map[string]*Conn)
+		h.users[conn.userID] = conns
+	}
+	conns[conn.id] = conn
+
+	// 超过连接数上限时关闭最早建立的连接
+	var evicted []*Conn
+	if over := len(conns) - h.cfg.MaxConnsPerUser; over > 0 {
+		for _, c := range conns {
+			if c != conn {
+				evicted = append(evicted, c)
+			}
+		}
+		sort.Slice(evicted, func(i, j int) bool {
+			return evicted[i].connectedAt.Before(evicted[j].connectedAt)
+		})
+		evicted = evicted[:over]
+	}
+	h.mu.Unlock()
+
+	for _, c := range evicted {
+		c.Close(websocket.ClosePolicyViolation, "too many connections")
+	}
+	h.route(conn)
+	h.logger.WithContext(conn.ctx).Info("[Gateway] connected",
+		zap.Uint("userId", conn.userID), zap.String("connId", conn.id), zap.String("device", conn.device))
+	h.runHooks(conn, "connect", func() []ConnHook { return h.onConnect })
+}
+
+func (h *Hub) unregister(conn *Conn) {
+	h.mu.Lock()
+	last := false
+	if conns, ok := h.users[conn.userID]; ok {
+		delete(conns, conn.id)
+		if len(conns) == 0 {
+			delete(h.users, conn.userID)
+			last = true
+		}
+	}
+	h.mu.Unlock()
+
+	if last {
+		// 与同一用户新连接的 route 并发时可能误删路由，新连接的下一次心跳会重新写入
+		if err := h.broker.Unregister(conn.ctx, conn.userID, h.instanceID); err != nil {
+			h.logger.WithContext(conn.ctx).Error("[Gateway] broker.Unregister error", zap.Uint("userId", conn.userID), zap.Error(err))
+		}
+	}
+
+	h.logger.WithContext(conn.ctx).Info("[Gateway] disconnected",
+		zap.Uint("userId", conn.userID), zap.String("connId", conn.id), zap.Duration("duration", time.Since(conn.connectedAt)))
+	h.runHooks(conn, "disconnect", func() []ConnHook { return h.onDisconnect })
+}
+
+func (h *Hub) heartbeat(conn *Conn) {
+	h.route(conn)
+	h.runHooks(conn, "heartbeat", func() []ConnHook { return h.onHeartbeat })
+}
+
+// route 记录或续期用户在本实例的路由，有效期覆盖两个心跳周期
+func (h *Hub) route(conn *Conn) {
+	ttl := 2 * time.Duration(h.cfg.PongWait) * time.Second
+	if err := h.broker.Register(conn.ctx, conn.userID, h.instanceID, ttl); err != nil {
+		h.logger.WithContext(conn.ctx).Error("[Gateway] broker.Register error", zap.Uint("userId", conn.userID), zap.Error(err))
+	}
+}
+
+// runHooks 执行连接回调，回调 panic 时只记录日志，不影响连接
+func (h *Hub) runHooks(conn *Conn, name string, hooks func() []ConnHook) {
+	h.handlerMu.RLock()
+	list := hooks()
+	h.handlerMu.RUnlock()
+
+	for _, hook := range list {
+		func() {
+			defer func() {
+				if rec := recover(); rec != nil {
+					h.logger.WithContext(conn.ctx).Error("[Gateway] hook panic",
+						zap.String("hook", name), zap.Any("panic", rec), zap.Stack("stack"))
+				}
+			}()
+			hook(conn)
+		}()
+	}
+}
+
+func (h *Hub) dispatch(conn *Conn, frame *Frame) {
+	switch frame.Type {
+	case FramePing:
+		_ = conn.write(&Frame{Type: FramePong, Seq: frame.Seq}, nil)
+		h.heartbeat(conn)
+	case FramePong:
+	case FrameSend:
+		h.handlerMu.RLock()
+		handler, ok := h.handlers[frame.Event]
+		h.handlerMu.RUnlock()
+		if !ok {
+			_ = conn.ack(frame.Seq, nil, errorx.ErrImEventNotFound)
+			return
+		}
+		result, err := h.call(conn, frame, handler)
+		_ = conn.ack(frame.Seq, result, err)
+	case FrameAck:
+		h.handlerMu.RLock()
+		handler, ok := h.ackHandlers[frame.Event]
+		h.handlerMu.RUnlock()
+		if ok {
+			handler(conn.ctx, conn, frame.Seq, frame.Data)
+		}
+	default:
+		_ = conn.ack(frame.Seq, nil, errorx.ErrImFrameInvalid)
+	}
+}
+
+// call 执行事件处理函数，处理函数 panic 时只影响本次请求
+func (h *Hub) call(conn *Conn, frame *Frame, handler Handler) (result any, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			h.logger.WithContext(conn.ctx).Error("[Gateway] handler panic",
+				zap.String("event", frame.Event), zap.Any("panic", rec), zap.Stack("stack"))
+			result, err = nil, errorx.ErrInternal
+		}
+	}()
+	result, err = handler(conn.ctx, conn, frame.Data)
+	if err != nil {
+		if bizErr := errorx.FromError(err); bizErr.Status >= http.StatusInternalServerError {
+			h.logger.WithContext(conn.ctx).Error("[Gateway] handler error", zap.String("event", frame.Event), zap.Error(err))
+		}
+	}
+	return result, err
+}
+
+// newInstanceID 主机名加随机后缀，同一主机上的多个进程互不冲突
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "im"
+	}
+	return hostname + "-" + newConnID()
+}
+
+func newConnID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,8 @@
+package gateway
+
+import "github.com/google/wire"
+
+var ProviderSet = wire.NewSet(
+	NewHub,
+	NewBroker,
+)
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Attachment 图片、文件、语音消息的附件，文件保存在对象存储中，下载地址按需签名生成
+type Attachment struct {
+	ID         uint64    `gorm:"primarykey" json:"id"`
+	UserID     uint64    `gorm:"not null;index;comment:上传者用户ID" json:"userId"`
+	Kind       string    `gorm:"type:varchar(16);not null;comment:附件类型（image、file、voice）" json:"kind"`
+	StorageKey string    `gorm:"type:varchar(255);not null;comment:存储路径" json:"-"`
+	ThumbKey   string    `gorm:"type:varchar(255);not null;default:'';comment:缩略图存储路径，为空表示没有缩略图" json:"-"`
+	Name       string    `gorm:"type:varchar(255);not null;comment:原始文件名" json:"name"`
+	Size       int64     `gorm:"not null;comment:文件大小（字节）" json:"size"`
+	MimeType   string    `gorm:"type:varchar(128);not null;comment:按文件内容识别的 MIME 类型" json:"mimeType"`
+	Width      int       `gorm:"not null;default:0;comment:图片宽度" json:"width,omitempty"`
+	Height     int       `gorm:"not null;default:0;comment:图片高度" json:"height,omitempty"`
+	Duration   int       `gorm:"not null;default:0;comment:语音时长（秒）" json:"duration,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (m *Attachment) TableName() string {
+	return "im_attachment"
+}
+
+// AttachmentContent 图片、文件、语音消息的内容，客户端发送时只需填写 attachmentId，服务端补全附件信息
+type AttachmentContent struct {
+	AttachmentID uint64 `json:"attachmentId"`
+	Name         string `json:"name"`
+	Size         int64  `json:"size"`
+	MimeType     string `json:"mimeType"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	Duration     int    `json:"duration,omitempty"`
+	Thumbnail    bool   `json:"thumbnail"` // 是否有缩略图，没有时客户端直接使用原图
+}
+
+func NewAttachmentContent(attachment *Attachment) *AttachmentContent {
+	return &AttachmentContent{
+		AttachmentID: attachment.ID,
+		Name:         attachment.Name,
+		Size:         attachment.Size,
+		MimeType:     attachment.MimeType,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+		Duration:     attachment.Duration,
+		Thumbnail:    attachment.ThumbKey != "",
+	}
+}
+
+// IsAttachmentType 消息类型是否携带附件，附件类型与消息类型同名
+func IsAttachmentType(msgType string) bool {
+	return msgType == MessageTypeImage || msgType == MessageTypeFile || msgType == MessageTypeVoice
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+const (
+	FriendRequestPending  = 1 // 待处理
+	FriendRequestAccepted = 2 // 已同意
+	FriendRequestRejected = 3 // 已拒绝
+)
+
+// FriendRequest 好友申请，同一对用户之间最多存在一条待处理的申请，重复申请时更新附言
+type FriendRequest struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	FromID    uint64    `gorm:"not null;index:idx_from_to,priority:1;comment:申请人用户ID" json:"fromId"`
+	ToID      uint64    `gorm:"not null;index:idx_from_to,priority:2;index:idx_to_status,priority:1;comment:被申请人用户ID" json:"toId"`
+	Message   string    `gorm:"type:varchar(255);not null;default:'';comment:附言" json:"message"`
+	Status    int8      `gorm:"type:tinyint;not null;default:1;index:idx_to_status,priority:2;comment:状态（1待处理 2已同意 3已拒绝）" json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (m *FriendRequest) TableName() string {
+	return "im_friend_request"
+}
+
+// Friend 好友关系，双方各保存一条记录，备注和分组只对 UserID 自己可见
+type Friend struct {
+	UserID       uint64    `gorm:"primaryKey;not null;comment:用户ID" json:"userId"`
+	FriendID     uint64    `gorm:"primaryKey;not null;comment:好友用户ID" json:"friendId"`
+	Remark       string    `gorm:"type:varchar(64);not null;default:'';comment:备注名" json:"remark"`
+	ContactGroup string    `gorm:"type:varchar(32);not null;default:'';comment:联系人分组" json:"contactGroup"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (m *Friend) TableName() string {
+	return "im_friend"
+}
+
+// Block 黑名单，UserID 拒收 BlockedID 的消息和好友申请
+type Block struct {
+	UserID    uint64    `gorm:"primaryKey;not null;comment:用户ID" json:"userId"`
+	BlockedID uint64    `gorm:"primaryKey;not null;comment:被拉黑的用户ID" json:"blockedId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (m *Block) TableName() string {
+	return "im_block"
+}
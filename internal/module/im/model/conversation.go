@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ConversationTypeSingle = 1 // 单聊
+	ConversationTypeGroup  = 2 // 群聊
+)
+
+// Conversation 会话，LastSeq 为会话内最新消息序号，发送消息时加行锁递增
+type Conversation struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	Type      int8      `gorm:"type:tinyint;not null;comment:会话类型（1单聊 2群聊）" json:"type"`
+	Key       string    `gorm:"type:varchar(64);not null;uniqueIndex;comment:会话唯一标识" json:"-"`
+	LastSeq   uint64    `gorm:"not null;default:0;comment:最新消息序号" json:"lastSeq"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (m *Conversation) TableName() string {
+	return "im_conversation"
+}
+
+// SingleConversationKey 单聊会话标识，与双方顺序无关
+func SingleConversationKey(a, b uint64) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("single:%d:%d", a, b)
+}
+
+// ConversationMember 会话成员及其已送达、已读位置
+type ConversationMember struct {
+	ConversationID uint64    `gorm:"primaryKey;not null;comment:会话ID" json:"conversationId"`
+	UserID         uint64    `gorm:"primaryKey;not null;index;comment:用户ID" json:"userId"`
+	PeerID         uint64    `gorm:"not null;default:0;comment:单聊对方用户ID" json:"peerId"`
+	StartSeq       uint64    `gorm:"not null;default:0;comment:加入时的消息序号，只能查看之后的消息" json:"startSeq"`
+	DeliveredSeq   uint64    `gorm:"not null;default:0;comment:已送达消息序号" json:"deliveredSeq"`
+	ReadSeq        uint64    `gorm:"not null;default:0;comment:已读消息序号" json:"readSeq"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func (m *ConversationMember) TableName() string {
+	return "im_conversation_member"
+}
+
+// UserConversation 用户视角的会话，UpdatedAt 取会话与成员记录中较晚的更新时间，用于增量同步
+type UserConversation struct {
+	ConversationID uint64    `json:"conversationId"`
+	Type           int8      `json:"type"`
+	PeerID         uint64    `json:"peerId"`
+	StartSeq       uint64    `json:"startSeq"`
+	LastSeq        uint64    `json:"lastSeq"`
+	DeliveredSeq   uint64    `json:"deliveredSeq"`
+	ReadSeq        uint64    `json:"readSeq"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
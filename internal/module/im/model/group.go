@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+const (
+	GroupRoleOwner  = 1 // 群主
+	GroupRoleAdmin  = 2 // 管理员
+	GroupRoleMember = 3 // 普通成员
+)
+
+const (
+	GroupJoinFree   = 1 // 任何人可直接加入
+	GroupJoinInvite = 2 // 仅允许群成员邀请加入
+)
+
+// Group 群组，ID 与群聊会话ID相同，MemberCount 在群组行锁内维护，用于成员数上限校验
+type Group struct {
+	ID           uint64    `gorm:"primarykey;autoIncrement:false;comment:群组ID（与会话ID相同）" json:"id"`
+	Name         string    `gorm:"type:varchar(64);not null;comment:群名称" json:"name"`
+	Avatar       string    `gorm:"type:varchar(255);not null;default:'';comment:群头像" json:"avatar"`
+	OwnerID      uint64    `gorm:"not null;index;comment:群主用户ID" json:"ownerId"`
+	Announcement string    `gorm:"type:text;comment:群公告" json:"announcement"`
+	JoinMode     int8      `gorm:"type:tinyint;not null;default:1;comment:加群方式（1直接加入 2仅邀请）" json:"joinMode"`
+	MaxMembers   int       `gorm:"not null;comment:成员数上限" json:"maxMembers"`
+	MemberCount  int       `gorm:"not null;default:0;comment:成员数" json:"memberCount"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (m *Group) TableName() string {
+	return "im_group"
+}
+
+// GroupMember 群成员，Role 越小权限越高，MutedUntil 非空且晚于当前时间时禁止发言
+type GroupMember struct {
+	GroupID    uint64     `gorm:"primaryKey;not null;comment:群组ID" json:"groupId"`
+	UserID     uint64     `gorm:"primaryKey;not null;index;comment:用户ID" json:"userId"`
+	Role       int8       `gorm:"type:tinyint;not null;default:3;comment:群内角色（1群主 2管理员 3成员）" json:"role"`
+	InviterID  uint64     `gorm:"not null;default:0;comment:邀请人用户ID，主动加入为0" json:"inviterId"`
+	MutedUntil *time.Time `gorm:"comment:禁言截止时间" json:"mutedUntil"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+func (m *GroupMember) TableName() string {
+	return "im_group_member"
+}
+
+// Muted 当前是否处于禁言中
+func (m *GroupMember) Muted(now time.Time) bool {
+	return m.MutedUntil != nil && m.MutedUntil.After(now)
+}
+
+// Outranks 是否有权管理目标成员，只能管理角色低于自己的成员
+func (m *GroupMember) Outranks(target *GroupMember) bool {
+	return m.Role < target.Role
+}
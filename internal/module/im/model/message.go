@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	MessageTypeText   = "text"
+	MessageTypeImage  = "image"
+	MessageTypeFile   = "file"
+	MessageTypeVoice  = "voice"
+	MessageTypeCustom = "custom"
+)
+
+// Message 消息，Seq 在会话内单调递增且连续，(SenderID, ClientMsgID) 唯一用于客户端重发去重；
+// 撤回、编辑和表情回应会更新 UpdatedAt，客户端据此增量同步已拉取消息的变化
+type Message struct {
+	ID             uint64     `gorm:"primarykey" json:"id"`
+	ConversationID uint64     `gorm:"not null;uniqueIndex:uk_conversation_seq,priority:1;index:idx_conversation_updated,priority:1;comment:会话ID" json:"conversationId"`
+	Seq            uint64     `gorm:"not null;uniqueIndex:uk_conversation_seq,priority:2;comment:会话内消息序号" json:"seq"`
+	SenderID       uint64     `gorm:"not null;uniqueIndex:uk_sender_client_msg,priority:1;comment:发送方用户ID" json:"senderId"`
+	ClientMsgID    string     `gorm:"type:varchar(64);not null;uniqueIndex:uk_sender_client_msg,priority:2;comment:客户端消息ID" json:"clientMsgId"`
+	Type           string     `gorm:"type:varchar(16);not null;comment:消息类型" json:"type"`
+	Content        string     `gorm:"type:text;not null;comment:消息内容，非文本消息为 JSON，图片、文件、语音消息为 AttachmentContent，撤回后清空" json:"content"`
+	ReplyToSeq     uint64     `gorm:"not null;default:0;comment:引用回复的消息序号" json:"replyToSeq"`
+	RecalledAt     *time.Time `gorm:"comment:撤回时间" json:"recalledAt"`
+	EditedAt       *time.Time `gorm:"comment:最后编辑时间" json:"editedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `gorm:"index:idx_conversation_updated,priority:2" json:"updatedAt"`
+}
+
+func (m *Message) TableName() string {
+	return "im_message"
+}
+
+// Recalled 消息是否已撤回
+func (m *Message) Recalled() bool {
+	return m.RecalledAt != nil
+}
+
+// MessageEdit 消息编辑历史，保存每次编辑前的内容
+type MessageEdit struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	MessageID uint64    `gorm:"not null;index;comment:消息ID" json:"messageId"`
+	Content   string    `gorm:"type:text;not null;comment:编辑前的内容" json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (m *MessageEdit) TableName() string {
+	return "im_message_edit"
+}
+
+// MessageReaction 表情回应，同一用户对同一消息的每种表情只记录一次
+type MessageReaction struct {
+	MessageID uint64    `gorm:"primaryKey;not null;comment:消息ID" json:"messageId"`
+	UserID    uint64    `gorm:"primaryKey;not null;comment:用户ID" json:"userId"`
+	Emoji     string    `gorm:"primaryKey;type:varchar(32) collate utf8mb4_bin;not null;comment:表情（按二进制比较，避免不同 emoji 被排序规则视为相同）" json:"emoji"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (m *MessageReaction) TableName() string {
+	return "im_message_reaction"
+}
@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// 审核动作，按严重程度升序排列，多个敏感词命中时取最严重的动作
+const (
+	ModerationActionPass   = "pass"   // 放行
+	ModerationActionMask   = "mask"   // 敏感词替换为 * 后发送
+	ModerationActionReview = "review" // 原样发送，同时进入人工审核队列
+	ModerationActionBlock  = "block"  // 拒绝发送
+)
+
+const (
+	ReviewPending  = 1 // 待审核
+	ReviewApproved = 2 // 审核通过
+	ReviewRejected = 3 // 审核不通过，消息已撤回
+)
+
+// ModerationSeverity 审核动作的严重程度，未知动作视为放行
+func ModerationSeverity(action string) int {
+	switch action {
+	case ModerationActionMask:
+		return 1
+	case ModerationActionReview:
+		return 2
+	case ModerationActionBlock:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// SensitiveWord 全局敏感词，Word 为归一化后的形式（小写、全角转半角、去除空白和标点）
+type SensitiveWord struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	Word      string    `gorm:"type:varchar(64) collate utf8mb4_bin;not null;uniqueIndex;comment:敏感词（归一化后）" json:"word"`
+	Category  string    `gorm:"type:varchar(32);not null;default:'';index;comment:分类，比如 politics、ads、abuse" json:"category"`
+	Action    string    `gorm:"type:varchar(16);not null;comment:命中后的动作（mask、review、block）" json:"action"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `gorm:"index" json:"updatedAt"`
+}
+
+func (m *SensitiveWord) TableName() string {
+	return "im_sensitive_word"
+}
+
+// GroupModeration 群审核设置，由群主或管理员维护；全局敏感词对所有群生效，群设置只能在此基础上加严
+type GroupModeration struct {
+	GroupID   uint64    `gorm:"primaryKey;autoIncrement:false;comment:群组ID" json:"groupId"`
+	Words     []string  `gorm:"type:json;serializer:json;comment:群自定义敏感词（归一化后）" json:"words"`
+	Action    string    `gorm:"type:varchar(16);not null;comment:群自定义敏感词命中后的动作（mask、review、block）" json:"action"`
+	Strict    bool      `gorm:"not null;default:false;comment:严格模式，全局敏感词中需替换的词直接拒绝发送" json:"strict"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (m *GroupModeration) TableName() string {
+	return "im_group_moderation"
+}
+
+// ModerationReview 人工审核队列，命中 review 动作的消息照常发送，审核不通过时撤回
+type ModerationReview struct {
+	ID             uint64     `gorm:"primarykey" json:"id"`
+	ConversationID uint64     `gorm:"not null;index:idx_conversation_seq,priority:1;comment:会话ID" json:"conversationId"`
+	Seq            uint64     `gorm:"not null;index:idx_conversation_seq,priority:2;comment:消息序号" json:"seq"`
+	GroupID        uint64     `gorm:"not null;default:0;comment:群组ID，单聊为0" json:"groupId"`
+	SenderID       uint64     `gorm:"not null;index;comment:发送者用户ID" json:"senderId"`
+	Content        string     `gorm:"type:text;comment:送审时的消息内容" json:"content"`
+	Words          []string   `gorm:"type:json;serializer:json;comment:命中的敏感词" json:"words"`
+	Status         int8       `gorm:"type:tinyint;not null;default:1;index:idx_status_id,priority:1;comment:状态（1待审核 2通过 3不通过）" json:"status"`
+	ReviewerID     uint64     `gorm:"not null;default:0;comment:审核人（系统用户ID）" json:"reviewerId"`
+	ReviewedAt     *time.Time `gorm:"comment:审核时间" json:"reviewedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func (m *ModerationReview) TableName() string {
+	return "im_moderation_review"
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+const (
+	PresenceOnline  = "online"  // 在线
+	PresenceAway    = "away"    // 离开，由客户端设置
+	PresenceOffline = "offline" // 离线
+)
+
+// Presence 用户在线状态，只保存在 Redis 或进程内存中，不落库
+type Presence struct {
+	UserID   uint64     `json:"userId"`
+	Status   string     `json:"status"`
+	LastSeen *time.Time `json:"lastSeen,omitempty"` // 最后在线时间，从未上线时为空
+}
@@ -0,0 +1,11 @@
+package reply
+
+import "server/internal/module/im/model"
+
+type AttachmentReply struct {
+	*model.AttachmentContent
+	Kind         string `json:"kind"`
+	URL          string `json:"url"`                    // 原文件下载地址
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"` // 缩略图下载地址，没有缩略图时为空
+	ExpiresIn    int    `json:"expiresIn"`              // 下载地址有效期（秒）
+}
@@ -0,0 +1,46 @@
+package reply
+
+import (
+	"server/internal/module/im/model"
+	systemModel "server/internal/module/system/model"
+)
+
+// UserProfileReply 对其他用户公开的资料，不包含手机号、邮箱等隐私字段
+type UserProfileReply struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	Gender   int64  `json:"gender"`
+}
+
+func NewUserProfileReply(user *systemModel.User) *UserProfileReply {
+	return &UserProfileReply{
+		ID:       user.ID,
+		Username: user.Username,
+		Nickname: user.Nickname,
+		Avatar:   user.Avatar,
+		Gender:   user.Gender,
+	}
+}
+
+type SearchUserReply struct {
+	*UserProfileReply
+	IsFriend bool `json:"isFriend"`
+}
+
+// FriendRequestReply 好友申请，User 为申请的另一方
+type FriendRequestReply struct {
+	*model.FriendRequest
+	User *UserProfileReply `json:"user"`
+}
+
+type FriendReply struct {
+	*model.Friend
+	User *UserProfileReply `json:"user"`
+}
+
+type BlockReply struct {
+	*model.Block
+	User *UserProfileReply `json:"user"`
+}
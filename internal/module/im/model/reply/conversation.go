@@ -0,0 +1,41 @@
+package reply
+
+import "server/internal/module/im/model"
+
+type ConversationReply struct {
+	ConversationId uint64        `json:"conversationId"`
+	Type           int8          `json:"type"`
+	PeerId         uint64        `json:"peerId,omitempty"` // 单聊对方用户ID，群聊时会话ID即群组ID
+	LastSeq        uint64        `json:"lastSeq"`
+	DeliveredSeq   uint64        `json:"deliveredSeq"`
+	ReadSeq        uint64        `json:"readSeq"`
+	Unread         uint64        `json:"unread"`
+	LastMessage    *MessageReply `json:"lastMessage"`
+	UpdatedAt      int64         `json:"updatedAt"` // 毫秒时间戳，作为下一次增量同步的 updatedAfter
+}
+
+// ConversationListReply 会话列表，按更新时间、会话ID升序排列，HasMore 为 true 时以最后一条的 UpdatedAt、ConversationId
+// 作为 updatedAfter、afterConversationId 继续拉取
+type ConversationListReply struct {
+	List    []*ConversationReply `json:"list"`
+	HasMore bool                 `json:"hasMore"`
+}
+
+func NewConversationReply(conversation *model.UserConversation, lastMessage *model.Message) *ConversationReply {
+	result := &ConversationReply{
+		ConversationId: conversation.ConversationID,
+		Type:           conversation.Type,
+		PeerId:         conversation.PeerID,
+		LastSeq:        conversation.LastSeq,
+		DeliveredSeq:   conversation.DeliveredSeq,
+		ReadSeq:        conversation.ReadSeq,
+		UpdatedAt:      conversation.UpdatedAt.UnixMilli(),
+	}
+	if conversation.LastSeq > conversation.ReadSeq {
+		result.Unread = conversation.LastSeq - conversation.ReadSeq
+	}
+	if lastMessage != nil {
+		result.LastMessage = NewMessageReply(&model.Conversation{ID: conversation.ConversationID, Type: conversation.Type}, lastMessage)
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package reply
+
+import (
+	"server/internal/module/im/model"
+	"time"
+)
+
+const (
+	GroupNoticeCreated      = "created"      // 创建群组
+	GroupNoticeInvited      = "invited"      // 成员被邀请加入
+	GroupNoticeJoined       = "joined"       // 成员主动加入
+	GroupNoticeLeft         = "left"         // 成员退出
+	GroupNoticeKicked       = "kicked"       // 成员被移出
+	GroupNoticeUpdated      = "updated"      // 群资料变更
+	GroupNoticeAnnouncement = "announcement" // 群公告变更
+	GroupNoticeRole         = "role"         // 成员角色变更
+	GroupNoticeTransferred  = "transferred"  // 群主转让
+	GroupNoticeMuted        = "muted"        // 成员禁言或解除禁言
+)
+
+type GroupDetailReply struct {
+	*model.Group
+	Role int8 `json:"role"` // 当前用户在群内的角色，非成员为 0
+}
+
+// GroupNoticeReply 群组变更通知，推送给群内全部在线成员，被移出或退出的成员也会收到
+type GroupNoticeReply struct {
+	GroupId    uint64       `json:"groupId"`
+	Type       string       `json:"type"`
+	OperatorId uint64       `json:"operatorId"`
+	UserIds    []uint64     `json:"userIds,omitempty"`
+	Group      *model.Group `json:"group,omitempty"`
+	Role       int8         `json:"role,omitempty"`
+	MutedUntil *time.Time   `json:"mutedUntil,omitempty"`
+}
@@ -0,0 +1,104 @@
+package reply
+
+import (
+	"server/internal/module/im/model"
+	"time"
+)
+
+const (
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+)
+
+type MessageReply struct {
+	ID               uint64           `json:"id"`
+	ConversationId   uint64           `json:"conversationId"`
+	ConversationType int8             `json:"conversationType"`
+	Seq              uint64           `json:"seq"`
+	SenderId         uint64           `json:"senderId"`
+	ClientMsgId      string           `json:"clientMsgId"`
+	Type             string           `json:"type"`
+	Content          string           `json:"content"` // 已撤回的消息为空
+	ReplyToSeq       uint64           `json:"replyToSeq,omitempty"`
+	Recalled         bool             `json:"recalled"`
+	EditedAt         *time.Time       `json:"editedAt,omitempty"`
+	Reactions        []*ReactionReply `json:"reactions,omitempty"`
+	CreatedAt        time.Time        `json:"createdAt"`
+	UpdatedAt        int64            `json:"updatedAt"` // 毫秒时间戳，作为下一次同步变化的 changedAfter
+}
+
+func NewMessageReply(conversation *model.Conversation, message *model.Message) *MessageReply {
+	result := &MessageReply{
+		ID:               message.ID,
+		ConversationId:   message.ConversationID,
+		ConversationType: conversation.Type,
+		Seq:              message.Seq,
+		SenderId:         message.SenderID,
+		ClientMsgId:      message.ClientMsgID,
+		Type:             message.Type,
+		Content:          message.Content,
+		ReplyToSeq:       message.ReplyToSeq,
+		Recalled:         message.Recalled(),
+		EditedAt:         message.EditedAt,
+		CreatedAt:        message.CreatedAt,
+		UpdatedAt:        message.UpdatedAt.UnixMilli(),
+	}
+	if result.Recalled {
+		result.Content = ""
+		result.EditedAt = nil
+	}
+	return result
+}
+
+// ReactionReply 按表情聚合的回应，按首次回应时间排序
+type ReactionReply struct {
+	Emoji   string   `json:"emoji"`
+	Count   int      `json:"count"`
+	UserIds []uint64 `json:"userIds"`
+}
+
+// NewReactionReplies 按消息ID聚合表情回应，reactions 需按添加时间升序排列
+func NewReactionReplies(reactions []*model.MessageReaction) map[uint64][]*ReactionReply {
+	result := make(map[uint64][]*ReactionReply)
+	index := make(map[uint64]map[string]*ReactionReply)
+	for _, reaction := range reactions {
+		emojis, ok := index[reaction.MessageID]
+		if !ok {
+			emojis = make(map[string]*ReactionReply)
+			index[reaction.MessageID] = emojis
+		}
+		item, ok := emojis[reaction.Emoji]
+		if !ok {
+			item = &ReactionReply{Emoji: reaction.Emoji}
+			emojis[reaction.Emoji] = item
+			result[reaction.MessageID] = append(result[reaction.MessageID], item)
+		}
+		item.Count++
+		item.UserIds = append(item.UserIds, reaction.UserID)
+	}
+	return result
+}
+
+// ReceiptReply 送达、已读回执，推送给会话中的其他成员
+type ReceiptReply struct {
+	ConversationId uint64 `json:"conversationId"`
+	UserId         uint64 `json:"userId"`
+	Type           string `json:"type"`
+	Seq            uint64 `json:"seq"`
+}
+
+// MessageListReply 消息列表，按序号升序排列；Changes 为已拉取消息的变化，按更新时间、序号升序排列，
+// ChangesHasMore 为 true 时以 ChangesCursor 继续拉取
+type MessageListReply struct {
+	List           []*MessageReply `json:"list"`
+	HasMore        bool            `json:"hasMore"`
+	Changes        []*MessageReply `json:"changes,omitempty"`
+	ChangesHasMore bool            `json:"changesHasMore,omitempty"`
+	ChangesCursor  *ChangesCursor  `json:"changesCursor,omitempty"`
+}
+
+// ChangesCursor 变化的分页游标，即最后一条变化的更新时间和序号，原样作为下一次同步的 changedAfter、changedAfterSeq
+type ChangesCursor struct {
+	ChangedAfter    int64  `json:"changedAfter"`
+	ChangedAfterSeq uint64 `json:"changedAfterSeq"`
+}
@@ -0,0 +1,6 @@
+package reply
+
+type CreateSensitiveWordReply struct {
+	Created int `json:"created"` // 新增的敏感词数量
+	Skipped int `json:"skipped"` // 已存在而跳过的敏感词数量
+}
@@ -0,0 +1,8 @@
+package reply
+
+// TypingReply 输入状态，推送给会话中的其他成员
+type TypingReply struct {
+	ConversationId uint64 `json:"conversationId"`
+	UserId         uint64 `json:"userId"`
+	Typing         bool   `json:"typing"`
+}
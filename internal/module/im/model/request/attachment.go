@@ -0,0 +1,9 @@
+package request
+
+import "mime/multipart"
+
+type UploadAttachmentReq struct {
+	Kind     string                `form:"kind" validate:"required,oneof=image file voice"` // 附件类型，与发送消息时的消息类型一致
+	Duration int                   `form:"duration" validate:"min=0,max=3600"`              // 语音时长（秒）
+	File     *multipart.FileHeader `form:"file" validate:"required" swaggerignore:"true"`   // 上传的文件
+}
@@ -0,0 +1,28 @@
+package request
+
+type SearchUserReq struct {
+	Keyword string `json:"keyword" form:"keyword" validate:"required,max=64"` // 用户名（精确匹配）或昵称（前缀匹配）
+}
+
+type FriendRequestReq struct {
+	UserId  uint64 `json:"userId" validate:"required"` // 被申请人用户ID
+	Message string `json:"message" validate:"max=255"` // 附言
+}
+
+type ListFriendRequestReq struct {
+	Type string `json:"type" form:"type" validate:"omitempty,oneof=received sent"` // received 收到的申请（默认），sent 发出的申请
+}
+
+type HandleFriendRequestReq struct {
+	RequestId uint64 `json:"requestId" validate:"required"` // 好友申请ID
+}
+
+type UpdateFriendReq struct {
+	UserId       uint64 `json:"userId" validate:"required"`     // 好友用户ID
+	Remark       string `json:"remark" validate:"max=64"`       // 备注名，为空表示清除
+	ContactGroup string `json:"contactGroup" validate:"max=32"` // 联系人分组，为空表示未分组
+}
+
+type ContactUserReq struct {
+	UserId uint64 `json:"userId" uri:"userId" validate:"required"` // 目标用户ID
+}
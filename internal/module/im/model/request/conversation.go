@@ -0,0 +1,7 @@
+package request
+
+type ListConversationReq struct {
+	UpdatedAfter        int64  `json:"updatedAfter" form:"updatedAfter" validate:"min=0"`     // 只返回该时间（毫秒时间戳）及之后有变化的会话，为 0 时返回全部
+	AfterConversationId uint64 `json:"afterConversationId" form:"afterConversationId"`        // 与 updatedAfter 组成分页游标，跳过更新时间等于 updatedAfter 且会话ID不大于该值的会话
+	Limit               int    `json:"limit" form:"limit" validate:"omitempty,min=1,max=500"` // 返回条数，默认 100
+}
@@ -0,0 +1,55 @@
+package request
+
+type CreateGroupReq struct {
+	Name       string   `json:"name" validate:"required,max=64"`            // 群名称
+	Avatar     string   `json:"avatar" validate:"max=255"`                  // 群头像
+	JoinMode   int8     `json:"joinMode" validate:"omitempty,oneof=1 2"`    // 加群方式（1直接加入 2仅邀请），默认直接加入
+	MaxMembers int      `json:"maxMembers" validate:"omitempty,min=2"`      // 成员数上限，默认使用配置 im.group.default_max_members
+	MemberIds  []uint64 `json:"memberIds" validate:"max=100,dive,required"` // 创建时拉入的成员
+}
+
+type UpdateGroupReq struct {
+	GroupId  uint64 `json:"groupId" validate:"required"`            // 群组ID
+	Name     string `json:"name" validate:"required,max=64"`        // 群名称
+	Avatar   string `json:"avatar" validate:"max=255"`              // 群头像
+	JoinMode int8   `json:"joinMode" validate:"required,oneof=1 2"` // 加群方式（1直接加入 2仅邀请）
+}
+
+type UpdateGroupAnnouncementReq struct {
+	GroupId      uint64 `json:"groupId" validate:"required"`      // 群组ID
+	Announcement string `json:"announcement" validate:"max=2048"` // 群公告，为空表示清除
+}
+
+type GroupIdReq struct {
+	GroupId uint64 `json:"groupId" form:"groupId" validate:"required"` // 群组ID
+}
+
+type InviteGroupMemberReq struct {
+	GroupId uint64   `json:"groupId" validate:"required"`                             // 群组ID
+	UserIds []uint64 `json:"userIds" validate:"required,min=1,max=100,dive,required"` // 被邀请的用户
+}
+
+type GroupMemberReq struct {
+	GroupId uint64 `json:"groupId" validate:"required"` // 群组ID
+	UserId  uint64 `json:"userId" validate:"required"`  // 目标成员
+}
+
+type SetGroupRoleReq struct {
+	GroupId uint64 `json:"groupId" validate:"required"`        // 群组ID
+	UserId  uint64 `json:"userId" validate:"required"`         // 目标成员
+	Role    int8   `json:"role" validate:"required,oneof=2 3"` // 角色（2管理员 3成员）
+}
+
+type MuteGroupMemberReq struct {
+	GroupId  uint64 `json:"groupId" validate:"required"`           // 群组ID
+	UserId   uint64 `json:"userId" validate:"required"`            // 目标成员
+	Duration int64  `json:"duration" validate:"min=0,max=2592000"` // 禁言时长（秒），0 表示解除禁言，最长 30 天
+}
+
+type SendGroupMessageReq struct {
+	GroupId     uint64 `json:"groupId" validate:"required"`                                 // 群组ID
+	ClientMsgId string `json:"clientMsgId" validate:"required,max=64"`                      // 客户端生成的消息ID，重发时保持不变
+	Type        string `json:"type" validate:"required,oneof=text image file voice custom"` // 消息类型
+	Content     string `json:"content" validate:"required,max=8192"`                        // 消息内容，非文本消息为 JSON，图片、文件、语音消息为 {"attachmentId":1}
+	ReplyToSeq  uint64 `json:"replyToSeq"`                                                  // 引用回复的消息序号，不回复时为 0
+}
@@ -0,0 +1,45 @@
+package request
+
+type SendMessageReq struct {
+	PeerId      uint64 `json:"peerId" validate:"required"`                                  // 接收方用户ID
+	ClientMsgId string `json:"clientMsgId" validate:"required,max=64"`                      // 客户端生成的消息ID，重发时保持不变
+	Type        string `json:"type" validate:"required,oneof=text image file voice custom"` // 消息类型
+	Content     string `json:"content" validate:"required,max=8192"`                        // 消息内容，非文本消息为 JSON，图片、文件、语音消息为 {"attachmentId":1}
+	ReplyToSeq  uint64 `json:"replyToSeq"`                                                  // 引用回复的消息序号，不回复时为 0
+}
+
+type MessageAckReq struct {
+	ConversationId uint64 `json:"conversationId" validate:"required"` // 会话ID
+	Seq            uint64 `json:"seq" validate:"required"`            // 已送达或已读到的消息序号
+}
+
+type SyncMessageReq struct {
+	ConversationId  uint64 `json:"conversationId" form:"conversationId" validate:"required"` // 会话ID
+	AfterSeq        uint64 `json:"afterSeq" form:"afterSeq"`                                 // 客户端已有的最新消息序号，返回之后的消息
+	ChangedAfter    int64  `json:"changedAfter" form:"changedAfter"`                         // 毫秒时间戳，大于 0 时同时返回 afterSeq 及之前的消息在此时间及之后的撤回、编辑和表情回应变化
+	ChangedAfterSeq uint64 `json:"changedAfterSeq" form:"changedAfterSeq"`                   // 与 changedAfter 组成变化的分页游标，跳过更新时间等于 changedAfter 且序号不大于该值的消息
+	Limit           int    `json:"limit" form:"limit" validate:"omitempty,min=1,max=500"`    // 返回条数，默认 100
+}
+
+type MessageHistoryReq struct {
+	ConversationId uint64 `json:"conversationId" form:"conversationId" validate:"required"` // 会话ID
+	BeforeSeq      uint64 `json:"beforeSeq" form:"beforeSeq"`                               // 返回该序号之前的消息，为 0 时从最新消息开始
+	Limit          int    `json:"limit" form:"limit" validate:"omitempty,min=1,max=100"`    // 返回条数，默认 20
+}
+
+type MessageTargetReq struct {
+	ConversationId uint64 `json:"conversationId" form:"conversationId" validate:"required"` // 会话ID
+	Seq            uint64 `json:"seq" form:"seq" validate:"required"`                       // 消息序号
+}
+
+type EditMessageReq struct {
+	ConversationId uint64 `json:"conversationId" validate:"required"`   // 会话ID
+	Seq            uint64 `json:"seq" validate:"required"`              // 消息序号
+	Content        string `json:"content" validate:"required,max=8192"` // 新的消息内容
+}
+
+type MessageReactionReq struct {
+	ConversationId uint64 `json:"conversationId" validate:"required"` // 会话ID
+	Seq            uint64 `json:"seq" validate:"required"`            // 消息序号
+	Emoji          string `json:"emoji" validate:"required,max=32"`   // 表情
+}
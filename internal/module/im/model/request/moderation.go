@@ -0,0 +1,43 @@
+package request
+
+import systemRequest "server/internal/module/system/model/request"
+
+type SensitiveWordListReq struct {
+	systemRequest.PageInfo
+	Keyword  string `form:"keyword"`                                             // 按敏感词模糊查询
+	Category string `form:"category"`                                            // 分类
+	Action   string `form:"action" validate:"omitempty,oneof=mask review block"` // 动作
+}
+
+type CreateSensitiveWordReq struct {
+	Words    []string `json:"words" validate:"required,min=1,max=1000,dive,required"` // 敏感词，支持批量导入，已存在的词会跳过
+	Category string   `json:"category" validate:"max=32"`                             // 分类
+	Action   string   `json:"action" validate:"required,oneof=mask review block"`     // 命中后的动作
+}
+
+type UpdateSensitiveWordReq struct {
+	ID       uint64 `json:"id" validate:"required"`
+	Category string `json:"category" validate:"max=32"`
+	Action   string `json:"action" validate:"required,oneof=mask review block"`
+}
+
+type SensitiveWordIdReq struct {
+	ID uint64 `uri:"id" validate:"required"`
+}
+
+type ModerationReviewListReq struct {
+	systemRequest.PageInfo
+	Status int8 `form:"status" validate:"omitempty,oneof=1 2 3"` // 状态（1待审核 2通过 3不通过），不传时查询全部
+}
+
+type ResolveReviewReq struct {
+	ID      uint64 `json:"id" validate:"required"`
+	Approve bool   `json:"approve"` // 是否通过，不通过时撤回消息
+}
+
+type GroupModerationReq struct {
+	GroupId uint64   `json:"groupId" validate:"required"`                        // 群组ID
+	Words   []string `json:"words" validate:"dive,required"`                     // 群自定义敏感词，整体替换
+	Action  string   `json:"action" validate:"required,oneof=mask review block"` // 群自定义敏感词命中后的动作
+	Strict  bool     `json:"strict"`                                             // 严格模式，全局敏感词中需替换的词直接拒绝发送
+}
@@ -0,0 +1,14 @@
+package request
+
+type PresenceReq struct {
+	UserIds []uint64 `json:"userIds" form:"userIds" validate:"required,min=1,max=500,dive,required"` // 用户ID列表
+}
+
+type SetPresenceReq struct {
+	Status string `json:"status" validate:"required,oneof=online away"` // 在线时展示的状态
+}
+
+type TypingReq struct {
+	ConversationId uint64 `json:"conversationId" validate:"required"` // 会话ID
+	Typing         bool   `json:"typing"`                             // true 开始输入，false 停止输入
+}
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type attachmentRepo struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepo(imDB *mysql.ImDB) repo.AttachmentRepo {
+	return &attachmentRepo{db: imDB.DB}
+}
+
+func (r *attachmentRepo) Create(ctx context.Context, attachment *model.Attachment) error {
+	return errors.WithStack(getDB(ctx, r.db).Create(attachment).Error)
+}
+
+func (r *attachmentRepo) Find(ctx context.Context, id uint64) (*model.Attachment, error) {
+	var attachment model.Attachment
+	if err := getDB(ctx, r.db).First(&attachment, id).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type friendRepo struct {
+	db *gorm.DB
+}
+
+func NewFriendRepo(imDB *mysql.ImDB) repo.FriendRepo {
+	return &friendRepo{db: imDB.DB}
+}
+
+func (r *friendRepo) FindPendingRequest(ctx context.Context, fromId, toId uint64) (*model.FriendRequest, error) {
+	var request model.FriendRequest
+	err := getDB(ctx, r.db).
+		Where("from_id = ? AND to_id = ? AND status = ?", fromId, toId, model.FriendRequestPending).
+		First(&request).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &request, nil
+}
+
+func (r *friendRepo) FindRequest(ctx context.Context, id uint64) (*model.FriendRequest, error) {
+	var request model.FriendRequest
+	if err := getDB(ctx, r.db).First(&request, id).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &request, nil
+}
+
+func (r *friendRepo) CreateRequest(ctx context.Context, request *model.FriendRequest) error {
+	return errors.WithStack(getDB(ctx, r.db).Create(request).Error)
+}
+
+func (r *friendRepo) UpdateRequest(ctx context.Context, id uint64, fields map[string]any) error {
+	err := getDB(ctx, r.db).Model(&model.FriendRequest{}).Where("id = ?", id).Updates(fields).Error
+	return errors.WithStack(err)
+}
+
+func (r *friendRepo) ListRequests(ctx context.Context, userId uint64, received bool, limit int) ([]*model.FriendRequest, error) {
+	column := "from_id"
+	if received {
+		column = "to_id"
+	}
+	var requests []*model.FriendRequest
+	err := getDB(ctx, r.db).
+		Where(column+" = ?", userId).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&requests).Error
+	return requests, errors.WithStack(err)
+}
+
+func (r *friendRepo) AddPair(ctx context.Context, userId, friendId uint64) error {
+	friends := []*model.Friend{
+		{UserID: userId, FriendID: friendId},
+		{UserID: friendId, FriendID: userId},
+	}
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&friends).Error
+	return errors.WithStack(err)
+}
+
+func (r *friendRepo) RemovePair(ctx context.Context, userId, friendId uint64) error {
+	err := getDB(ctx, r.db).
+		Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)", userId, friendId, friendId, userId).
+		Delete(&model.Friend{}).Error
+	return errors.WithStack(err)
+}
+
+func (r *friendRepo) Find(ctx context.Context, userId, friendId uint64) (*model.Friend, error) {
+	var friend model.Friend
+	err := getDB(ctx, r.db).Where("user_id = ? AND friend_id = ?", userId, friendId).First(&friend).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &friend, nil
+}
+
+func (r *friendRepo) List(ctx context.Context, userId uint64) ([]*model.Friend, error) {
+	var friends []*model.Friend
+	err := getDB(ctx, r.db).
+		Where("user_id = ?", userId).
+		Order("contact_group ASC, created_at ASC").
+		Find(&friends).Error
+	return friends, errors.WithStack(err)
+}
+
+func (r *friendRepo) FindFriendIds(ctx context.Context, userId uint64, friendIds []uint64) ([]uint64, error) {
+	var ids []uint64
+	if len(friendIds) == 0 {
+		return ids, nil
+	}
+	err := getDB(ctx, r.db).Model(&model.Friend{}).
+		Where("user_id = ? AND friend_id IN ?", userId, friendIds).
+		Pluck("friend_id", &ids).Error
+	return ids, errors.WithStack(err)
+}
+
+func (r *friendRepo) Update(ctx context.Context, userId, friendId uint64, fields map[string]any) error {
+	err := getDB(ctx, r.db).Model(&model.Friend{}).
+		Where("user_id = ? AND friend_id = ?", userId, friendId).
+		Updates(fields).Error
+	return errors.WithStack(err)
+}
+
+type blockRepo struct {
+	db *gorm.DB
+}
+
+func NewBlockRepo(imDB *mysql.ImDB) repo.BlockRepo {
+	return &blockRepo{db: imDB.DB}
+}
+
+func (r *blockRepo) Create(ctx context.Context, userId, blockedId uint64) error {
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.Block{UserID: userId, BlockedID: blockedId}).Error
+	return errors.WithStack(err)
+}
+
+func (r *blockRepo) Delete(ctx context.Context, userId, blockedId uint64) error {
+	err := getDB(ctx, r.db).Where("user_id = ? AND blocked_id = ?", userId, blockedId).Delete(&model.Block{}).Error
+	return errors.WithStack(err)
+}
+
+func (r *blockRepo) Exists(ctx context.Context, userId, blockedId uint64) (bool, error) {
+	var count int64
+	err := getDB(ctx, r.db).Model(&model.Block{}).
+		Where("user_id = ? AND blocked_id = ?", userId, blockedId).
+		Count(&count).Error
+	return count > 0, errors.WithStack(err)
+}
+
+func (r *blockRepo) List(ctx context.Context, userId uint64) ([]*model.Block, error) {
+	var blocks []*model.Block
+	err := getDB(ctx, r.db).Where("user_id = ?", userId).Order("created_at DESC").Find(&blocks).Error
+	return blocks, errors.WithStack(err)
+}
+
+func (r *blockRepo) FindBlockerIds(ctx context.Context, blockedId uint64, userIds []uint64) ([]uint64, error) {
+	var ids []uint64
+	if len(userIds) == 0 {
+		return ids, nil
+	}
+	err := getDB(ctx, r.db).Model(&model.Block{}).
+		Where("blocked_id = ? AND user_id IN ?", blockedId, userIds).
+		Pluck("user_id", &ids).Error
+	return ids, errors.WithStack(err)
+}
+
+func (r *blockRepo) FindBlockedIds(ctx context.Context, userId uint64, blockedIds []uint64) ([]uint64, error) {
+	var ids []uint64
+	if len(blockedIds) == 0 {
+		return ids, nil
+	}
+	err := getDB(ctx, r.db).Model(&model.Block{}).
+		Where("user_id = ? AND blocked_id IN ?", userId, blockedIds).
+		Pluck("blocked_id", &ids).Error
+	return ids, errors.WithStack(err)
+}
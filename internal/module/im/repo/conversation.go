@@ -0,0 +1,184 @@
+package repo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"server/internal/core/mysql"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type conversationRepo struct {
+	db *gorm.DB
+}
+
+func NewConversationRepo(imDB *mysql.ImDB) repo.ConversationRepo {
+	return &conversationRepo{db: imDB.DB}
+}
+
+func (r *conversationRepo) FindOrCreateSingle(ctx context.Context, userId, peerId uint64) (*model.Conversation, error) {
+	key := model.SingleConversationKey(userId, peerId)
+	var conversation model.Conversation
+	err := getDB(ctx, r.db).Where("`key` = ?", key).First(&conversation).Error
+	if err == nil {
+		return &conversation, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(err)
+	}
+
+	// 并发创建时忽略唯一索引冲突，随后重新读取
+	err = getDB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		conversation = model.Conversation{Type: model.ConversationTypeSingle, Key: key}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&conversation).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("`key` = ?", key).First(&conversation).Error; err != nil {
+			return err
+		}
+		members := []*model.ConversationMember{
+			{ConversationID: conversation.ID, UserID: userId, PeerID: peerId},
+			{ConversationID: conversation.ID, UserID: peerId, PeerID: userId},
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &conversation, nil
+}
+
+func (r *conversationRepo) CreateGroup(ctx context.Context) (*model.Conversation, error) {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	conversation := &model.Conversation{Type: model.ConversationTypeGroup, Key: "group:" + hex.EncodeToString(b)}
+	if err := getDB(ctx, r.db).Create(conversation).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return conversation, nil
+}
+
+func (r *conversationRepo) Find(ctx context.Context, id uint64) (*model.Conversation, error) {
+	var conversation model.Conversation
+	if err := getDB(ctx, r.db).First(&conversation, id).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &conversation, nil
+}
+
+func (r *conversationRepo) Touch(ctx context.Context, id uint64) error {
+	err := getDB(ctx, r.db).Model(&model.Conversation{}).Where("id = ?", id).Update("updated_at", time.Now()).Error
+	return errors.WithStack(err)
+}
+
+func (r *conversationRepo) NextSeq(ctx context.Context, id uint64) (uint64, error) {
+	db := getDB(ctx, r.db)
+	result := db.Model(&model.Conversation{}).Where("id = ?", id).
+		Update("last_seq", gorm.Expr("last_seq + 1"))
+	if result.Error != nil {
+		return 0, errors.WithStack(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, errors.WithStack(gorm.ErrRecordNotFound)
+	}
+
+	var seq uint64
+	err := db.Model(&model.Conversation{}).Where("id = ?", id).Pluck("last_seq", &seq).Error
+	return seq, errors.WithStack(err)
+}
+
+func (r *conversationRepo) FindMember(ctx context.Context, conversationId, userId uint64) (*model.ConversationMember, error) {
+	var member model.ConversationMember
+	err := getDB(ctx, r.db).
+		Where("conversation_id = ? AND user_id = ?", conversationId, userId).
+		First(&member).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &member, nil
+}
+
+func (r *conversationRepo) ListMembers(ctx context.Context, conversationId uint64) ([]*model.ConversationMember, error) {
+	var members []*model.ConversationMember
+	err := getDB(ctx, r.db).Where("conversation_id = ?", conversationId).Find(&members).Error
+	return members, errors.WithStack(err)
+}
+
+func (r *conversationRepo) FindPeerIds(ctx context.Context, userId uint64, peerIds []uint64) ([]uint64, error) {
+	var ids []uint64
+	if len(peerIds) == 0 {
+		return ids, nil
+	}
+	err := getDB(ctx, r.db).Table("im_conversation_member AS m").
+		Joins("JOIN im_conversation_member AS p ON p.conversation_id = m.conversation_id").
+		Where("m.user_id = ? AND p.user_id IN ?", userId, peerIds).
+		Distinct().
+		Pluck("p.user_id", &ids).Error
+	return ids, errors.WithStack(err)
+}
+
+func (r *conversationRepo) ListByUser(ctx context.Context, userId uint64, updatedAfter time.Time, afterId uint64, limit int) ([]*model.UserConversation, error) {
+	var conversations []*model.UserConversation
+	const updatedAt = "GREATEST(c.updated_at, m.updated_at)"
+	err := getDB(ctx, r.db).Table("im_conversation_member AS m").
+		Select("m.conversation_id, c.type, m.peer_id, m.start_seq, c.last_seq, m.delivered_seq, m.read_seq, "+
+			updatedAt+" AS updated_at").
+		Joins("JOIN im_conversation AS c ON c.id = m.conversation_id").
+		Where("m.user_id = ?", userId).
+		Where("("+updatedAt+" > ? OR ("+updatedAt+" = ? AND m.conversation_id > ?))", updatedAfter, updatedAfter, afterId).
+		Order("updated_at ASC, m.conversation_id ASC").
+		Limit(limit).
+		Scan(&conversations).Error
+	return conversations, errors.WithStack(err)
+}
+
+func (r *conversationRepo) AddMembers(ctx context.Context, conversationId uint64, userIds []uint64, startSeq uint64) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	members := make([]*model.ConversationMember, 0, len(userIds))
+	for _, userId := range userIds {
+		members = append(members, &model.ConversationMember{
+			ConversationID: conversationId,
+			UserID:         userId,
+			StartSeq:       startSeq,
+			DeliveredSeq:   startSeq,
+			ReadSeq:        startSeq,
+		})
+	}
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+	return errors.WithStack(err)
+}
+
+func (r *conversationRepo) RemoveMembers(ctx context.Context, conversationId uint64, userIds []uint64) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+	err := getDB(ctx, r.db).
+		Where("conversation_id = ? AND user_id IN ?", conversationId, userIds).
+		Delete(&model.ConversationMember{}).Error
+	return errors.WithStack(err)
+}
+
+func (r *conversationRepo) AdvanceDelivered(ctx context.Context, conversationId, userId, seq uint64) (bool, error) {
+	result := getDB(ctx, r.db).Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND delivered_seq < ?", conversationId, userId, seq).
+		Update("delivered_seq", seq)
+	return result.RowsAffected > 0, errors.WithStack(result.Error)
+}
+
+func (r *conversationRepo) AdvanceRead(ctx context.Context, conversationId, userId, seq uint64) (bool, error) {
+	result := getDB(ctx, r.db).Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND read_seq < ?", conversationId, userId, seq).
+		Updates(map[string]any{
+			"read_seq":      seq,
+			"delivered_seq": gorm.Expr("GREATEST(delivered_seq, ?)", seq),
+		})
+	return result.RowsAffected > 0, errors.WithStack(result.Error)
+}
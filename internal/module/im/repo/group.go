@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type groupRepo struct {
+	db *gorm.DB
+}
+
+func NewGroupRepo(imDB *mysql.ImDB) repo.GroupRepo {
+	return &groupRepo{db: imDB.DB}
+}
+
+func (r *groupRepo) Create(ctx context.Context, group *model.Group) error {
+	return errors.WithStack(getDB(ctx, r.db).Create(group).Error)
+}
+
+func (r *groupRepo) Find(ctx context.Context, id uint64) (*model.Group, error) {
+	var group model.Group
+	if err := getDB(ctx, r.db).First(&group, id).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &group, nil
+}
+
+func (r *groupRepo) FindForUpdate(ctx context.Context, id uint64) (*model.Group, error) {
+	var group model.Group
+	err := getDB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&group, id).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &group, nil
+}
+
+func (r *groupRepo) Update(ctx context.Context, id uint64, fields map[string]any) error {
+	err := getDB(ctx, r.db).Model(&model.Group{}).Where("id = ?", id).Updates(fields).Error
+	return errors.WithStack(err)
+}
+
+func (r *groupRepo) AddMemberCount(ctx context.Context, id uint64, delta int) error {
+	err := getDB(ctx, r.db).Model(&model.Group{}).Where("id = ?", id).
+		Update("member_count", gorm.Expr("member_count + ?", delta)).Error
+	return errors.WithStack(err)
+}
+
+func (r *groupRepo) CountOwned(ctx context.Context, ownerId uint64) (int64, error) {
+	var count int64
+	err := getDB(ctx, r.db).Model(&model.Group{}).Where("owner_id = ?", ownerId).Count(&count).Error
+	return count, errors.WithStack(err)
+}
+
+func (r *groupRepo) ListByUser(ctx context.Context, userId uint64) ([]*model.Group, error) {
+	var groups []*model.Group
+	db := getDB(ctx, r.db)
+	err := db.Where("id IN (?)", db.Model(&model.GroupMember{}).Select("group_id").Where("user_id = ?", userId)).
+		Order("id DESC").
+		Find(&groups).Error
+	return groups, errors.WithStack(err)
+}
+
+func (r *groupRepo) AddMembers(ctx context.Context, members []*model.GroupMember) (int, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	result := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&members)
+	return int(result.RowsAffected), errors.WithStack(result.Error)
+}
+
+func (r *groupRepo) FindMember(ctx context.Context, groupId, userId uint64) (*model.GroupMember, error) {
+	var member model.GroupMember
+	err := getDB(ctx, r.db).Where("group_id = ? AND user_id = ?", groupId, userId).First(&member).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &member, nil
+}
+
+func (r *groupRepo) FindMembers(ctx context.Context, groupId uint64, userIds []uint64) ([]*model.GroupMember, error) {
+	var members []*model.GroupMember
+	if len(userIds) == 0 {
+		return members, nil
+	}
+	err := getDB(ctx, r.db).Where("group_id = ? AND user_id IN ?", groupId, userIds).Find(&members).Error
+	return members, errors.WithStack(err)
+}
+
+func (r *groupRepo) ListMembers(ctx context.Context, groupId uint64) ([]*model.GroupMember, error) {
+	var members []*model.GroupMember
+	err := getDB(ctx, r.db).Where("group_id = ?", groupId).Order("role ASC, created_at ASC").Find(&members).Error
+	return members, errors.WithStack(err)
+}
+
+func (r *groupRepo) UpdateMember(ctx context.Context, groupId, userId uint64, fields map[string]any) error {
+	err := getDB(ctx, r.db).Model(&model.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupId, userId).
+		Updates(fields).Error
+	return errors.WithStack(err)
+}
+
+func (r *groupRepo) RemoveMember(ctx context.Context, groupId, userId uint64) error {
+	err := getDB(ctx, r.db).Where("group_id = ? AND user_id = ?", groupId, userId).Delete(&model.GroupMember{}).Error
+	return errors.WithStack(err)
+}
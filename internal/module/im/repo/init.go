@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"server/internal/core/mysql"
+	"server/internal/module/im/biz/repo"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type initRepo struct {
+	db *gorm.DB
+}
+
+func NewInitRepo(imDB *mysql.ImDB) repo.InitRepo {
+	return &initRepo{db: imDB.DB}
+}
+
+func (r *initRepo) AutoMigrate(tables []schema.Tabler) error {
+	for _, table := range tables {
+		if err := r.db.AutoMigrate(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type messageRepo struct {
+	db *gorm.DB
+}
+
+func NewMessageRepo(imDB *mysql.ImDB) repo.MessageRepo {
+	return &messageRepo{db: imDB.DB}
+}
+
+func (r *messageRepo) Create(ctx context.Context, message *model.Message) (bool, error) {
+	result := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(message)
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *messageRepo) FindByClientMsgId(ctx context.Context, senderId uint64, clientMsgId string) (*model.Message, error) {
+	var message model.Message
+	err := getDB(ctx, r.db).
+		Where("sender_id = ? AND client_msg_id = ?", senderId, clientMsgId).
+		First(&message).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &message, nil
+}
+
+func (r *messageRepo) ListAfter(ctx context.Context, conversationId, afterSeq uint64, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+	err := getDB(ctx, r.db).
+		Where("conversation_id = ? AND seq > ?", conversationId, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, errors.WithStack(err)
+}
+
+func (r *messageRepo) ListBefore(ctx context.Context, conversationId, beforeSeq, minSeq uint64, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+	err := getDB(ctx, r.db).
+		Where("conversation_id = ? AND seq < ? AND seq > ?", conversationId, beforeSeq, minSeq).
+		Order("seq DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, errors.WithStack(err)
+}
+
+func (r *messageRepo) FindBySeqs(ctx context.Context, seqs map[uint64]uint64) ([]*model.Message, error) {
+	var messages []*model.Message
+	if len(seqs) == 0 {
+		return messages, nil
+	}
+	pairs := make([][]any, 0, len(seqs))
+	for conversationId, seq := range seqs {
+		pairs = append(pairs, []any{conversationId, seq})
+	}
+	err := getDB(ctx, r.db).Where("(conversation_id, seq) IN ?", pairs).Find(&messages).Error
+	return messages, errors.WithStack(err)
+}
+
+func (r *messageRepo) FindBySeq(ctx context.Context, conversationId, seq uint64) (*model.Message, error) {
+	var message model.Message
+	err := getDB(ctx, r.db).
+		Where("conversation_id = ? AND seq = ?", conversationId, seq).
+		First(&message).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &message, nil
+}
+
+func (r *messageRepo) ListChanged(ctx context.Context, conversationId uint64, changedAfter time.Time, afterSeq, minSeq, maxSeq uint64, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+	err := getDB(ctx, r.db).
+		Where("conversation_id = ? AND seq > ? AND seq <= ?", conversationId, minSeq, maxSeq).
+		Where("(updated_at > ? OR (updated_at = ? AND seq > ?))", changedAfter, changedAfter, afterSeq).
+		Order("updated_at ASC, seq ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, errors.WithStack(err)
+}
+
+func (r *messageRepo) Update(ctx context.Context, id uint64, values map[string]any) error {
+	err := getDB(ctx, r.db).Model(&model.Message{}).Where("id = ?", id).Updates(values).Error
+	return errors.WithStack(err)
+}
+
+func (r *messageRepo) CreateEdit(ctx context.Context, edit *model.MessageEdit) error {
+	return errors.WithStack(getDB(ctx, r.db).Create(edit).Error)
+}
+
+func (r *messageRepo) ListEdits(ctx context.Context, messageId uint64) ([]*model.MessageEdit, error) {
+	var edits []*model.MessageEdit
+	err := getDB(ctx, r.db).Where("message_id = ?", messageId).Order("id ASC").Find(&edits).Error
+	return edits, errors.WithStack(err)
+}
+
+func (r *messageRepo) DeleteEdits(ctx context.Context, messageId uint64) error {
+	err := getDB(ctx, r.db).Where("message_id = ?", messageId).Delete(&model.MessageEdit{}).Error
+	return errors.WithStack(err)
+}
+
+func (r *messageRepo) AddReaction(ctx context.Context, reaction *model.MessageReaction) (bool, error) {
+	result := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *messageRepo) RemoveReaction(ctx context.Context, messageId, userId uint64, emoji string) (bool, error) {
+	result := getDB(ctx, r.db).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageId, userId, emoji).
+		Delete(&model.MessageReaction{})
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *messageRepo) ListReactions(ctx context.Context, messageIds []uint64) ([]*model.MessageReaction, error) {
+	var reactions []*model.MessageReaction
+	if len(messageIds) == 0 {
+		return reactions, nil
+	}
+	err := getDB(ctx, r.db).Where("message_id IN ?", messageIds).Order("created_at ASC").Find(&reactions).Error
+	return reactions, errors.WithStack(err)
+}
+
+func (r *messageRepo) DeleteReactions(ctx context.Context, messageId uint64) error {
+	err := getDB(ctx, r.db).Where("message_id = ?", messageId).Delete(&model.MessageReaction{}).Error
+	return errors.WithStack(err)
+}
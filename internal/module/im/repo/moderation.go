@@ -0,0 +1,188 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"server/internal/core/mysql"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+	"server/internal/module/im/model/request"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sensitiveWordRepo struct {
+	db *gorm.DB
+}
+
+func NewSensitiveWordRepo(imDB *mysql.ImDB) repo.SensitiveWordRepo {
+	return &sensitiveWordRepo{db: imDB.DB}
+}
+
+func (r *sensitiveWordRepo) List(ctx context.Context, req *request.SensitiveWordListReq) ([]*model.SensitiveWord, int64, error) {
+	var (
+		words []*model.SensitiveWord
+		total int64
+	)
+	db := getDB(ctx, r.db).Model(&model.SensitiveWord{})
+	if req.Keyword != "" {
+		db = db.Where("word LIKE ?", "%"+req.Keyword+"%")
+	}
+	if req.Category != "" {
+		db = db.Where("category = ?", req.Category)
+	}
+	if req.Action != "" {
+		db = db.Where("action = ?", req.Action)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	if err := db.Offset(offset).Limit(limit).Order("id DESC").Find(&words).Error; err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return words, total, nil
+}
+
+func (r *sensitiveWordRepo) ListAll(ctx context.Context) ([]*model.SensitiveWord, error) {
+	var words []*model.SensitiveWord
+	err := getDB(ctx, r.db).Select("id", "word", "action").Find(&words).Error
+	return words, errors.WithStack(err)
+}
+
+func (r *sensitiveWordRepo) Version(ctx context.Context) (string, error) {
+	var result struct {
+		Total     int64
+		UpdatedAt *time.Time
+	}
+	err := getDB(ctx, r.db).Model(&model.SensitiveWord{}).
+		Select("COUNT(*) AS total, MAX(updated_at) AS updated_at").
+		Scan(&result).Error
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	var updatedAt int64
+	if result.UpdatedAt != nil {
+		updatedAt = result.UpdatedAt.UnixNano()
+	}
+	return fmt.Sprintf("%d:%d", result.Total, updatedAt), nil
+}
+
+func (r *sensitiveWordRepo) Find(ctx context.Context, id uint64) (*model.SensitiveWord, error) {
+	var word model.SensitiveWord
+	if err := getDB(ctx, r.db).First(&word, id).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &word, nil
+}
+
+func (r *sensitiveWordRepo) FindByWords(ctx context.Context, words []string) ([]*model.SensitiveWord, error) {
+	var result []*model.SensitiveWord
+	if len(words) == 0 {
+		return result, nil
+	}
+	err := getDB(ctx, r.db).Where("word IN ?", words).Find(&result).Error
+	return result, errors.WithStack(err)
+}
+
+// BatchCreate 并发导入相同的词时忽略唯一索引冲突
+func (r *sensitiveWordRepo) BatchCreate(ctx context.Context, words []*model.SensitiveWord) error {
+	if len(words) == 0 {
+		return nil
+	}
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(words, 200).Error
+	return errors.WithStack(err)
+}
+
+func (r *sensitiveWordRepo) Update(ctx context.Context, id uint64, fields map[string]any) error {
+	err := getDB(ctx, r.db).Model(&model.SensitiveWord{}).Where("id = ?", id).Updates(fields).Error
+	return errors.WithStack(err)
+}
+
+func (r *sensitiveWordRepo) Delete(ctx context.Context, id uint64) (bool, error) {
+	result := getDB(ctx, r.db).Delete(&model.SensitiveWord{}, id)
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+type groupModerationRepo struct {
+	db *gorm.DB
+}
+
+func NewGroupModerationRepo(imDB *mysql.ImDB) repo.GroupModerationRepo {
+	return &groupModerationRepo{db: imDB.DB}
+}
+
+func (r *groupModerationRepo) Find(ctx context.Context, groupId uint64) (*model.GroupModeration, error) {
+	var setting model.GroupModeration
+	if err := getDB(ctx, r.db).First(&setting, groupId).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &setting, nil
+}
+
+func (r *groupModerationRepo) Save(ctx context.Context, setting *model.GroupModeration) error {
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"words", "action", "strict", "updated_at"}),
+	}).Create(setting).Error
+	return errors.WithStack(err)
+}
+
+type moderationReviewRepo struct {
+	db *gorm.DB
+}
+
+func NewModerationReviewRepo(imDB *mysql.ImDB) repo.ModerationReviewRepo {
+	return &moderationReviewRepo{db: imDB.DB}
+}
+
+func (r *moderationReviewRepo) Create(ctx context.Context, review *model.ModerationReview) error {
+	return errors.WithStack(getDB(ctx, r.db).Create(review).Error)
+}
+
+func (r *moderationReviewRepo) Find(ctx context.Context, id uint64) (*model.ModerationReview, error) {
+	var review model.ModerationReview
+	if err := getDB(ctx, r.db).First(&review, id).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &review, nil
+}
+
+// List 待审核的记录按提交顺序排列，其他状态按审核时间倒序排列
+func (r *moderationReviewRepo) List(ctx context.Context, req *request.ModerationReviewListReq) ([]*model.ModerationReview, int64, error) {
+	var (
+		reviews []*model.ModerationReview
+		total   int64
+	)
+	db := getDB(ctx, r.db).Model(&model.ModerationReview{})
+	order := "id DESC"
+	if req.Status != 0 {
+		db = db.Where("status = ?", req.Status)
+		if req.Status == model.ReviewPending {
+			order = "id ASC"
+		}
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	if err := db.Offset(offset).Limit(limit).Order(order).Find(&reviews).Error; err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return reviews, total, nil
+}
+
+func (r *moderationReviewRepo) Resolve(ctx context.Context, id uint64, fields map[string]any) (bool, error) {
+	result := getDB(ctx, r.db).Model(&model.ModerationReview{}).
+		Where("id = ? AND status = ?", id, model.ReviewPending).
+		Updates(fields)
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,243 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"server/internal/module/im/biz/repo"
+	"server/internal/module/im/model"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	presenceConnsKey  = "im:presence:conns:%d" // zset，member 为连接ID，score 为过期时间（毫秒）
+	presenceUserKey   = "im:presence:user:%d"  // hash，保存 status 和 last_seen
+	presenceChannel   = "im:presence:changed"
+	presenceUserTTL   = 30 * 24 * time.Hour
+	presenceLastSeen  = "last_seen"
+	presenceStatusKey = "status"
+)
+
+func NewPresenceRepo(rdb *redis.Client) repo.PresenceRepo {
+	if rdb == nil {
+		return &presenceLocalRepo{users: make(map[uint64]*localPresence)}
+	}
+	return &presenceRedisRepo{rdb: rdb}
+}
+
+// presenceRedisRepo 每个连接单独记录过期时间，实例异常退出后其连接到期自动失效
+type presenceRedisRepo struct {
+	rdb *redis.Client
+}
+
+func (r *presenceRedisRepo) Touch(ctx context.Context, userId uint64, connId string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	key := fmt.Sprintf(presenceConnsKey, userId)
+	pipe := r.rdb.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+	added := pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.Add(ttl).UnixMilli()), Member: connId})
+	card := pipe.ZCard(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return added.Val() == 1 && card.Val() == 1, nil
+}
+
+func (r *presenceRedisRepo) Remove(ctx context.Context, userId uint64, connId string) (bool, error) {
+	now := time.Now()
+	key := fmt.Sprintf(presenceConnsKey, userId)
+	userKey := fmt.Sprintf(presenceUserKey, userId)
+	pipe := r.rdb.TxPipeline()
+	pipe.ZRem(ctx, key, connId)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+	card := pipe.ZCard(ctx, key)
+	pipe.HSet(ctx, userKey, presenceLastSeen, now.UnixMilli())
+	pipe.Expire(ctx, userKey, presenceUserTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return card.Val() == 0, nil
+}
+
+func (r *presenceRedisRepo) SetStatus(ctx context.Context, userId uint64, status string) error {
+	userKey := fmt.Sprintf(presenceUserKey, userId)
+	pipe := r.rdb.TxPipeline()
+	pipe.HSet(ctx, userKey, presenceStatusKey, status)
+	pipe.Expire(ctx, userKey, presenceUserTTL)
+	_, err := pipe.Exec(ctx)
+	return errors.WithStack(err)
+}
+
+func (r *presenceRedisRepo) Find(ctx context.Context, userIds []uint64) ([]*model.Presence, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	pipe := r.rdb.Pipeline()
+	counts := make([]*redis.IntCmd, len(userIds))
+	values := make([]*redis.SliceCmd, len(userIds))
+	for i, userId := range userIds {
+		counts[i] = pipe.ZCount(ctx, fmt.Sprintf(presenceConnsKey, userId), "("+now, "+inf")
+		values[i] = pipe.HMGet(ctx, fmt.Sprintf(presenceUserKey, userId), presenceStatusKey, presenceLastSeen)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]*model.Presence, 0, len(userIds))
+	for i, userId := range userIds {
+		presence := &model.Presence{UserID: userId, Status: model.PresenceOffline}
+		fields := values[i].Val()
+		if counts[i].Val() > 0 {
+			presence.Status = model.PresenceOnline
+			if status, ok := fields[0].(string); ok && status != "" {
+				presence.Status = status
+			}
+		}
+		if lastSeen, ok := fields[1].(string); ok {
+			if ms, err := strconv.ParseInt(lastSeen, 10, 64); err == nil {
+				t := time.UnixMilli(ms)
+				presence.LastSeen = &t
+			}
+		}
+		result = append(result, presence)
+	}
+	return result, nil
+}
+
+func (r *presenceRedisRepo) Publish(ctx context.Context, presence *model.Presence) error {
+	data, err := json.Marshal(presence)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(r.rdb.Publish(ctx, presenceChannel, data).Err())
+}
+
+func (r *presenceRedisRepo) Subscribe(ctx context.Context, handler func(presence *model.Presence)) error {
+	sub := r.rdb.Subscribe(ctx, presenceChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return errors.WithStack(err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var presence model.Presence
+			if err := json.Unmarshal([]byte(msg.Payload), &presence); err != nil {
+				continue
+			}
+			handler(&presence)
+		}
+	}
+}
+
+type localPresence struct {
+	conns    map[string]time.Time
+	status   string
+	lastSeen *time.Time
+}
+
+// presenceLocalRepo 单实例部署时使用，状态随进程重启丢失
+type presenceLocalRepo struct {
+	mu       sync.Mutex
+	users    map[uint64]*localPresence
+	handlers []func(presence *model.Presence)
+}
+
+func (r *presenceLocalRepo) user(userId uint64) *localPresence {
+	p, ok := r.users[userId]
+	if !ok {
+		p = &localPresence{conns: make(map[string]time.Time)}
+		r.users[userId] = p
+	}
+	return p
+}
+
+// alive 清理过期连接并返回剩余连接数
+func (p *localPresence) alive(now time.Time) int {
+	for connId, expireAt := range p.conns {
+		if !expireAt.After(now) {
+			delete(p.conns, connId)
+		}
+	}
+	return len(p.conns)
+}
+
+func (r *presenceLocalRepo) Touch(_ context.Context, userId uint64, connId string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	p := r.user(userId)
+	first := p.alive(now) == 0
+	p.conns[connId] = now.Add(ttl)
+	return first, nil
+}
+
+func (r *presenceLocalRepo) Remove(_ context.Context, userId uint64, connId string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	p := r.user(userId)
+	delete(p.conns, connId)
+	p.lastSeen = &now
+	return p.alive(now) == 0, nil
+}
+
+func (r *presenceLocalRepo) SetStatus(_ context.Context, userId uint64, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.user(userId).status = status
+	return nil
+}
+
+func (r *presenceLocalRepo) Find(_ context.Context, userIds []uint64) ([]*model.Presence, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	result := make([]*model.Presence, 0, len(userIds))
+	for _, userId := range userIds {
+		presence := &model.Presence{UserID: userId, Status: model.PresenceOffline}
+		if p, ok := r.users[userId]; ok {
+			if p.alive(now) > 0 {
+				presence.Status = model.PresenceOnline
+				if p.status != "" {
+					presence.Status = p.status
+				}
+			}
+			presence.LastSeen = p.lastSeen
+		}
+		result = append(result, presence)
+	}
+	return result, nil
+}
+
+func (r *presenceLocalRepo) Publish(_ context.Context, presence *model.Presence) error {
+	r.mu.Lock()
+	handlers := r.handlers
+	r.mu.Unlock()
+	for _, handler := range handlers {
+		handler(presence)
+	}
+	return nil
+}
+
+func (r *presenceLocalRepo) Subscribe(ctx context.Context, handler func(presence *model.Presence)) error {
+	r.mu.Lock()
+	r.handlers = append(r.handlers, handler)
+	r.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"github.com/google/wire"
+)
+
+var ProviderSet = wire.NewSet(
+	NewTransaction,
+	NewInitRepo,
+	NewConversationRepo,
+	NewMessageRepo,
+	NewGroupRepo,
+	NewPresenceRepo,
+	NewFriendRepo,
+	NewBlockRepo,
+	NewAttachmentRepo,
+	NewSensitiveWordRepo,
+	NewGroupModerationRepo,
+	NewModerationReviewRepo,
+)
@@ -6,6 +6,7 @@ import (
 	_ "server/internal/module/system/model/reply"
 	"server/internal/module/system/model/request"
 	"server/pkg/response"
+	"server/pkg/validatex"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -29,6 +30,9 @@ func (a *ApiApi) InitApiApi(router *gin.RouterGroup) {
 	router.POST("", a.Create)
 	router.PUT("", a.Update)
 	router.DELETE(":id", a.Delete)
+	router.GET("recycle/list", a.RecycleList)
+	router.PUT("recycle/restore", a.Restore)
+	router.DELETE("recycle/purge", a.Purge)
 }
 
 // List godoc
@@ -46,13 +50,13 @@ func (a *ApiApi) InitApiApi(router *gin.RouterGroup) {
 // @Router /api/system/api/list [get]
 func (a *ApiApi) List(c *gin.Context) {
 	var req request.ApiListReq
-	if err := c.ShouldBindQuery(&req); err != nil {
+	if err := validatex.BindQuery(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	result, err := a.apiUsecase.List(c, &req)
 	if err != nil {
-		a.logger.Error("[ApiApi] List error", zap.Error(err))
+		a.logger.WithContext(c).Error("[ApiApi] List error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -70,12 +74,12 @@ func (a *ApiApi) List(c *gin.Context) {
 // @Router /api/system/api [post]
 func (a *ApiApi) Create(c *gin.Context) {
 	var req request.CreateApiReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.apiUsecase.Create(c, &req); err != nil {
-		a.logger.Error("[ApiApi] Create error", zap.Error(err))
+		a.logger.WithContext(c).Error("[ApiApi] Create error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -93,12 +97,12 @@ func (a *ApiApi) Create(c *gin.Context) {
 // @Router /api/system/api [put]
 func (a *ApiApi) Update(c *gin.Context) {
 	var req request.UpdateApiReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.apiUsecase.Update(c, &req); err != nil {
-		a.logger.Error("[ApiApi] Update error", zap.Error(err))
+		a.logger.WithContext(c).Error("[ApiApi] Update error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -123,7 +127,79 @@ func (a *ApiApi) Delete(c *gin.Context) {
 	}
 	req := &request.DeleteApiReq{ID: idInt}
 	if err := a.apiUsecase.Delete(c, req); err != nil {
-		a.logger.Error("[ApiApi] Delete error", zap.Error(err))
+		a.logger.WithContext(c).Error("[ApiApi] Delete error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// RecycleList godoc
+// @Summary 获取接口回收站列表
+// @Tags 接口管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param current query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param keyword query string false "关键字"
+// @Success 200 {object} server_internal_module_system_model_reply.PageReply
+// @Router /api/system/api/recycle/list [get]
+func (a *ApiApi) RecycleList(c *gin.Context) {
+	var req request.RecycleListReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.apiUsecase.RecycleList(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[ApiApi] RecycleList error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Restore godoc
+// @Summary 从回收站恢复接口
+// @Tags 接口管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "接口ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/api/recycle/restore [put]
+func (a *ApiApi) Restore(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.apiUsecase.Restore(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[ApiApi] Restore error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Purge godoc
+// @Summary 彻底删除回收站中的接口
+// @Tags 接口管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "接口ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/api/recycle/purge [delete]
+func (a *ApiApi) Purge(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.apiUsecase.Purge(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[ApiApi] Purge error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -5,8 +5,8 @@ import (
 	"server/internal/module/system/biz"
 	_ "server/internal/module/system/model/reply"
 	"server/internal/module/system/model/request"
-	"server/pkg/errorx"
 	"server/pkg/response"
+	"server/pkg/validatex"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -40,15 +40,14 @@ func (a *AuthApi) InitAuthApi(router *gin.RouterGroup) {
 // @Router /api/auth/login [post]
 func (a *AuthApi) Login(c *gin.Context) {
 	var req request.LoginReq
-	if err := c.ShouldBindJSON(&req); err != nil {
-		a.logger.Error("[AuthApi] ShouldBindJSON error", zap.Any("req", req), zap.Any("err", err))
-		response.Fail(c, errorx.ErrInvalidParam)
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
 		return
 	}
 
 	reply, err := a.authUsecase.Login(c, &req, c.ClientIP())
 	if err != nil {
-		a.logger.Error("[AuthApi] Login error", zap.Any("req", req), zap.Error(err))
+		a.logger.WithContext(c).Error("[AuthApi] Login error", zap.Any("req", req), zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -65,14 +64,13 @@ func (a *AuthApi) Login(c *gin.Context) {
 // @Router /api/auth/register [post]
 func (a *AuthApi) Register(c *gin.Context) {
 	var req request.RegisterReq
-	if err := c.ShouldBindJSON(&req); err != nil {
-		a.logger.Error("[AuthApi] ShouldBindJSON error", zap.Any("req", req), zap.Any("err", err))
-		response.Fail(c, errorx.ErrInvalidParam)
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
 		return
 	}
 
 	if err := a.authUsecase.Register(c, &req); err != nil {
-		a.logger.Error("[AuthApi] Register error", zap.Any("req", req), zap.Error(err))
+		a.logger.WithContext(c).Error("[AuthApi] Register error", zap.Any("req", req), zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -89,8 +87,8 @@ func (a *AuthApi) Register(c *gin.Context) {
 // @Router /api/auth/emailLogin [post]
 func (a *AuthApi) EmailLogin(c *gin.Context) {
 	var req request.EmailLoginReq
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Fail(c, errorx.ErrInvalidParam)
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
 		return
 	}
 
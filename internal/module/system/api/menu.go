@@ -2,16 +2,27 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
 	"server/internal/core/logger"
 	"server/internal/module/system/biz"
 	"server/internal/module/system/model"
 	_ "server/internal/module/system/model/reply"
-	modelResponse "server/internal/module/system/model/response"
+	"server/internal/module/system/model/request"
+	"server/pkg/errorx"
 	"server/pkg/response"
+	"server/pkg/validatex"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	menuTransferJSON = "json"
+	menuTransferYAML = "yaml"
 )
 
 type MenuApi struct {
@@ -32,6 +43,14 @@ func (a *MenuApi) InitMenuApi(router *gin.RouterGroup) {
 	router.POST("", a.Create)
 	router.PUT("", a.Update)
 	router.DELETE(":id", a.Delete)
+	router.GET("recycle/list", a.RecycleList)
+	router.PUT("recycle/restore", a.Restore)
+	router.DELETE("recycle/purge", a.Purge)
+	router.GET(":id/apis", a.GetButtonApis)
+	router.PUT("apis", a.BindButtonApis)
+	router.PUT("sort", a.Sort)
+	router.GET("export", a.Export)
+	router.POST("import", a.Import)
 }
 
 // GetMenuTree godoc
@@ -41,18 +60,23 @@ func (a *MenuApi) InitMenuApi(router *gin.RouterGroup) {
 // @Produce json
 // @Security Bearer
 // @Param all query bool false "是否返回所有菜单（用于权限分配）"
+// @Param If-None-Match header string false "上次响应的 ETag"
 // @Success 200 {array} server_internal_module_system_model_response.MenuTreeResp
+// @Success 304 {string} string "菜单树未变化"
 // @Router /api/system/menu/tree [get]
 func (a *MenuApi) GetMenuTree(c *gin.Context) {
 	// 检查是否需要返回所有菜单（用于权限分配）
 	all := c.Query("all") == "true"
 
-	var tree []*modelResponse.MenuTreeResp
-	var err error
+	var (
+		tree json.RawMessage
+		etag string
+		err  error
+	)
 
 	if all {
 		// 返回所有菜单（不过滤权限）
-		tree, err = a.menuUsecase.GetAllMenuTree(c.Request.Context())
+		tree, etag, err = a.menuUsecase.GetAllMenuTree(c.Request.Context())
 	} else {
 		// 返回用户有权限的菜单
 		// 从 gin.Context 中获取 claims
@@ -68,14 +92,21 @@ func (a *MenuApi) GetMenuTree(c *gin.Context) {
 			ctx = context.WithValue(ctx, "userID", claims.GetUserID())
 		}
 
-		tree, err = a.menuUsecase.GetMenuTree(ctx)
+		tree, etag, err = a.menuUsecase.GetMenuTree(ctx)
 	}
 
 	if err != nil {
-		a.logger.Error("[MenuApi] GetMenuTree error", zap.Error(err))
+		a.logger.WithContext(c).Error("[MenuApi] GetMenuTree error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
+
+	// 菜单树未变化时返回 304，前端复用本地缓存
+	c.Header("ETag", etag)
+	if match := c.GetHeader("If-None-Match"); match != "" && match == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	response.SuccessWithData(c, tree)
 }
 
@@ -90,7 +121,7 @@ func (a *MenuApi) GetMenuTree(c *gin.Context) {
 func (a *MenuApi) List(c *gin.Context) {
 	menus, err := a.menuUsecase.List(c, nil)
 	if err != nil {
-		a.logger.Error("[MenuApi] List error", zap.Error(err))
+		a.logger.WithContext(c).Error("[MenuApi] List error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -108,12 +139,12 @@ func (a *MenuApi) List(c *gin.Context) {
 // @Router /api/system/menu [post]
 func (a *MenuApi) Create(c *gin.Context) {
 	var req model.Menu
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.menuUsecase.Create(c, &req); err != nil {
-		a.logger.Error("[MenuApi] Create error", zap.Error(err))
+		a.logger.WithContext(c).Error("[MenuApi] Create error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -131,12 +162,12 @@ func (a *MenuApi) Create(c *gin.Context) {
 // @Router /api/system/menu [put]
 func (a *MenuApi) Update(c *gin.Context) {
 	var req model.Menu
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.menuUsecase.Update(c, &req); err != nil {
-		a.logger.Error("[MenuApi] Update error", zap.Error(err))
+		a.logger.WithContext(c).Error("[MenuApi] Update error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -145,11 +176,13 @@ func (a *MenuApi) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary 删除菜单
+// @Description 存在子菜单时需指定 children：cascade 级联删除，reparent 子菜单挂到上级
 // @Tags 菜单管理
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path int true "菜单ID"
+// @Param children query string false "子菜单处理方式" Enums(cascade, reparent)
 // @Success 200 {string} string "success"
 // @Router /api/system/menu/{id} [delete]
 func (a *MenuApi) Delete(c *gin.Context) {
@@ -159,10 +192,246 @@ func (a *MenuApi) Delete(c *gin.Context) {
 		response.Fail(c, err)
 		return
 	}
-	if err := a.menuUsecase.Delete(c, id); err != nil {
-		a.logger.Error("[MenuApi] Delete error", zap.Error(err))
+	if err := a.menuUsecase.Delete(c, id, c.Query("children")); err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Delete error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// RecycleList godoc
+// @Summary 获取菜单回收站列表
+// @Tags 菜单管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param current query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param keyword query string false "关键字"
+// @Success 200 {object} server_internal_module_system_model_reply.PageReply
+// @Router /api/system/menu/recycle/list [get]
+func (a *MenuApi) RecycleList(c *gin.Context) {
+	var req request.RecycleListReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.menuUsecase.RecycleList(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] RecycleList error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Restore godoc
+// @Summary 从回收站恢复菜单
+// @Tags 菜单管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "菜单ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/menu/recycle/restore [put]
+func (a *MenuApi) Restore(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.menuUsecase.Restore(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Restore error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Purge godoc
+// @Summary 彻底删除回收站中的菜单
+// @Tags 菜单管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "菜单ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/menu/recycle/purge [delete]
+func (a *MenuApi) Purge(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.menuUsecase.Purge(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Purge error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
 	response.Success(c)
 }
+
+// GetButtonApis godoc
+// @Summary 获取按钮关联的接口
+// @Tags 菜单管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "按钮菜单ID"
+// @Success 200 {array} uint64 "接口ID列表"
+// @Router /api/system/menu/{id}/apis [get]
+func (a *MenuApi) GetButtonApis(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	apiIds, err := a.menuUsecase.GetButtonApis(c, id)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] GetButtonApis error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, apiIds)
+}
+
+// BindButtonApis godoc
+// @Summary 绑定按钮关联的接口
+// @Description 覆盖按钮关联的接口，已拥有该按钮的角色同步获得或失去对应接口权限
+// @Tags 菜单管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.BindMenuApisReq true "按钮ID和接口ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/menu/apis [put]
+func (a *MenuApi) BindButtonApis(c *gin.Context) {
+	var req request.BindMenuApisReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.menuUsecase.BindButtonApis(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] BindButtonApis error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Sort godoc
+// @Summary 菜单拖拽排序
+// @Description 批量更新菜单的上级与排序，拖拽后将受影响菜单的新位置一并提交
+// @Tags 菜单管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.MenuSortReq true "菜单新位置"
+// @Success 200 {string} string "success"
+// @Router /api/system/menu/sort [put]
+func (a *MenuApi) Sort(c *gin.Context) {
+	var req request.MenuSortReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.menuUsecase.Sort(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Sort error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Export godoc
+// @Summary 导出菜单
+// @Description 导出全部菜单、角色菜单关联及按钮关联接口，以菜单名称作为标识，用于在不同环境之间迁移
+// @Tags 菜单管理
+// @Produce json
+// @Produce application/x-yaml
+// @Security Bearer
+// @Param format query string false "文件格式：json（默认）或 yaml"
+// @Success 200 {object} request.MenuTransferDoc
+// @Router /api/system/menu/export [get]
+func (a *MenuApi) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", menuTransferJSON)
+	if format != menuTransferJSON && format != menuTransferYAML {
+		response.Fail(c, errorx.ErrInvalidParam)
+		return
+	}
+
+	doc, err := a.menuUsecase.Export(c)
+	if err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Export error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
+	var (
+		data        []byte
+		contentType string
+	)
+	if format == menuTransferYAML {
+		data, err = yaml.Marshal(doc)
+		contentType = "application/x-yaml"
+	} else {
+		data, err = json.MarshalIndent(doc, "", "  ")
+		contentType = "application/json"
+	}
+	if err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Export marshal error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=menus."+format)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// Import godoc
+// @Summary 导入菜单
+// @Description 按菜单名称新建或更新菜单，角色菜单关联只追加不回收。默认只返回变更预览，apply=true 时才写入
+// @Tags 菜单管理
+// @Accept json
+// @Accept application/x-yaml
+// @Produce json
+// @Security Bearer
+// @Param format query string false "文件格式：json（默认）或 yaml"
+// @Param apply query bool false "是否执行导入"
+// @Param body body request.MenuTransferDoc true "导出的菜单文件"
+// @Success 200 {object} server_internal_module_system_model_reply.MenuImportReply
+// @Router /api/system/menu/import [post]
+func (a *MenuApi) Import(c *gin.Context) {
+	format := c.DefaultQuery("format", menuTransferJSON)
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	var doc request.MenuTransferDoc
+	switch format {
+	case menuTransferJSON:
+		err = json.Unmarshal(body, &doc)
+	case menuTransferYAML:
+		err = yaml.Unmarshal(body, &doc)
+	default:
+		response.Fail(c, errorx.ErrInvalidParam)
+		return
+	}
+	if err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Import unmarshal error", zap.Error(err))
+		response.Fail(c, errorx.ErrMenuImportInvalid)
+		return
+	}
+
+	result, err := a.menuUsecase.Import(c, &doc, c.Query("apply") == "true")
+	if err != nil {
+		a.logger.WithContext(c).Error("[MenuApi] Import error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
@@ -6,6 +6,7 @@ import (
 	_ "server/internal/module/system/model/reply"
 	"server/internal/module/system/model/request"
 	"server/pkg/response"
+	"server/pkg/validatex"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -33,6 +34,16 @@ func (a *RoleApi) InitRoleApi(router *gin.RouterGroup) {
 	router.GET(":id/api-permissions", a.GetRoleApiPermissions)
 	router.POST("assign-menu-permissions", a.AssignMenuPermissions)
 	router.GET(":id/menu-permissions", a.GetRoleMenuPermissions)
+	router.GET("recycle/list", a.RecycleList)
+	router.PUT("recycle/restore", a.Restore)
+	router.DELETE("recycle/purge", a.Purge)
+	router.POST(":id/clone", a.Clone)
+	router.GET("diff", a.Diff)
+	router.GET("template/list", a.ListPermissionTemplates)
+	router.POST("template", a.CreatePermissionTemplate)
+	router.PUT("template", a.UpdatePermissionTemplate)
+	router.DELETE("template/:id", a.DeletePermissionTemplate)
+	router.POST("template/apply", a.ApplyPermissionTemplate)
 }
 
 // List godoc
@@ -48,13 +59,13 @@ func (a *RoleApi) InitRoleApi(router *gin.RouterGroup) {
 // @Router /api/system/role/list [get]
 func (a *RoleApi) List(c *gin.Context) {
 	var req request.RoleListReq
-	if err := c.ShouldBindQuery(&req); err != nil {
+	if err := validatex.BindQuery(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	result, err := a.roleUsecase.List(c, &req)
 	if err != nil {
-		a.logger.Error("[RoleApi] List error", zap.Error(err))
+		a.logger.WithContext(c).Error("[RoleApi] List error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -72,12 +83,12 @@ func (a *RoleApi) List(c *gin.Context) {
 // @Router /api/system/role [post]
 func (a *RoleApi) Create(c *gin.Context) {
 	var req request.CreateRoleReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.roleUsecase.Create(c, &req); err != nil {
-		a.logger.Error("[RoleApi] Create error", zap.Error(err))
+		a.logger.WithContext(c).Error("[RoleApi] Create error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -95,12 +106,12 @@ func (a *RoleApi) Create(c *gin.Context) {
 // @Router /api/system/role [put]
 func (a *RoleApi) Update(c *gin.Context) {
 	var req request.UpdateRoleReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.roleUsecase.Update(c, &req); err != nil {
-		a.logger.Error("[RoleApi] Update error", zap.Error(err))
+		a.logger.WithContext(c).Error("[RoleApi] Update error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -109,11 +120,13 @@ func (a *RoleApi) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary 删除角色
+// @Description 删除角色并清理其菜单、用户关联及 Casbin 策略；仍有用户持有该角色时需传 cascade=true
 // @Tags 角色管理
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path int true "角色ID"
+// @Param cascade query bool false "是否级联解除用户关联"
 // @Success 200 {string} string "success"
 // @Router /api/system/role/{id} [delete]
 func (a *RoleApi) Delete(c *gin.Context) {
@@ -123,8 +136,9 @@ func (a *RoleApi) Delete(c *gin.Context) {
 		response.Fail(c, err)
 		return
 	}
-	if err := a.roleUsecase.Delete(c, idInt); err != nil {
-		a.logger.Error("[RoleApi] Delete error", zap.Error(err))
+	cascade := c.Query("cascade") == "true"
+	if err := a.roleUsecase.Delete(c, idInt, cascade); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] Delete error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -143,12 +157,12 @@ func (a *RoleApi) Delete(c *gin.Context) {
 // @Router /api/system/role/assign-api-permissions [post]
 func (a *RoleApi) AssignApiPermissions(c *gin.Context) {
 	var req request.AssignApiPermissionsReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.roleUsecase.AssignApiPermissions(c, req.RoleId, req.ApiIds); err != nil {
-		a.logger.Error("[RoleApi] AssignApiPermissions error", zap.Error(err))
+		a.logger.WithContext(c).Error("[RoleApi] AssignApiPermissions error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -174,7 +188,7 @@ func (a *RoleApi) GetRoleApiPermissions(c *gin.Context) {
 	}
 	apiIds, err := a.roleUsecase.GetRoleApiPermissions(c, idInt)
 	if err != nil {
-		a.logger.Error("[RoleApi] GetRoleApiPermissions error", zap.Error(err))
+		a.logger.WithContext(c).Error("[RoleApi] GetRoleApiPermissions error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -193,12 +207,12 @@ func (a *RoleApi) GetRoleApiPermissions(c *gin.Context) {
 // @Router /api/system/role/assign-menu-permissions [post]
 func (a *RoleApi) AssignMenuPermissions(c *gin.Context) {
 	var req request.AssignMenuPermissionsReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.roleUsecase.AssignMenuPermissions(c, req.RoleId, req.MenuIds); err != nil {
-		a.logger.Error("[RoleApi] AssignMenuPermissions error", zap.Error(err))
+		a.logger.WithContext(c).Error("[RoleApi] AssignMenuPermissions error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -224,7 +238,7 @@ func (a *RoleApi) GetRoleMenuPermissions(c *gin.Context) {
 	}
 	menuIds, err := a.roleUsecase.GetRoleMenuPermissions(c, idInt)
 	if err != nil {
-		a.logger.Error("[RoleApi] GetRoleMenuPermissions error", zap.Error(err))
+		a.logger.WithContext(c).Error("[RoleApi] GetRoleMenuPermissions error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -233,3 +247,252 @@ func (a *RoleApi) GetRoleMenuPermissions(c *gin.Context) {
 		"menuIds": menuIds,
 	})
 }
+
+// RecycleList godoc
+// @Summary 获取角色回收站列表
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param current query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param keyword query string false "关键字"
+// @Success 200 {object} server_internal_module_system_model_reply.PageReply
+// @Router /api/system/role/recycle/list [get]
+func (a *RoleApi) RecycleList(c *gin.Context) {
+	var req request.RecycleListReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.roleUsecase.RecycleList(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] RecycleList error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Restore godoc
+// @Summary 从回收站恢复角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "角色ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/role/recycle/restore [put]
+func (a *RoleApi) Restore(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.roleUsecase.Restore(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] Restore error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Purge godoc
+// @Summary 彻底删除回收站中的角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "角色ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/role/recycle/purge [delete]
+func (a *RoleApi) Purge(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.roleUsecase.Purge(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] Purge error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Clone godoc
+// @Summary 克隆角色
+// @Description 以指定角色为源创建新角色，复制其API权限与菜单权限
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "源角色ID"
+// @Param body body request.CloneRoleReq true "新角色信息"
+// @Success 200 {string} string "success"
+// @Router /api/system/role/{id}/clone [post]
+func (a *RoleApi) Clone(c *gin.Context) {
+	id := c.Param("id")
+	idInt, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	var req request.CloneRoleReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.roleUsecase.Clone(c, idInt, &req); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] Clone error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Diff godoc
+// @Summary 对比角色权限
+// @Description 对比两个角色的有效API权限与菜单权限
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param sourceId query int true "源角色ID"
+// @Param targetId query int true "目标角色ID"
+// @Success 200 {object} server_internal_module_system_model_reply.RoleDiffReply
+// @Router /api/system/role/diff [get]
+func (a *RoleApi) Diff(c *gin.Context) {
+	var req request.RoleDiffReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.roleUsecase.Diff(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] Diff error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// ListPermissionTemplates godoc
+// @Summary 获取权限模板列表
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param current query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param name query string false "模板名称"
+// @Success 200 {object} server_internal_module_system_model_reply.PageReply
+// @Router /api/system/role/template/list [get]
+func (a *RoleApi) ListPermissionTemplates(c *gin.Context) {
+	var req request.PermissionTemplateListReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.roleUsecase.ListPermissionTemplates(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] ListPermissionTemplates error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// CreatePermissionTemplate godoc
+// @Summary 创建权限模板
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.CreatePermissionTemplateReq true "模板信息"
+// @Success 200 {string} string "success"
+// @Router /api/system/role/template [post]
+func (a *RoleApi) CreatePermissionTemplate(c *gin.Context) {
+	var req request.CreatePermissionTemplateReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.roleUsecase.CreatePermissionTemplate(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] CreatePermissionTemplate error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// UpdatePermissionTemplate godoc
+// @Summary 更新权限模板
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.UpdatePermissionTemplateReq true "模板信息"
+// @Success 200 {string} string "success"
+// @Router /api/system/role/template [put]
+func (a *RoleApi) UpdatePermissionTemplate(c *gin.Context) {
+	var req request.UpdatePermissionTemplateReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.roleUsecase.UpdatePermissionTemplate(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] UpdatePermissionTemplate error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// DeletePermissionTemplate godoc
+// @Summary 删除权限模板
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "模板ID"
+// @Success 200 {string} string "success"
+// @Router /api/system/role/template/{id} [delete]
+func (a *RoleApi) DeletePermissionTemplate(c *gin.Context) {
+	id := c.Param("id")
+	idInt, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.roleUsecase.DeletePermissionTemplate(c, idInt); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] DeletePermissionTemplate error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// ApplyPermissionTemplate godoc
+// @Summary 应用权限模板
+// @Description 将模板中的API与菜单权限应用到角色，merge=true 时与角色现有权限合并，否则覆盖
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.ApplyPermissionTemplateReq true "模板ID和角色ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/role/template/apply [post]
+func (a *RoleApi) ApplyPermissionTemplate(c *gin.Context) {
+	var req request.ApplyPermissionTemplateReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.roleUsecase.ApplyPermissionTemplate(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[RoleApi] ApplyPermissionTemplate error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
@@ -9,6 +9,7 @@ import (
 	"server/pkg"
 	"server/pkg/errorx"
 	"server/pkg/response"
+	"server/pkg/validatex"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -33,6 +34,9 @@ func (a *UserApi) InitUserApi(router *gin.RouterGroup) {
 	router.GET("list", a.List)
 	router.POST("", a.Create)
 	router.DELETE("", a.Delete)
+	router.GET("recycle/list", a.RecycleList)
+	router.PUT("recycle/restore", a.Restore)
+	router.DELETE("recycle/purge", a.Purge)
 }
 
 // Info godoc
@@ -52,7 +56,7 @@ func (a *UserApi) Info(c *gin.Context) {
 
 	userInfo, err := a.userUsecase.GetInfo(c, int(userId))
 	if err != nil {
-		a.logger.Error("[UserApi] GetUserInfo error", zap.Any("userId", userId), zap.Error(err))
+		a.logger.WithContext(c).Error("[UserApi] GetUserInfo error", zap.Any("userId", userId), zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -74,13 +78,13 @@ func (a *UserApi) Info(c *gin.Context) {
 // @Router /api/system/user/list [get]
 func (a *UserApi) List(c *gin.Context) {
 	var req request.UserListReq
-	if err := c.ShouldBindQuery(&req); err != nil {
+	if err := validatex.BindQuery(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	result, err := a.userUsecase.List(c, &req)
 	if err != nil {
-		a.logger.Error("[UserApi] List error", zap.Error(err))
+		a.logger.WithContext(c).Error("[UserApi] List error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -98,12 +102,12 @@ func (a *UserApi) List(c *gin.Context) {
 // @Router /api/system/user [post]
 func (a *UserApi) Create(c *gin.Context) {
 	var req request.CreateUserReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.userUsecase.Create(c, &req); err != nil {
-		a.logger.Error("[UserApi] Create error", zap.Error(err))
+		a.logger.WithContext(c).Error("[UserApi] Create error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -121,12 +125,84 @@ func (a *UserApi) Create(c *gin.Context) {
 // @Router /api/system/user [delete]
 func (a *UserApi) Delete(c *gin.Context) {
 	var req request.DeleteUserReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := validatex.BindJSON(c, &req); err != nil {
 		response.Fail(c, err)
 		return
 	}
 	if err := a.userUsecase.Delete(c, &req); err != nil {
-		a.logger.Error("[UserApi] Delete error", zap.Error(err))
+		a.logger.WithContext(c).Error("[UserApi] Delete error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// RecycleList godoc
+// @Summary 获取用户回收站列表
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param current query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Param keyword query string false "关键字"
+// @Success 200 {object} server_internal_module_system_model_reply.PageReply
+// @Router /api/system/user/recycle/list [get]
+func (a *UserApi) RecycleList(c *gin.Context) {
+	var req request.RecycleListReq
+	if err := validatex.BindQuery(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	result, err := a.userUsecase.RecycleList(c, &req)
+	if err != nil {
+		a.logger.WithContext(c).Error("[UserApi] RecycleList error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.SuccessWithData(c, result)
+}
+
+// Restore godoc
+// @Summary 从回收站恢复用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "用户ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/user/recycle/restore [put]
+func (a *UserApi) Restore(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.userUsecase.Restore(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] Restore error", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// Purge godoc
+// @Summary 彻底删除回收站中的用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param body body request.RecycleIdsReq true "用户ID列表"
+// @Success 200 {string} string "success"
+// @Router /api/system/user/recycle/purge [delete]
+func (a *UserApi) Purge(c *gin.Context) {
+	var req request.RecycleIdsReq
+	if err := validatex.BindJSON(c, &req); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if err := a.userUsecase.Purge(c, &req); err != nil {
+		a.logger.WithContext(c).Error("[UserApi] Purge error", zap.Error(err))
 		response.Fail(c, err)
 		return
 	}
@@ -7,20 +7,55 @@ import (
 	"server/internal/module/system/model"
 	"server/internal/module/system/model/reply"
 	"server/internal/module/system/model/request"
+	"server/pkg/errorx"
+
+	"go.uber.org/zap"
 )
 
 type ApiUsecase struct {
-	logger  logger.Logger
-	apiRepo repo.ApiRepo
+	logger        logger.Logger
+	transaction   repo.Transaction
+	apiRepo       repo.ApiRepo
+	casbinUsecase casbinUsecase
+	policy        *rolePolicy
 }
 
 func NewApiUsecase(
 	logger logger.Logger,
+	transaction repo.Transaction,
 	apiRepo repo.ApiRepo,
+	roleRepo repo.RoleRepo,
+	roleApiRepo repo.RoleApiRepo,
+	roleMenuRepo repo.RoleMenuRepo,
+	menuRepo repo.MenuRepo,
+	menuApiRepo repo.MenuApiRepo,
+	casbinRepo repo.CasbinRepo,
+	casbinUsecase casbinUsecase,
 ) *ApiUsecase {
 	return &ApiUsecase{
-		logger:  logger,
-		apiRepo: apiRepo,
+		logger:        logger,
+		transaction:   transaction,
+		apiRepo:       apiRepo,
+		casbinUsecase: casbinUsecase,
+		policy: &rolePolicy{
+			roleRepo:     roleRepo,
+			menuRepo:     menuRepo,
+			menuApiRepo:  menuApiRepo,
+			apiRepo:      apiRepo,
+			roleApiRepo:  roleApiRepo,
+			roleMenuRepo: roleMenuRepo,
+			casbinRepo:   casbinRepo,
+		},
+	}
+}
+
+// reloadPolicy 接口变化影响了角色的 Casbin 策略时，事务提交后同步内存中的策略
+func (u ApiUsecase) reloadPolicy(ctx context.Context, affected int) {
+	if affected == 0 {
+		return
+	}
+	if err := u.casbinUsecase.LoadPolicy(); err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] casbinUsecase.LoadPolicy error", zap.Error(err))
 	}
 }
 
@@ -31,26 +66,111 @@ func (u ApiUsecase) Create(ctx context.Context, req *request.CreateApiReq) error
 		Method:      req.Method,
 		Description: req.Description,
 		Group:       req.Group,
-		Status:      1,
+		Status:      model.ApiStatusEnable,
 	}
-	return u.apiRepo.Create(ctx, api)
+	if err := u.checkPathMethod(ctx, api); err != nil {
+		return err
+	}
+	if err := u.apiRepo.Create(ctx, api); err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.Create error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	return nil
 }
 
+// Delete 软删除接口，同一事务内重新生成直接分配或通过按钮持有该接口的角色的策略
 func (u ApiUsecase) Delete(ctx context.Context, req *request.DeleteApiReq) error {
-	return u.apiRepo.Delete(ctx, req.ID)
+	api, err := u.find(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	var affected int
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		affected, err = u.policy.syncByApis(ctx, []uint64{api.ID}, func(ctx context.Context) error {
+			return u.apiRepo.Delete(ctx, req.ID)
+		})
+		return err
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] delete api error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	return nil
 }
 
+// Update 修改接口，path、method 或状态变化时同一事务内重新生成持有该接口的角色的策略
 func (u ApiUsecase) Update(ctx context.Context, req *request.UpdateApiReq) error {
-	api := &model.Api{
-		BaseModel:   model.BaseModel{ID: uint64(req.ID)},
-		Name:        req.Name,
-		Path:        req.Path,
-		Method:      req.Method,
-		Description: req.Description,
-		Group:       req.Group,
-		Status:      req.Status,
+	api, err := u.find(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	policyChanged := api.Path != req.Path || api.Method != req.Method || api.Status != req.Status
+
+	api.Name = req.Name
+	api.Path = req.Path
+	api.Method = req.Method
+	api.Description = req.Description
+	api.Group = req.Group
+	api.Status = req.Status
+	if err := u.checkPathMethod(ctx, api); err != nil {
+		return err
+	}
+
+	if !policyChanged {
+		if err := u.apiRepo.Update(ctx, api); err != nil {
+			u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.Update error", zap.Any("req", req), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		return nil
+	}
+
+	var affected int
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		affected, err = u.policy.syncByApis(ctx, []uint64{api.ID}, func(ctx context.Context) error {
+			return u.apiRepo.Update(ctx, api)
+		})
+		return err
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] update api error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	return nil
+}
+
+// find 查询未删除的接口，不存在时返回 ErrApiNotFound
+func (u ApiUsecase) find(ctx context.Context, id int64) (*model.Api, error) {
+	api, err := u.apiRepo.Find(ctx, id)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.Find error", zap.Int64("id", id), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if api == nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] api not found", zap.Int64("id", id))
+		return nil, errorx.ErrApiNotFound
 	}
-	return u.apiRepo.Update(ctx, api)
+	return api, nil
+}
+
+// checkPathMethod 相同 path + method 已被其他接口使用时返回 ErrApiAlreadyExists
+func (u ApiUsecase) checkPathMethod(ctx context.Context, api *model.Api) error {
+	exists, err := u.apiRepo.FindByPathMethods(ctx, apiPathMethods([]*model.Api{api}))
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.FindByPathMethods error", zap.String("path", api.Path), zap.String("method", api.Method), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	for _, exist := range exists {
+		if exist.ID != api.ID {
+			u.logger.WithContext(ctx).Warn("[ApiUsecase] api already exists", zap.String("path", api.Path), zap.String("method", api.Method))
+			return errorx.ErrApiAlreadyExists
+		}
+	}
+	return nil
 }
 
 func (u ApiUsecase) Get(ctx context.Context, req *request.GetApiReq) (*reply.GetApiReply, error) {
@@ -75,3 +195,104 @@ func (u ApiUsecase) List(ctx context.Context, req *request.ApiListReq) (*reply.L
 	}
 	return reply.BuilderListApiReply(list, total, page, pageSize), nil
 }
+
+// RecycleList 回收站中的接口列表
+func (u ApiUsecase) RecycleList(ctx context.Context, req *request.RecycleListReq) (*reply.PageReply, error) {
+	apis, total, err := u.apiRepo.ListDeleted(ctx, req)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.ListDeleted error", zap.Any("req", req), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	return reply.BuilderPageReply(reply.BuilderApiRecycleList(apis), total, offset, limit), nil
+}
+
+// Restore 从回收站恢复接口，相同 path + method 已存在时拒绝恢复
+func (u ApiUsecase) Restore(ctx context.Context, req *request.RecycleIdsReq) error {
+	apis, err := u.findDeleted(ctx, req.Ids)
+	if err != nil {
+		return err
+	}
+
+	pathMethods := make([]struct {
+		Path   string
+		Method string
+	}, 0, len(apis))
+	seen := make(map[string]struct{}, len(apis))
+	for _, api := range apis {
+		key := api.Method + " " + api.Path
+		if _, ok := seen[key]; ok {
+			return errorx.ErrApiAlreadyExists
+		}
+		seen[key] = struct{}{}
+		pathMethods = append(pathMethods, struct {
+			Path   string
+			Method string
+		}{Path: api.Path, Method: api.Method})
+	}
+
+	exists, err := u.apiRepo.FindByPathMethods(ctx, pathMethods)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.FindByPathMethods error", zap.Any("pathMethods", pathMethods), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	if len(exists) > 0 {
+		u.logger.WithContext(ctx).Warn("[ApiUsecase] restore api conflict", zap.Any("exists", exists))
+		return errorx.ErrApiAlreadyExists
+	}
+
+	// 软删除保留了角色与按钮的接口关联，恢复后这些角色重新获得授权
+	var affected int
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		affected, err = u.policy.syncByApis(ctx, toUint64s(req.Ids), func(ctx context.Context) error {
+			return u.apiRepo.Restore(ctx, req.Ids)
+		})
+		return err
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] restore api error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	return nil
+}
+
+// Purge 彻底删除回收站中的接口，关联清理后重新生成原先持有这些接口的角色的策略
+func (u ApiUsecase) Purge(ctx context.Context, req *request.RecycleIdsReq) error {
+	if _, err := u.findDeleted(ctx, req.Ids); err != nil {
+		return err
+	}
+
+	var affected int
+	err := u.transaction.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		affected, err = u.policy.syncByApis(ctx, toUint64s(req.Ids), func(ctx context.Context) error {
+			return u.apiRepo.Purge(ctx, req.Ids)
+		})
+		return err
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.Purge error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	return nil
+}
+
+func (u ApiUsecase) findDeleted(ctx context.Context, ids []int64) ([]*model.Api, error) {
+	ids = uniqueInt64s(ids)
+	if len(ids) == 0 {
+		return nil, errorx.ErrInvalidParam
+	}
+	apis, err := u.apiRepo.FindDeletedByIds(ctx, ids)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] apiRepo.FindDeletedByIds error", zap.Any("ids", ids), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if len(apis) < len(ids) {
+		u.logger.WithContext(ctx).Error("[ApiUsecase] deleted apis not found", zap.Any("ids", ids))
+		return nil, errorx.ErrApiNotFound
+	}
+	return apis, nil
+}
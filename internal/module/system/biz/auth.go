@@ -61,21 +61,21 @@ func (u *AuthUsecase) Login(ctx context.Context, req *request.LoginReq, ip strin
 func (u *AuthUsecase) Register(ctx context.Context, req *request.RegisterReq) error {
 	user, err := u.userRepo.FindByPhone(ctx, req.Phone)
 	if err != nil {
-		u.logger.Error("[AuthUsecase] userRepo.FindByUsername error", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[AuthUsecase] userRepo.FindByUsername error", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrInternal
 	}
 	if user != nil {
-		u.logger.Error("[AuthUsecase] userRepo.FindByUsername user exist", zap.Any("req", req))
+		u.logger.WithContext(ctx).Error("[AuthUsecase] userRepo.FindByUsername user exist", zap.Any("req", req))
 		return errorx.ErrUserConflict
 	}
 
 	role, err := u.roleRepo.FindByKey(ctx, model.RoleKeyUser)
 	if err != nil {
-		u.logger.Error("[AuthUsecase] roleRepo.FindByKey error", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[AuthUsecase] roleRepo.FindByKey error", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrInternal
 	}
 	if role == nil {
-		u.logger.Error("[AuthUsecase] roleRepo.FindByKey role not found", zap.Any("req", req))
+		u.logger.WithContext(ctx).Error("[AuthUsecase] roleRepo.FindByKey role not found", zap.Any("req", req))
 		return errorx.ErrRoleNotFound
 	}
 
@@ -93,7 +93,7 @@ func (u *AuthUsecase) Register(ctx context.Context, req *request.RegisterReq) er
 	}
 
 	if err := u.userRepo.Create(ctx, createUser); err != nil {
-		u.logger.Error("[AuthUsecase] userRepo.Create error", zap.Any("user", createUser), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[AuthUsecase] userRepo.Create error", zap.Any("user", createUser), zap.Error(err))
 		return errorx.ErrInternal
 	}
 
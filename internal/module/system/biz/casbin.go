@@ -29,6 +29,7 @@ type (
 		AddPolicies([][]string) (bool, error)
 		BatchAddPolicies([][]string) (bool, error)
 		GetPermissionsForRole(role string) ([][]string, error)
+		LoadPolicy() error
 	}
 )
 
@@ -192,3 +193,34 @@ func (u *CasbinUsecase) AddPolicies(policies [][]string) (bool, error) {
 func (u *CasbinUsecase) GetPermissionsForRole(role string) ([][]string, error) {
 	return u.enforcer.GetFilteredPolicy(0, role)
 }
+
+// LoadPolicy 从数据库重新加载策略，用于直接修改 casbin_rule 表后同步内存中的 Enforcer
+func (u *CasbinUsecase) LoadPolicy() error {
+	if err := u.enforcer.LoadPolicy(); err != nil {
+		u.logger.Error("重新加载策略失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DumpPolicies 导出 Enforcer 中当前生效的策略，每条以 ptype 开头，比如 [p admin /api/system/user GET]。
+// p 策略由角色直接分配的接口和按钮绑定的接口派生，导出的是派生结果而非授权来源，直接修改 casbin_rule 会在下次同步时被覆盖
+func (u *CasbinUsecase) DumpPolicies() ([][]string, error) {
+	policies, err := u.enforcer.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+	groupings, err := u.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]string, 0, len(policies)+len(groupings))
+	for _, policy := range policies {
+		result = append(result, append([]string{"p"}, policy...))
+	}
+	for _, grouping := range groupings {
+		result = append(result, append([]string{"g"}, grouping...))
+	}
+	return result, nil
+}
@@ -3,6 +3,7 @@ package biz
 import (
 	"context"
 	"server/internal/core/logger"
+	"server/internal/core/migrate"
 	"server/internal/module/system/biz/repo"
 	"server/internal/module/system/model"
 	"server/pkg"
@@ -14,6 +15,8 @@ import (
 	"gorm.io/gorm/schema"
 )
 
+const initModule = "system"
+
 type InitUsecase struct {
 	logger        logger.Logger
 	initRepo      repo.InitRepo
@@ -21,8 +24,12 @@ type InitUsecase struct {
 	roleRepo      repo.RoleRepo
 	menuRepo      repo.MenuRepo
 	apiRepo       repo.ApiRepo
+	menuApiRepo   repo.MenuApiRepo
+	roleApiRepo   repo.RoleApiRepo
 	roleMenuRepo  repo.RoleMenuRepo
+	casbinRepo    repo.CasbinRepo
 	casbinUsecase casbinUsecase
+	menuTreeCache menuTreeCache
 }
 
 func NewInitUsecase(
@@ -32,8 +39,12 @@ func NewInitUsecase(
 	roleRepo repo.RoleRepo,
 	menuRepo repo.MenuRepo,
 	apiRepo repo.ApiRepo,
+	menuApiRepo repo.MenuApiRepo,
+	roleApiRepo repo.RoleApiRepo,
 	roleMenuRepo repo.RoleMenuRepo,
+	casbinRepo repo.CasbinRepo,
 	casbinUsecase casbinUsecase,
+	menuTreeCache menuTreeCache,
 ) *InitUsecase {
 	return &InitUsecase{
 		logger:        logger,
@@ -42,137 +53,206 @@ func NewInitUsecase(
 		roleRepo:      roleRepo,
 		menuRepo:      menuRepo,
 		apiRepo:       apiRepo,
+		menuApiRepo:   menuApiRepo,
+		roleApiRepo:   roleApiRepo,
 		roleMenuRepo:  roleMenuRepo,
+		casbinRepo:    casbinRepo,
 		casbinUsecase: casbinUsecase,
+		menuTreeCache: menuTreeCache,
 	}
 }
 
-func (u *InitUsecase) InitIfNeeded() error {
-	if err := u.initRepo.AutoMigrate([]schema.Tabler{
-		&model.Init{}, &model.Role{}, &model.User{}, &model.Menu{}, &model.Api{}, &model.RoleMenu{},
-	}); err != nil {
-		u.logger.Error("[InitUsecase] failed to initialize database table structure", zap.Any("err", err))
-		return err
+// Migrations 系统模块的迁移。已发布的版本迁移不能再修改，新增种子数据（接口、菜单等）请追加新版本
+func (u *InitUsecase) Migrations() []*migrate.Migration {
+	return []*migrate.Migration{
+		{
+			Module: initModule, Name: "schema", Repeatable: true,
+			Description: "同步系统表结构",
+			Source:      migrate.SchemaSource(systemTables...),
+			Up:          u.SyncSchema,
+		},
+		{
+			Module: initModule, Version: "v1.0.0", Name: "role", Legacy: model.InitNameRole,
+			Description: "初始化超级管理员角色",
+			Source:      adminRole,
+			Up:          u.RoleInitialize,
+		},
+		{
+			Module: initModule, Version: "v1.0.0", Name: "user", Legacy: model.InitNameUser,
+			Description: "初始化超级管理员用户",
+			Source:      adminUser,
+			Up:          u.UserInitialize,
+		},
+		{
+			Module: initModule, Version: "v1.0.0", Name: "menu", Legacy: model.InitNameMenu,
+			Description: "初始化菜单",
+			Source:      v100Menus,
+			Up:          u.MenuInitialize,
+		},
+		{
+			Module: initModule, Version: "v1.0.0", Name: "api", Legacy: model.InitNameApi,
+			Description: "初始化管理员 api",
+			Source:      v100Apis,
+			Up:          u.ApiInitialize,
+		},
+		{
+			Module: initModule, Version: "v1.0.0", Name: "casbin", Legacy: model.InitNameCasbin,
+			Description: "初始化超级管理员权限",
+			Source:      v100Policies,
+			Up:          u.CasbinInitialize,
+		},
+		{
+			Module: initModule, Version: "v1.0.0", Name: "role_menu", Legacy: model.InitNameRoleMenu,
+			Description: "初始化超级管理员菜单权限",
+			Source:      v100RoleMenuIds,
+			Up:          u.RoleMenuInitialize,
+		},
+		{
+			Module: initModule, Version: "v1.1.0", Name: "api",
+			Description: "新增回收站、权限模板、按钮接口、菜单排序及导入导出接口",
+			Source:      v110Apis,
+			Up:          u.seedAdminApis(v110Apis),
+			Down:        u.removeAdminApis(v110Apis),
+		},
+		{
+			Module: initModule, Version: "v1.1.0", Name: "menu_type",
+			Description: "将内置目录菜单的类型改为 dir",
+			Source:      v110DirMenus,
+			Up:          u.setMenuType(v110DirMenus, model.MenuTypePage, model.MenuTypeDir),
+			Down:        u.setMenuType(v110DirMenus, model.MenuTypeDir, model.MenuTypePage),
+		},
+		{
+			Module: initModule, Version: "v1.2.0", Name: "api",
+			Description: "新增 IM 内容审核管理接口",
+			Source:      v120Apis,
+			Up:          u.seedAdminApis(v120Apis),
+			Down:        u.removeAdminApis(v120Apis),
+		},
+		{
+			Module: initModule, Version: "v1.3.0", Name: "role_api",
+			Description: "按现有策略回填角色直接分配的接口",
+			Up:          u.backfillRoleApis,
+		},
 	}
+}
 
-	initSteps := []struct {
-		name    string
-		check   func() bool
-		execute func() error
-	}{
-		{"role", u.RoleIsInitialized, u.RoleInitialize},
-		{"user", u.UserIsInitialized, u.UserInitialize},
-		{"menu", u.MenuIsInitialized, u.MenuInitialize},
-		{"api", u.ApiIsInitialized, u.ApiInitialize},
-		{"casbin", u.CasbinIsInitialized, u.CasbinInitialize},
-		{"role_menu", u.RoleMenuIsInitialized, u.RoleMenuInitialize},
-	}
-
-	for _, step := range initSteps {
-		if !step.check() {
-			if err := step.execute(); err != nil {
-				u.logger.Error("[InitUsecase] system initialize "+step.name+" fail", zap.Any("err", err))
-				return err
-			}
-		}
-		u.logger.Info("[InitUsecase] " + step.name + " initialized")
+// AfterMigrate 迁移直接写入数据库，完成后刷新内存中的 Casbin 策略和菜单树缓存
+func (u *InitUsecase) AfterMigrate(ctx context.Context) error {
+	if err := u.casbinUsecase.LoadPolicy(); err != nil {
+		u.logger.WithContext(ctx).Error("[InitUsecase] casbinUsecase.LoadPolicy error", zap.Error(err))
+		return err
 	}
+	u.menuTreeCache.InvalidateMenuTree(ctx)
 	return nil
 }
 
-func (u *InitUsecase) isInitialized(name string) bool {
-	initialized, err := u.initRepo.IsInitialized(name)
-	if err != nil {
-		u.logger.Error("[InitUsecase] find system "+name+" initialize flag fail", zap.Any("err", err))
-		return false
-	}
-	return initialized
+var systemTables = []schema.Tabler{
+	&model.Init{}, &model.Role{}, &model.User{}, &model.Menu{}, &model.Api{}, &model.RoleMenu{}, &model.PermissionTemplate{}, &model.MenuApi{}, &model.RoleApi{},
 }
 
-func (u *InitUsecase) RoleIsInitialized() bool   { return u.isInitialized(model.InitNameRole) }
-func (u *InitUsecase) UserIsInitialized() bool   { return u.isInitialized(model.InitNameUser) }
-func (u *InitUsecase) MenuIsInitialized() bool   { return u.isInitialized(model.InitNameMenu) }
-func (u *InitUsecase) ApiIsInitialized() bool    { return u.isInitialized(model.InitNameApi) }
-func (u *InitUsecase) CasbinIsInitialized() bool { return u.isInitialized(model.InitNameCasbin) }
-func (u *InitUsecase) RoleMenuIsInitialized() bool {
-	return u.isInitialized(model.InitNameRoleMenu)
+// Seed 补齐内置接口及超级管理员的接口权限，已存在的接口保持不变，用于误删后恢复
+func (u *InitUsecase) Seed(ctx context.Context) error {
+	if err := u.seedAdminApis(u.allApis()...)(ctx); err != nil {
+		u.logger.WithContext(ctx).Error("[InitUsecase] seed admin apis fail", zap.Error(err))
+		return err
+	}
+	return u.AfterMigrate(ctx)
 }
 
-func (u *InitUsecase) RoleInitialize() error {
-	role := &model.Role{
-		BaseModel: model.BaseModel{ID: 1},
-		Name:      "超级管理员",
-		Key:       model.RoleKeyAdmin,
-		Status:    model.RoleStatusEnable,
-		DataScope: model.RoleDataScopeAll,
-		Sort:      1,
-		IsSystem:  model.RoleIsSystem,
-		Remark:    "系统初始化超级管理员",
-	}
-	if err := u.roleRepo.Create(context.Background(), role); err != nil {
-		return err
+// allApis 各版本迁移中的内置接口，从 Migrations 中收集，新版本追加的接口无需另外登记
+func (u *InitUsecase) allApis() [][]*apiSeed {
+	var result [][]*apiSeed
+	for _, migration := range u.Migrations() {
+		if apis, ok := migration.Source.([]*apiSeed); ok {
+			result = append(result, apis)
+		}
 	}
-	return u.initRepo.SetInitialized(model.InitNameRole, "v1.0.0", "初始化超级管理员角色")
+	return result
 }
 
-func (u *InitUsecase) UserInitialize() error {
-	role, err := u.roleRepo.FindByKey(context.Background(), model.RoleKeyAdmin)
-	if err != nil || role == nil {
-		return errorx.ErrAdminRoleNotFound
+// SyncSchema 同步表结构，模型变化后自动重新执行
+func (u *InitUsecase) SyncSchema(ctx context.Context) error {
+	if err := u.dropLegacyUniqueIndexes(); err != nil {
+		u.logger.WithContext(ctx).Error("[InitUsecase] failed to drop legacy unique indexes", zap.Any("err", err))
+		return err
 	}
 
-	now := time.Now()
+	if err := u.initRepo.AutoMigrate(systemTables); err != nil {
+		u.logger.WithContext(ctx).Error("[InitUsecase] failed to initialize database table structure", zap.Any("err", err))
+		return err
+	}
 
-	user := &model.User{
-		BaseModel:   model.BaseModel{ID: 1},
-		Username:    "admin",
-		Password:    pkg.HashPassword("123456"),
-		Nickname:    "系统管理员",
-		Email:       "202000000@qq.com",
-		Phone:       "15599999999",
-		Gender:      model.UserGenderMale,
-		Status:      model.UserStatusEnable,
-		IsAdmin:     model.UserIsSystem,
-		Province:    "四川省",
-		City:        "成都市",
-		District:    "xxx",
-		Address:     "四川省成都市xxx",
-		Position:    "后端开发工程师",
-		Department:  "开发部",
-		JobTitle:    "开发经理",
-		Tags:        strings.Join([]string{"天然呆", "懒癌患者"}, ","),
-		Roles:       []*model.Role{role},
-		LastLoginAt: &now,
-		LastLoginIP: "",
-	}
-	if err := u.userRepo.Create(context.Background(), user); err != nil {
+	if err := u.initRepo.BackfillDeleteMark([]schema.Tabler{
+		&model.Role{}, &model.User{}, &model.Menu{}, &model.Api{},
+	}); err != nil {
+		u.logger.WithContext(ctx).Error("[InitUsecase] failed to backfill delete mark", zap.Any("err", err))
 		return err
 	}
-	return u.initRepo.SetInitialized(model.InitNameUser, "v1.0.0", "初始化超级管理员用户")
+	return nil
 }
 
-func (u *InitUsecase) MenuInitialize() error {
-	menus := []*model.Menu{
-		{BaseModel: model.BaseModel{ID: 1}, ParentID: 0, Name: "Dashboard", Title: "仪表盘", Path: "/dashboard", Component: "/index/index", Roles: model.RoleKeyAdmin, Icon: "ri:pie-chart-line", Sort: 1, Status: 1, KeepAlive: 1},
-		{BaseModel: model.BaseModel{ID: 2}, ParentID: 1, Name: "Console", Title: "工作台", Path: "dashboard/console", Component: "/dashboard/console", Roles: model.RoleKeyAdmin, Icon: "ri:home-smile-2-line", Sort: 1, Status: 1, KeepAlive: 1},
-		{BaseModel: model.BaseModel{ID: 3}, ParentID: 0, Name: "System", Title: "系统管理", Path: "/system", Component: "/index/index", Roles: model.RoleKeyAdmin, Icon: "ri:user-3-line", Sort: 2, Status: 1, KeepAlive: 1},
-		{BaseModel: model.BaseModel{ID: 4}, ParentID: 3, Name: "User", Title: "用户管理", Path: "system/user", Component: "/system/user", Roles: model.RoleKeyAdmin, Icon: "ri:user-line", Sort: 1, Status: 1, KeepAlive: 1},
-		{BaseModel: model.BaseModel{ID: 5}, ParentID: 3, Name: "Role", Title: "角色管理", Path: "system/role", Component: "/system/role", Roles: model.RoleKeyAdmin, Icon: "ri:user-settings-line", Sort: 2, Status: 1, KeepAlive: 1},
-		{BaseModel: model.BaseModel{ID: 6}, ParentID: 3, Name: "Menu", Title: "菜单管理", Path: "system/menu", Component: "/system/menu", Roles: model.RoleKeyAdmin, Icon: "ri:menu-line", Sort: 3, Status: 1, KeepAlive: 1},
-		{BaseModel: model.BaseModel{ID: 7}, ParentID: 3, Name: "Api", Title: "接口管理", Path: "system/api", Component: "/system/api", Roles: model.RoleKeyAdmin, Icon: "ri:api-line", Sort: 4, Status: 1, KeepAlive: 1},
+// dropLegacyUniqueIndexes 删除不含 delete_mark 的旧唯一索引，否则已软删除的记录仍会占用唯一值
+func (u *InitUsecase) dropLegacyUniqueIndexes() error {
+	legacy := []struct {
+		table   schema.Tabler
+		indexes []string
+	}{
+		{&model.User{}, []string{"idx_sys_user_username", "idx_sys_user_phone"}},
+		{&model.Role{}, []string{"idx_sys_role_key"}},
+		{&model.Api{}, []string{"uk_path_method"}},
+		{&model.Menu{}, []string{"uni_sys_menu_name", "name"}},
 	}
-
-	for _, menu := range menus {
-		if err := u.menuRepo.Create(context.Background(), menu); err != nil {
+	for _, item := range legacy {
+		if err := u.initRepo.DropIndexes(item.table, item.indexes...); err != nil {
 			return err
 		}
 	}
-
-	return u.initRepo.SetInitialized(model.InitNameMenu, "v1.0.0", "初始化菜单")
+	return nil
 }
 
-func (u *InitUsecase) ApiInitialize() error {
-	apis := []*model.Api{
+var (
+	adminRole = &roleSeed{
+		ID:        1,
+		Name:      "超级管理员",
+		Key:       model.RoleKeyAdmin,
+		Status:    model.RoleStatusEnable,
+		DataScope: model.RoleDataScopeAll,
+		Sort:      1,
+		IsSystem:  model.RoleIsSystem,
+		Remark:    "系统初始化超级管理员",
+	}
+
+	// adminUser 密码和最后登录时间在执行时填充，不参与校验和计算
+	adminUser = &userSeed{
+		ID:         1,
+		Username:   "admin",
+		Nickname:   "系统管理员",
+		Email:      "202000000@qq.com",
+		Phone:      "15599999999",
+		Gender:     model.UserGenderMale,
+		Status:     model.UserStatusEnable,
+		IsAdmin:    model.UserIsSystem,
+		Province:   "四川省",
+		City:       "成都市",
+		District:   "xxx",
+		Address:    "四川省成都市xxx",
+		Position:   "后端开发工程师",
+		Department: "开发部",
+		JobTitle:   "开发经理",
+		Tags:       strings.Join([]string{"天然呆", "懒癌患者"}, ","),
+	}
+
+	v100Menus = []*menuSeed{
+		{ID: 1, ParentID: 0, Type: model.MenuTypeDir, Name: "Dashboard", Title: "仪表盘", Path: "/dashboard", Component: "/index/index", Roles: model.RoleKeyAdmin, Icon: "ri:pie-chart-line", Sort: 1, Status: 1, KeepAlive: 1},
+		{ID: 2, ParentID: 1, Name: "Console", Title: "工作台", Path: "dashboard/console", Component: "/dashboard/console", Roles: model.RoleKeyAdmin, Icon: "ri:home-smile-2-line", Sort: 1, Status: 1, KeepAlive: 1},
+		{ID: 3, ParentID: 0, Type: model.MenuTypeDir, Name: "System", Title: "系统管理", Path: "/system", Component: "/index/index", Roles: model.RoleKeyAdmin, Icon: "ri:user-3-line", Sort: 2, Status: 1, KeepAlive: 1},
+		{ID: 4, ParentID: 3, Name: "User", Title: "用户管理", Path: "system/user", Component: "/system/user", Roles: model.RoleKeyAdmin, Icon: "ri:user-line", Sort: 1, Status: 1, KeepAlive: 1},
+		{ID: 5, ParentID: 3, Name: "Role", Title: "角色管理", Path: "system/role", Component: "/system/role", Roles: model.RoleKeyAdmin, Icon: "ri:user-settings-line", Sort: 2, Status: 1, KeepAlive: 1},
+		{ID: 6, ParentID: 3, Name: "Menu", Title: "菜单管理", Path: "system/menu", Component: "/system/menu", Roles: model.RoleKeyAdmin, Icon: "ri:menu-line", Sort: 3, Status: 1, KeepAlive: 1},
+		{ID: 7, ParentID: 3, Name: "Api", Title: "接口管理", Path: "system/api", Component: "/system/api", Roles: model.RoleKeyAdmin, Icon: "ri:api-line", Sort: 4, Status: 1, KeepAlive: 1},
+	}
+
+	v100Apis = []*apiSeed{
 		{Name: "SystemUserInfo", Path: "/api/system/user/info", Method: "GET", Description: "获取用户信息", Group: "user", Status: 1},
 		{Name: "SystemUserList", Path: "/api/system/user/list", Method: "GET", Description: "获取用户列表", Group: "user", Status: 1},
 		{Name: "SystemUserCreate", Path: "/api/system/user", Method: "POST", Description: "创建用户", Group: "user", Status: 1},
@@ -195,14 +275,8 @@ func (u *InitUsecase) ApiInitialize() error {
 		{Name: "SystemApiUpdate", Path: "/api/system/api", Method: "PUT", Description: "更新API", Group: "api", Status: 1},
 		{Name: "SystemApiDelete", Path: "/api/system/api/:id", Method: "DELETE", Description: "删除API", Group: "api", Status: 1},
 	}
-	if err := u.apiRepo.BatchCreate(context.Background(), apis); err != nil {
-		return err
-	}
-	return u.initRepo.SetInitialized(model.InitNameApi, "v1.0.0", "初始化管理员 api")
-}
 
-func (u *InitUsecase) CasbinInitialize() error {
-	policies := [][]string{
+	v100Policies = [][]string{
 		{model.RoleKeyAdmin, "/api/system/user/info", "GET"},
 		{model.RoleKeyAdmin, "/api/system/user/list", "GET"},
 		{model.RoleKeyAdmin, "/api/system/user", "POST"},
@@ -226,26 +300,245 @@ func (u *InitUsecase) CasbinInitialize() error {
 		{model.RoleKeyAdmin, "/api/system/api/:id", "DELETE"},
 	}
 
-	for _, policy := range policies {
-		if exist, err := u.casbinUsecase.HasPolicy(policy); err != nil {
-			return err
-		} else if exist {
-			return errorx.ErrPolicyIsExist
-		}
+	// v100RoleMenuIds 超级管理员拥有的初始菜单
+	v100RoleMenuIds = []uint64{1, 2, 3, 4, 5, 6, 7}
+
+	// v110Apis v1.1.0 新增的接口，超级管理员同时获得访问权限
+	v110Apis = []*apiSeed{
+		{Name: "SystemRoleClone", Path: "/api/system/role/:id/clone", Method: "POST", Description: "克隆角色", Group: "role", Status: 1},
+		{Name: "SystemRoleDiff", Path: "/api/system/role/diff", Method: "GET", Description: "对比角色权限", Group: "role", Status: 1},
+		{Name: "SystemRoleTemplateList", Path: "/api/system/role/template/list", Method: "GET", Description: "获取权限模板列表", Group: "role", Status: 1},
+		{Name: "SystemRoleTemplateCreate", Path: "/api/system/role/template", Method: "POST", Description: "创建权限模板", Group: "role", Status: 1},
+		{Name: "SystemRoleTemplateUpdate", Path: "/api/system/role/template", Method: "PUT", Description: "更新权限模板", Group: "role", Status: 1},
+		{Name: "SystemRoleTemplateDelete", Path: "/api/system/role/template/:id", Method: "DELETE", Description: "删除权限模板", Group: "role", Status: 1},
+		{Name: "SystemRoleTemplateApply", Path: "/api/system/role/template/apply", Method: "POST", Description: "应用权限模板", Group: "role", Status: 1},
+		{Name: "SystemUserRecycleList", Path: "/api/system/user/recycle/list", Method: "GET", Description: "获取用户回收站列表", Group: "user", Status: 1},
+		{Name: "SystemUserRecycleRestore", Path: "/api/system/user/recycle/restore", Method: "PUT", Description: "恢复回收站用户", Group: "user", Status: 1},
+		{Name: "SystemUserRecyclePurge", Path: "/api/system/user/recycle/purge", Method: "DELETE", Description: "彻底删除回收站用户", Group: "user", Status: 1},
+		{Name: "SystemRoleRecycleList", Path: "/api/system/role/recycle/list", Method: "GET", Description: "获取角色回收站列表", Group: "role", Status: 1},
+		{Name: "SystemRoleRecycleRestore", Path: "/api/system/role/recycle/restore", Method: "PUT", Description: "恢复回收站角色", Group: "role", Status: 1},
+		{Name: "SystemRoleRecyclePurge", Path: "/api/system/role/recycle/purge", Method: "DELETE", Description: "彻底删除回收站角色", Group: "role", Status: 1},
+		{Name: "SystemMenuGetButtonApis", Path: "/api/system/menu/:id/apis", Method: "GET", Description: "获取按钮关联接口", Group: "menu", Status: 1},
+		{Name: "SystemMenuBindButtonApis", Path: "/api/system/menu/apis", Method: "PUT", Description: "绑定按钮关联接口", Group: "menu", Status: 1},
+		{Name: "SystemMenuSort", Path: "/api/system/menu/sort", Method: "PUT", Description: "菜单拖拽排序", Group: "menu", Status: 1},
+		{Name: "SystemMenuExport", Path: "/api/system/menu/export", Method: "GET", Description: "导出菜单", Group: "menu", Status: 1},
+		{Name: "SystemMenuImport", Path: "/api/system/menu/import", Method: "POST", Description: "导入菜单", Group: "menu", Status: 1},
+		{Name: "SystemMenuRecycleList", Path: "/api/system/menu/recycle/list", Method: "GET", Description: "获取菜单回收站列表", Group: "menu", Status: 1},
+		{Name: "SystemMenuRecycleRestore", Path: "/api/system/menu/recycle/restore", Method: "PUT", Description: "恢复回收站菜单", Group: "menu", Status: 1},
+		{Name: "SystemMenuRecyclePurge", Path: "/api/system/menu/recycle/purge", Method: "DELETE", Description: "彻底删除回收站菜单", Group: "menu", Status: 1},
+		{Name: "SystemApiRecycleList", Path: "/api/system/api/recycle/list", Method: "GET", Description: "获取接口回收站列表", Group: "api", Status: 1},
+		{Name: "SystemApiRecycleRestore", Path: "/api/system/api/recycle/restore", Method: "PUT", Description: "恢复回收站接口", Group: "api", Status: 1},
+		{Name: "SystemApiRecyclePurge", Path: "/api/system/api/recycle/purge", Method: "DELETE", Description: "彻底删除回收站接口", Group: "api", Status: 1},
 	}
 
-	if ok, err := u.casbinUsecase.BatchAddPolicies(policies); err != nil || !ok {
+	// v110DirMenus v1.0.0 中作为页面创建的内置目录
+	v110DirMenus = []string{"Dashboard", "System"}
+
+	// v120Apis v1.2.0 新增的 IM 内容审核管理接口，超级管理员同时获得访问权限
+	v120Apis = []*apiSeed{
+		{Name: "ImModerationWordList", Path: "/api/im/admin/moderation/word/list", Method: "GET", Description: "获取敏感词列表", Group: "im_moderation", Status: 1},
+		{Name: "ImModerationWordCreate", Path: "/api/im/admin/moderation/word", Method: "POST", Description: "添加敏感词", Group: "im_moderation", Status: 1},
+		{Name: "ImModerationWordUpdate", Path: "/api/im/admin/moderation/word", Method: "PUT", Description: "修改敏感词", Group: "im_moderation", Status: 1},
+		{Name: "ImModerationWordDelete", Path: "/api/im/admin/moderation/word/:id", Method: "DELETE", Description: "删除敏感词", Group: "im_moderation", Status: 1},
+		{Name: "ImModerationReviewList", Path: "/api/im/admin/moderation/review/list", Method: "GET", Description: "获取人工审核队列", Group: "im_moderation", Status: 1},
+		{Name: "ImModerationReviewResolve", Path: "/api/im/admin/moderation/review", Method: "POST", Description: "处理审核记录", Group: "im_moderation", Status: 1},
+	}
+)
+
+func (u *InitUsecase) RoleInitialize(ctx context.Context) error {
+	return u.roleRepo.Create(ctx, adminRole.model())
+}
+
+func (u *InitUsecase) UserInitialize(ctx context.Context) error {
+	role, err := u.roleRepo.FindByKey(ctx, model.RoleKeyAdmin)
+	if err != nil {
 		return err
 	}
-	return u.initRepo.SetInitialized(model.InitNameCasbin, "v1.0.0", "初始化超级管理员权限")
+	if role == nil {
+		return errorx.ErrAdminRoleNotFound
+	}
+
+	now := time.Now()
+	user := adminUser.model()
+	user.Password = pkg.HashPassword("123456")
+	user.Roles = []*model.Role{role}
+	user.LastLoginAt = &now
+	return u.userRepo.Create(ctx, user)
+}
+
+func (u *InitUsecase) MenuInitialize(ctx context.Context) error {
+	for _, seed := range v100Menus {
+		if err := u.menuRepo.Create(ctx, seed.model()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *InitUsecase) ApiInitialize(ctx context.Context) error {
+	return u.apiRepo.BatchCreate(ctx, toApis(v100Apis))
+}
+
+func (u *InitUsecase) CasbinInitialize(ctx context.Context) error {
+	return u.casbinRepo.AddPolicies(ctx, v100Policies)
 }
 
 // RoleMenuInitialize 初始化角色菜单关联
-func (u *InitUsecase) RoleMenuInitialize() error {
-	// 为超级管理员分配所有菜单（使用菜单ID）
-	menuIds := []uint64{1, 2, 3, 4, 5, 6, 7}
-	if err := u.roleMenuRepo.AssignMenus(context.Background(), 1, menuIds); err != nil {
+func (u *InitUsecase) RoleMenuInitialize(ctx context.Context) error {
+	return u.roleMenuRepo.AssignMenus(ctx, adminRole.ID, v100RoleMenuIds)
+}
+
+// seedAdminApis 新增不存在的接口并授权给超级管理员，已存在的接口保持不变
+func (u *InitUsecase) seedAdminApis(seeds ...[]*apiSeed) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		apis := toApis(seeds...)
+		exists, err := u.apiRepo.FindByPathMethods(ctx, apiPathMethods(apis))
+		if err != nil {
+			return err
+		}
+		existSet := make(map[string]struct{}, len(exists))
+		for _, api := range exists {
+			existSet[api.Method+" "+api.Path] = struct{}{}
+		}
+
+		var missing []*model.Api
+		for _, api := range apis {
+			if _, ok := existSet[api.Method+" "+api.Path]; !ok {
+				missing = append(missing, api)
+			}
+		}
+		if len(missing) > 0 {
+			if err := u.apiRepo.BatchCreate(ctx, missing); err != nil {
+				return err
+			}
+		}
+
+		ids := make([]uint64, 0, len(apis))
+		for _, api := range append(exists, missing...) {
+			ids = append(ids, api.ID)
+		}
+		if err := u.roleApiRepo.AddApis(ctx, adminRole.ID, ids); err != nil {
+			return err
+		}
+		return u.casbinRepo.AddPolicies(ctx, buildPolicies(model.RoleKeyAdmin, apis))
+	}
+}
+
+// removeAdminApis 删除接口及所有角色对其的授权
+func (u *InitUsecase) removeAdminApis(seeds ...[]*apiSeed) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		apis := toApis(seeds...)
+		exists, err := u.apiRepo.FindByPathMethods(ctx, apiPathMethods(apis))
+		if err != nil {
+			return err
+		}
+		ids := make([]int64, 0, len(exists))
+		for _, api := range exists {
+			ids = append(ids, int64(api.ID))
+		}
+		if len(ids) > 0 {
+			if err := u.apiRepo.BatchDelete(ctx, ids); err != nil {
+				return err
+			}
+			if err := u.roleApiRepo.RemoveApis(ctx, adminRole.ID, toUint64s(ids)); err != nil {
+				return err
+			}
+		}
+		return u.casbinRepo.RemovePolicies(ctx, buildPolicies(model.RoleKeyAdmin, apis))
+	}
+}
+
+// backfillRoleApis 升级前角色的接口授权只存在于 Casbin 策略中，扣除角色已分配按钮关联的接口后，
+// 其余视为直接分配写入 sys_role_api，之后回收按钮不会误删这些授权
+func (u *InitUsecase) backfillRoleApis(ctx context.Context) error {
+	policies, err := u.casbinRepo.ListPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	bySubject := make(map[string][]*model.Api)
+	var keys []string
+	for _, policy := range policies {
+		if len(policy) < 3 {
+			continue
+		}
+		if _, ok := bySubject[policy[0]]; !ok {
+			keys = append(keys, policy[0])
+		}
+		bySubject[policy[0]] = append(bySubject[policy[0]], &model.Api{Path: policy[1], Method: policy[2]})
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	roles, err := u.roleRepo.FindByKeys(ctx, keys)
+	if err != nil {
 		return err
 	}
-	return u.initRepo.SetInitialized(model.InitNameRoleMenu, "v1.0.0", "初始化超级管理员菜单权限")
+	for _, role := range roles {
+		apis, err := u.apiRepo.FindByPathMethods(ctx, apiPathMethods(bySubject[role.Key]))
+		if err != nil {
+			return err
+		}
+		menuIds, err := u.roleMenuRepo.GetMenuIdsByRoleId(ctx, role.ID)
+		if err != nil {
+			return err
+		}
+		buttonApis, err := findButtonApis(ctx, u.menuRepo, u.menuApiRepo, u.apiRepo, menuIds)
+		if err != nil {
+			return err
+		}
+		fromButton := make(map[uint64]struct{}, len(buttonApis))
+		for _, api := range buttonApis {
+			fromButton[api.ID] = struct{}{}
+		}
+
+		var ids []uint64
+		for _, api := range apis {
+			if _, ok := fromButton[api.ID]; !ok {
+				ids = append(ids, api.ID)
+			}
+		}
+		if err := u.roleApiRepo.AddApis(ctx, role.ID, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setMenuType 将指定菜单的类型从 from 改为 to，类型已被手动修改的菜单保持不变
+func (u *InitUsecase) setMenuType(names []string, from, to string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for _, name := range names {
+			menu, err := u.menuRepo.FindByName(ctx, name)
+			if err != nil {
+				return err
+			}
+			if menu == nil || menu.Type != from {
+				continue
+			}
+			menu.Type = to
+			if err := u.menuRepo.Update(ctx, menu); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func apiPathMethods(apis []*model.Api) []struct {
+	Path   string
+	Method string
+} {
+	result := make([]struct {
+		Path   string
+		Method string
+	}, 0, len(apis))
+	for _, api := range apis {
+		result = append(result, struct {
+			Path   string
+			Method string
+		}{Path: api.Path, Method: api.Method})
+	}
+	return result
 }
@@ -0,0 +1,172 @@
+package biz
+
+import (
+	"server/internal/module/system/model"
+	"time"
+)
+
+// 以下结构冻结了种子数据参与迁移校验和计算时的 JSON 形式（字段、顺序及 json 标签），与 model 解耦，
+// model 新增字段或修改 json 标签不会改变已执行版本迁移的校验和。已发布的结构不要再修改，
+// 新版本需要新的字段时定义新的结构
+type (
+	roleSeed struct {
+		ID        uint64     `json:"id"`
+		CreatedAt time.Time  `json:"createdAt"`
+		UpdatedAt time.Time  `json:"updatedAt"`
+		Name      string     `json:"name"`
+		Key       string     `json:"key"`
+		Status    int64      `json:"status"`
+		DataScope string     `json:"dataScope"`
+		Sort      int64      `json:"sort"`
+		IsSystem  int64      `json:"isSystem"`
+		Remark    string     `json:"remark"`
+		Users     []struct{} `json:"users"`
+	}
+
+	userSeed struct {
+		ID          uint64     `json:"id"`
+		CreatedAt   time.Time  `json:"createdAt"`
+		UpdatedAt   time.Time  `json:"updatedAt"`
+		Username    string     `json:"username"`
+		Nickname    string     `json:"nickname"`
+		Email       string     `json:"email"`
+		Phone       string     `json:"phone"`
+		Avatar      string     `json:"avatar"`
+		Gender      int64      `json:"gender"`
+		Status      int64      `json:"status"`
+		IsAdmin     int64      `json:"isAdmin"`
+		Province    string     `json:"province"`
+		City        string     `json:"city"`
+		District    string     `json:"district"`
+		Address     string     `json:"address"`
+		Position    string     `json:"position"`
+		Department  string     `json:"department"`
+		JobTitle    string     `json:"jobTitle"`
+		Tags        string     `json:"tags"`
+		LastLoginAt *time.Time `json:"lastLoginAt"`
+		LastLoginIP string     `json:"lastLoginIP"`
+		Roles       []struct{} `json:"roles"`
+	}
+
+	menuSeed struct {
+		ID         uint64    `json:"id"`
+		CreatedAt  time.Time `json:"createdAt"`
+		UpdatedAt  time.Time `json:"updatedAt"`
+		ParentID   uint64    `json:"parentId"`
+		Type       string    `json:"type"`
+		AuthCode   string    `json:"authCode"`
+		Name       string    `json:"name"`
+		Title      string    `json:"title"`
+		Path       string    `json:"path"`
+		Component  string    `json:"component"`
+		Icon       string    `json:"icon"`
+		Redirect   string    `json:"redirect"`
+		Link       string    `json:"link"`
+		Roles      string    `json:"roles"`
+		IsIframe   int64     `json:"isIframe"`
+		Hidden     int64     `json:"hidden"`
+		HideTab    int64     `json:"hideTab"`
+		KeepAlive  int64     `json:"keepAlive"`
+		FullPage   int64     `json:"fullPage"`
+		FixedTab   int64     `json:"fixedTab"`
+		ShowBadge  int64     `json:"showBadge"`
+		TextBadge  string    `json:"textBadge"`
+		ActivePath string    `json:"activePath"`
+		Sort       int64     `json:"sort"`
+		Status     int64     `json:"status"`
+	}
+
+	apiSeed struct {
+		ID          uint64    `json:"id"`
+		CreatedAt   time.Time `json:"createdAt"`
+		UpdatedAt   time.Time `json:"updatedAt"`
+		Name        string    `json:"name"`
+		Path        string    `json:"path"`
+		Method      string    `json:"method"`
+		Description string    `json:"description"`
+		Group       string    `json:"group"`
+		Status      int64     `json:"status"`
+	}
+)
+
+func (s *roleSeed) model() *model.Role {
+	return &model.Role{
+		BaseModel: model.BaseModel{ID: s.ID},
+		Name:      s.Name,
+		Key:       s.Key,
+		Status:    s.Status,
+		DataScope: s.DataScope,
+		Sort:      s.Sort,
+		IsSystem:  s.IsSystem,
+		Remark:    s.Remark,
+	}
+}
+
+func (s *userSeed) model() *model.User {
+	return &model.User{
+		BaseModel:  model.BaseModel{ID: s.ID},
+		Username:   s.Username,
+		Nickname:   s.Nickname,
+		Email:      s.Email,
+		Phone:      s.Phone,
+		Avatar:     s.Avatar,
+		Gender:     s.Gender,
+		Status:     s.Status,
+		IsAdmin:    s.IsAdmin,
+		Province:   s.Province,
+		City:       s.City,
+		District:   s.District,
+		Address:    s.Address,
+		Position:   s.Position,
+		Department: s.Department,
+		JobTitle:   s.JobTitle,
+		Tags:       s.Tags,
+	}
+}
+
+func (s *menuSeed) model() *model.Menu {
+	return &model.Menu{
+		BaseModel:  model.BaseModel{ID: s.ID},
+		ParentID:   s.ParentID,
+		Type:       s.Type,
+		AuthCode:   s.AuthCode,
+		Name:       s.Name,
+		Title:      s.Title,
+		Path:       s.Path,
+		Component:  s.Component,
+		Icon:       s.Icon,
+		Redirect:   s.Redirect,
+		Link:       s.Link,
+		Roles:      s.Roles,
+		IsIframe:   s.IsIframe,
+		Hidden:     s.Hidden,
+		HideTab:    s.HideTab,
+		KeepAlive:  s.KeepAlive,
+		FullPage:   s.FullPage,
+		FixedTab:   s.FixedTab,
+		ShowBadge:  s.ShowBadge,
+		TextBadge:  s.TextBadge,
+		ActivePath: s.ActivePath,
+		Sort:       s.Sort,
+		Status:     s.Status,
+	}
+}
+
+// toApis 将种子数据转换为新的模型，可直接用于写入
+func toApis(seeds ...[]*apiSeed) []*model.Api {
+	var result []*model.Api
+	for _, list := range seeds {
+		for _, s := range list {
+			result = append(result, &model.Api{
+				BaseModel:   model.BaseModel{ID: s.ID},
+				Name:        s.Name,
+				Path:        s.Path,
+				Method:      s.Method,
+				Description: s.Description,
+				Group:       s.Group,
+				Status:      s.Status,
+			})
+		}
+	}
+	return result
+}
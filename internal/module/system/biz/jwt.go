@@ -3,11 +3,12 @@ package biz
 import (
 	"server/internal/core/config"
 	"server/pkg/jwtx"
+	"sync/atomic"
 )
 
 type (
 	JwtUsecase struct {
-		cfg *config.Jwt
+		cfg atomic.Pointer[config.Jwt] // 配置热更新时整体替换
 	}
 
 	jwtUsecase interface {
@@ -19,22 +20,29 @@ type (
 func NewJwtUsecase(
 	cfg *config.Jwt,
 ) *JwtUsecase {
-	return &JwtUsecase{
-		cfg: cfg,
-	}
+	ju := &JwtUsecase{}
+	ju.cfg.Store(cfg)
+	return ju
+}
+
+// UpdateConfig 替换令牌有效期配置，配置热更新时调用，已签发的令牌不受影响
+func (ju *JwtUsecase) UpdateConfig(cfg *config.Jwt) {
+	ju.cfg.Store(cfg)
+}
+
+func (ju *JwtUsecase) jwt() *jwtx.Jwt {
+	cfg := ju.cfg.Load()
+	return jwtx.New(cfg.Secret, cfg.AccessExpire, cfg.RefreshExpire)
 }
 
 func (ju *JwtUsecase) Parse(string string) (*jwtx.CustomClaims, error) {
-	j := jwtx.New(ju.cfg.Secret, ju.cfg.AccessExpire, ju.cfg.RefreshExpire)
-	return j.ParseToken(string)
+	return ju.jwt().ParseToken(string)
 }
 
 func (ju *JwtUsecase) GenerateAccessToken(userID uint, username string, roles []string) (string, error) {
-	j := jwtx.New(ju.cfg.Secret, ju.cfg.AccessExpire, ju.cfg.RefreshExpire)
-	return j.GenerateAccessToken(userID, username, roles)
+	return ju.jwt().GenerateAccessToken(userID, username, roles)
 }
 
 func (ju *JwtUsecase) GenerateRefreshToken(userID uint, username string, roles []string) (string, error) {
-	j := jwtx.New(ju.cfg.Secret, ju.cfg.AccessExpire, ju.cfg.RefreshExpire)
-	return j.GenerateRefreshToken(userID, username, roles)
+	return ju.jwt().GenerateRefreshToken(userID, username, roles)
 }
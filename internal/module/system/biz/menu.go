@@ -2,115 +2,459 @@ package biz
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
 	"server/internal/core/logger"
 	"server/internal/module/system/biz/repo"
 	"server/internal/module/system/model"
 	"server/internal/module/system/model/reply"
+	"server/internal/module/system/model/request"
 	"server/internal/module/system/model/response"
+	"server/pkg/errorx"
+	"sort"
+	"strconv"
 	"strings"
+
+	"go.uber.org/zap"
 )
 
-type MenuUsecase struct {
-	logger       logger.Logger
-	menuRepo     repo.MenuRepo
-	roleMenuRepo repo.RoleMenuRepo
-	userRepo     repo.UserRepo
-}
+type (
+	MenuUsecase struct {
+		logger        logger.Logger
+		transaction   repo.Transaction
+		menuRepo      repo.MenuRepo
+		roleMenuRepo  repo.RoleMenuRepo
+		userRepo      repo.UserRepo
+		roleRepo      repo.RoleRepo
+		apiRepo       repo.ApiRepo
+		menuApiRepo   repo.MenuApiRepo
+		menuCacheRepo repo.MenuCacheRepo
+		casbinRepo    repo.CasbinRepo
+		casbinUsecase casbinUsecase
+		policy        *rolePolicy
+	}
+
+	menuTreeCache interface {
+		InvalidateMenuTree(ctx context.Context)
+		InvalidateUserMenuTree(ctx context.Context, userIds ...uint64)
+	}
+)
+
+const (
+	menuTreeAllField         = "all"
+	menuTreeRolesFieldPrefix = "roles:"
+	menuTreeUserFieldPrefix  = "user:"
+)
 
-func NewMenuUsecase(logger logger.Logger, menuRepo repo.MenuRepo, roleMenuRepo repo.RoleMenuRepo, userRepo repo.UserRepo) *MenuUsecase {
+func NewMenuUsecase(
+	logger logger.Logger,
+	transaction repo.Transaction,
+	menuRepo repo.MenuRepo,
+	roleMenuRepo repo.RoleMenuRepo,
+	userRepo repo.UserRepo,
+	roleRepo repo.RoleRepo,
+	apiRepo repo.ApiRepo,
+	menuApiRepo repo.MenuApiRepo,
+	roleApiRepo repo.RoleApiRepo,
+	menuCacheRepo repo.MenuCacheRepo,
+	casbinRepo repo.CasbinRepo,
+	casbinUsecase casbinUsecase,
+) *MenuUsecase {
 	return &MenuUsecase{
-		logger:       logger,
-		menuRepo:     menuRepo,
-		roleMenuRepo: roleMenuRepo,
-		userRepo:     userRepo,
+		logger:        logger,
+		transaction:   transaction,
+		menuRepo:      menuRepo,
+		roleMenuRepo:  roleMenuRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		apiRepo:       apiRepo,
+		menuApiRepo:   menuApiRepo,
+		menuCacheRepo: menuCacheRepo,
+		casbinRepo:    casbinRepo,
+		casbinUsecase: casbinUsecase,
+		policy: &rolePolicy{
+			roleRepo:     roleRepo,
+			menuRepo:     menuRepo,
+			menuApiRepo:  menuApiRepo,
+			apiRepo:      apiRepo,
+			roleApiRepo:  roleApiRepo,
+			roleMenuRepo: roleMenuRepo,
+			casbinRepo:   casbinRepo,
+		},
 	}
 }
 
-func (u *MenuUsecase) GetAllMenuTree(ctx context.Context) ([]*response.MenuTreeResp, error) {
-	menus, err := u.menuRepo.GetAllEnabled(ctx)
-	if err != nil {
-		return nil, err
+// reloadPolicy 按钮变化影响了角色的 Casbin 策略时，事务提交后同步内存中的策略
+func (u *MenuUsecase) reloadPolicy(ctx context.Context, affected int) {
+	if affected == 0 {
+		return
+	}
+	if err := u.casbinUsecase.LoadPolicy(); err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] casbinUsecase.LoadPolicy error", zap.Error(err))
 	}
-	return u.buildMenuTree(menus, 0), nil
 }
 
-func (u *MenuUsecase) GetMenuTree(ctx context.Context) ([]*response.MenuTreeResp, error) {
-	// 获取所有启用的菜单
-	menus, err := u.menuRepo.GetAllEnabled(ctx)
-	if err != nil {
-		return nil, err
-	}
+// GetAllMenuTree 获取全部启用菜单的树（用于权限分配），返回序列化结果及 ETag
+func (u *MenuUsecase) GetAllMenuTree(ctx context.Context) (json.RawMessage, string, error) {
+	return u.cachedTree(ctx, menuTreeAllField, func() ([]*response.MenuTreeResp, error) {
+		menus, err := u.menuRepo.GetAllEnabled(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return u.buildMenuTree(newMenuIndex(menus), 0), nil
+	})
+}
 
+// GetMenuTree 获取当前用户的菜单树，按用户的角色组合缓存，返回序列化结果及 ETag
+func (u *MenuUsecase) GetMenuTree(ctx context.Context) (json.RawMessage, string, error) {
 	// 从 context 中获取用户ID
 	userIDVal := ctx.Value("userID")
 	if userIDVal == nil {
 		// 如果没有用户ID，返回空菜单（未登录或token无效）
-		return []*response.MenuTreeResp{}, nil
+		return u.marshalTree(nil)
 	}
 
 	userID, ok := userIDVal.(uint)
 	if !ok {
-		return []*response.MenuTreeResp{}, nil
+		return u.marshalTree(nil)
 	}
 
-	// 获取用户信息（包含角色）
-	user, err := u.userRepo.Find(ctx, int64(userID))
-	if err != nil || user == nil {
-		return []*response.MenuTreeResp{}, nil
+	roleIds, err := u.userRoleIds(ctx, uint64(userID))
+	if err != nil || len(roleIds) == 0 {
+		return u.marshalTree(nil)
 	}
 
-	// 获取用户所有角色的菜单权限
-	allowedMenuIDs := make(map[uint64]bool)
-	for _, role := range user.Roles {
-		menuIDs, err := u.roleMenuRepo.GetMenuIdsByRoleId(ctx, uint64(role.ID))
+	field := menuTreeRolesFieldPrefix + joinIds(roleIds)
+	return u.cachedTree(ctx, field, func() ([]*response.MenuTreeResp, error) {
+		// 获取所有启用的菜单
+		menus, err := u.menuRepo.GetAllEnabled(ctx)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		for _, menuID := range menuIDs {
-			allowedMenuIDs[menuID] = true
+
+		// 获取用户所有角色的菜单权限
+		allowedMenuIDs := make(map[uint64]bool)
+		for _, roleId := range roleIds {
+			menuIDs, err := u.roleMenuRepo.GetMenuIdsByRoleId(ctx, roleId)
+			if err != nil {
+				return nil, err
+			}
+			for _, menuID := range menuIDs {
+				allowedMenuIDs[menuID] = true
+			}
+		}
+
+		// 收集所有需要显示的菜单ID（包括父菜单）
+		idx := newMenuIndex(menus)
+		menuIDsToShow := make(map[uint64]bool)
+		for menuID := range allowedMenuIDs {
+			menuIDsToShow[menuID] = true
+			for _, parentID := range idx.ancestors(menuID) {
+				menuIDsToShow[parentID] = true
+			}
+		}
+
+		// 过滤菜单：保留用户有权限的菜单及其父菜单
+		var filteredMenus []*model.Menu
+		for _, menu := range menus {
+			if menuIDsToShow[menu.ID] {
+				filteredMenus = append(filteredMenus, menu)
+			}
+		}
+
+		return u.buildMenuTree(newMenuIndex(filteredMenus), 0), nil
+	})
+}
+
+// userRoleIds 获取用户的角色ID（已排序），优先读取缓存
+func (u *MenuUsecase) userRoleIds(ctx context.Context, userID uint64) ([]uint64, error) {
+	field := menuTreeUserFieldPrefix + strconv.FormatUint(userID, 10)
+	if data, err := u.menuCacheRepo.Get(ctx, field); err != nil {
+		u.logger.WithContext(ctx).Warn("[MenuUsecase] menuCacheRepo.Get error", zap.String("field", field), zap.Error(err))
+	} else if data != nil {
+		var roleIds []uint64
+		if err := json.Unmarshal(data, &roleIds); err == nil {
+			return roleIds, nil
 		}
 	}
 
-	// 收集所有需要显示的菜单ID（包括父菜单）
-	menuIDsToShow := make(map[uint64]bool)
-	for menuID := range allowedMenuIDs {
-		menuIDsToShow[menuID] = true
-		// 递归添加所有父菜单
-		u.addParentMenus(menus, menuID, menuIDsToShow)
+	// 获取用户信息（包含角色）
+	user, err := u.userRepo.Find(ctx, int64(userID))
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] userRepo.Find error", zap.Uint64("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
 	}
 
-	// 过滤菜单：保留用户有权限的菜单及其父菜单
-	var filteredMenus []*model.Menu
-	for _, menu := range menus {
-		if menuIDsToShow[menu.ID] {
-			filteredMenus = append(filteredMenus, menu)
-		}
+	roleIds := make([]uint64, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roleIds = append(roleIds, role.ID)
 	}
+	sort.Slice(roleIds, func(i, j int) bool { return roleIds[i] < roleIds[j] })
 
-	return u.buildMenuTree(filteredMenus, 0), nil
+	data, _ := json.Marshal(roleIds)
+	if err := u.menuCacheRepo.Set(ctx, field, data); err != nil {
+		u.logger.WithContext(ctx).Warn("[MenuUsecase] menuCacheRepo.Set error", zap.String("field", field), zap.Error(err))
+	}
+	return roleIds, nil
 }
 
-// addParentMenus 递归添加父菜单ID
-func (u *MenuUsecase) addParentMenus(menus []*model.Menu, menuID uint64, result map[uint64]bool) {
-	for _, menu := range menus {
-		if menu.ID == menuID && menu.ParentID != 0 {
-			result[menu.ParentID] = true
-			u.addParentMenus(menus, menu.ParentID, result)
-			break
-		}
+// cachedTree 读取缓存的菜单树，未命中时构建并写入缓存；缓存异常只记录日志，不影响返回
+func (u *MenuUsecase) cachedTree(ctx context.Context, field string, build func() ([]*response.MenuTreeResp, error)) (json.RawMessage, string, error) {
+	data, err := u.menuCacheRepo.Get(ctx, field)
+	if err != nil {
+		u.logger.WithContext(ctx).Warn("[MenuUsecase] menuCacheRepo.Get error", zap.String("field", field), zap.Error(err))
+	}
+	if data != nil {
+		return data, treeETag(data), nil
+	}
+
+	tree, err := build()
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] build menu tree error", zap.String("field", field), zap.Error(err))
+		return nil, "", errorx.ErrInternal
+	}
+
+	data, etag, err := u.marshalTree(tree)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := u.menuCacheRepo.Set(ctx, field, data); err != nil {
+		u.logger.WithContext(ctx).Warn("[MenuUsecase] menuCacheRepo.Set error", zap.String("field", field), zap.Error(err))
+	}
+	return data, etag, nil
+}
+
+func (u *MenuUsecase) marshalTree(tree []*response.MenuTreeResp) (json.RawMessage, string, error) {
+	if tree == nil {
+		tree = []*response.MenuTreeResp{}
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		u.logger.Error("[MenuUsecase] marshal menu tree error", zap.Error(err))
+		return nil, "", errorx.ErrInternal
+	}
+	return data, treeETag(data), nil
+}
+
+// InvalidateMenuTree 菜单或角色菜单权限变化时清空全部菜单树缓存
+func (u *MenuUsecase) InvalidateMenuTree(ctx context.Context) {
+	if err := u.menuCacheRepo.Clear(ctx); err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuCacheRepo.Clear error", zap.Error(err))
+	}
+}
+
+// InvalidateUserMenuTree 用户角色变化时清除用户的角色缓存
+func (u *MenuUsecase) InvalidateUserMenuTree(ctx context.Context, userIds ...uint64) {
+	fields := make([]string, 0, len(userIds))
+	for _, userId := range userIds {
+		fields = append(fields, menuTreeUserFieldPrefix+strconv.FormatUint(userId, 10))
+	}
+	if err := u.menuCacheRepo.Del(ctx, fields...); err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuCacheRepo.Del error", zap.Any("userIds", userIds), zap.Error(err))
 	}
 }
 
+func treeETag(data []byte) string {
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func joinIds(ids []uint64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatUint(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
 func (u *MenuUsecase) Create(ctx context.Context, req *model.Menu) error {
-	return u.menuRepo.Create(ctx, req)
+	if err := u.checkMenuType(req); err != nil {
+		return err
+	}
+	if err := u.checkParent(ctx, 0, req); err != nil {
+		return err
+	}
+	if err := u.menuRepo.Create(ctx, req); err != nil {
+		return err
+	}
+	u.InvalidateMenuTree(ctx)
+	return nil
 }
 
 func (u *MenuUsecase) Update(ctx context.Context, req *model.Menu) error {
-	return u.menuRepo.Update(ctx, req)
+	if err := u.checkMenuType(req); err != nil {
+		return err
+	}
+	if err := u.checkParent(ctx, req.ID, req); err != nil {
+		return err
+	}
+	if err := u.menuRepo.Update(ctx, req); err != nil {
+		return err
+	}
+	u.InvalidateMenuTree(ctx)
+	return nil
+}
+
+// checkParent 校验上级菜单，防止挂到不存在的菜单、按钮或自身的下级上形成环；
+// 按钮的上级必须是页面，已挂有按钮的页面不能改为其他类型。id 为 0 表示新建
+func (u *MenuUsecase) checkParent(ctx context.Context, id uint64, menu *model.Menu) error {
+	if menu.ParentID == 0 && (id == 0 || menu.Type == model.MenuTypePage) {
+		return nil
+	}
+	menus, err := u.menuRepo.GetAll(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.GetAll error", zap.Error(err))
+		return errorx.ErrInternal
+	}
+	idx := newMenuIndex(menus)
+	if !idx.validParent(id, menu.ParentID) || !idx.validButtonParent(menu) {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] invalid parent menu", zap.Any("id", id), zap.Any("parentId", menu.ParentID))
+		return errorx.ErrMenuParentInvalid
+	}
+	if id != 0 && menu.Type != model.MenuTypePage && idx.hasButtons(id) {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menu with buttons must be page", zap.Any("id", id), zap.String("type", menu.Type))
+		return errorx.ErrMenuTypeInvalid
+	}
+	return nil
 }
 
-func (u *MenuUsecase) Delete(ctx context.Context, id int64) error {
-	return u.menuRepo.Delete(ctx, id)
+// checkMenuType 校验菜单类型，按钮必须挂在页面下并填写权限标识
+func (u *MenuUsecase) checkMenuType(menu *model.Menu) error {
+	switch menu.Type {
+	case "":
+		menu.Type = model.MenuTypePage
+	case model.MenuTypeDir, model.MenuTypePage:
+	case model.MenuTypeButton:
+		if menu.ParentID == 0 || menu.AuthCode == "" {
+			u.logger.Error("[MenuUsecase] button menu requires parent and auth code", zap.Any("menu", menu))
+			return errorx.ErrMenuTypeInvalid
+		}
+	default:
+		u.logger.Error("[MenuUsecase] invalid menu type", zap.Any("type", menu.Type))
+		return errorx.ErrMenuTypeInvalid
+	}
+	return nil
+}
+
+// Delete 删除菜单，存在子菜单时按 children 处理：
+// cascade 连同所有下级一并删除，reparent 将下级挂到被删除菜单的上级（其按钮随页面删除），为空则拒绝删除
+func (u *MenuUsecase) Delete(ctx context.Context, id int64, children string) error {
+	menus, err := u.menuRepo.GetAll(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.GetAll error", zap.Error(err))
+		return errorx.ErrInternal
+	}
+	idx := newMenuIndex(menus)
+	menu := idx.byID[uint64(id)]
+	if menu == nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menu not found", zap.Any("id", id))
+		return errorx.ErrMenuNotFound
+	}
+
+	deleteIds := []int64{id}
+	var reparentIds []uint64
+	switch children {
+	case request.MenuDeleteChildrenCascade:
+		deleteIds = append(deleteIds, toInt64s(idx.descendants(menu.ID))...)
+	case request.MenuDeleteChildrenReparent:
+		for _, child := range idx.children[menu.ID] {
+			if child.IsButton() {
+				deleteIds = append(deleteIds, int64(child.ID))
+			} else {
+				reparentIds = append(reparentIds, child.ID)
+			}
+		}
+	case "":
+		if len(idx.children[menu.ID]) > 0 {
+			u.logger.WithContext(ctx).Warn("[MenuUsecase] menu has children", zap.Any("id", id))
+			return errorx.ErrMenuHasChildren
+		}
+	default:
+		return errorx.ErrInvalidParam
+	}
+
+	// 被删除的按钮不再授权，同一事务内重新生成拥有这些按钮的角色的策略；角色菜单关联保留，恢复后重新授权
+	var affected int
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.menuRepo.UpdateParent(ctx, reparentIds, menu.ParentID); err != nil {
+			return err
+		}
+		if err := u.menuRepo.BatchDelete(ctx, deleteIds); err != nil {
+			return err
+		}
+		var err error
+		affected, err = u.policy.syncByMenus(ctx, idx.buttons(toUint64s(deleteIds)))
+		return err
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] delete menu error", zap.Any("id", id), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	u.InvalidateMenuTree(ctx)
+	return nil
+}
+
+// Sort 拖拽排序，批量更新菜单的上级与排序，校验通过后在同一事务内写入
+func (u *MenuUsecase) Sort(ctx context.Context, req *request.MenuSortReq) error {
+	menus, err := u.menuRepo.GetAll(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.GetAll error", zap.Error(err))
+		return errorx.ErrInternal
+	}
+
+	// 在副本上应用新位置，整体校验后再落库
+	moved := make([]*model.Menu, 0, len(menus))
+	byID := make(map[uint64]*model.Menu, len(menus))
+	for _, menu := range menus {
+		clone := *menu
+		moved = append(moved, &clone)
+		byID[clone.ID] = &clone
+	}
+	for _, item := range req.Items {
+		menu := byID[item.ID]
+		if menu == nil {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] menu not found", zap.Any("id", item.ID))
+			return errorx.ErrMenuNotFound
+		}
+		menu.ParentID = item.ParentID
+		menu.Sort = item.Sort
+	}
+
+	idx := newMenuIndex(moved)
+	for _, item := range req.Items {
+		menu := idx.byID[item.ID]
+		if menu.IsButton() && item.ParentID == 0 {
+			return errorx.ErrMenuTypeInvalid
+		}
+		if (item.ParentID != 0 && (idx.byID[item.ParentID] == nil || idx.byID[item.ParentID].IsButton())) || !idx.validButtonParent(menu) {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] invalid parent menu", zap.Any("item", item))
+			return errorx.ErrMenuParentInvalid
+		}
+	}
+	if idx.hasCycle() {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menu sort creates cycle", zap.Any("req", req))
+		return errorx.ErrMenuParentInvalid
+	}
+
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		for _, item := range req.Items {
+			if err := u.menuRepo.UpdatePosition(ctx, item.ID, item.ParentID, item.Sort); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] sort menu error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.InvalidateMenuTree(ctx)
+	return nil
 }
 
 func (u *MenuUsecase) List(ctx context.Context, req *model.Menu) (*reply.ListMenuReply, error) {
@@ -121,37 +465,256 @@ func (u *MenuUsecase) List(ctx context.Context, req *model.Menu) (*reply.ListMen
 	return reply.BuilderListMenuReply(menus), nil
 }
 
-func (u *MenuUsecase) buildMenuTree(menus []*model.Menu, parentID uint64) []*response.MenuTreeResp {
+func (u *MenuUsecase) buildMenuTree(idx *menuIndex, parentID uint64) []*response.MenuTreeResp {
 	var tree []*response.MenuTreeResp
-	for _, menu := range menus {
-		if menu.ParentID == parentID {
-			node := &response.MenuTreeResp{
-				ID:        menu.ID,
-				Name:      menu.Name,
-				Path:      menu.Path,
-				Component: menu.Component,
-				Redirect:  menu.Redirect,
-				Meta: response.MenuMeta{
-					Title:         menu.Title,
-					Icon:          menu.Icon,
-					IsHide:        menu.Hidden == 1,
-					IsHideTab:     menu.HideTab == 1,
-					Link:          menu.Link,
-					IsIframe:      menu.IsIframe == 1,
-					KeepAlive:     menu.KeepAlive == 1,
-					FixedTab:      menu.FixedTab == 1,
-					ShowBadge:     menu.ShowBadge == 1,
-					ShowTextBadge: menu.TextBadge,
-					ActivePath:    menu.ActivePath,
-					IsFullPage:    menu.FullPage == 1,
-				},
-			}
-			if menu.Roles != "" {
-				node.Meta.Roles = strings.Split(menu.Roles, ",")
-			}
-			node.Children = u.buildMenuTree(menus, menu.ID)
-			tree = append(tree, node)
+	for _, menu := range idx.children[parentID] {
+		if menu.IsButton() {
+			continue
+		}
+		node := &response.MenuTreeResp{
+			ID:        menu.ID,
+			Name:      menu.Name,
+			Path:      menu.Path,
+			Component: menu.Component,
+			Redirect:  menu.Redirect,
+			Meta: response.MenuMeta{
+				Title:         menu.Title,
+				Icon:          menu.Icon,
+				IsHide:        menu.Hidden == 1,
+				IsHideTab:     menu.HideTab == 1,
+				Link:          menu.Link,
+				IsIframe:      menu.IsIframe == 1,
+				KeepAlive:     menu.KeepAlive == 1,
+				FixedTab:      menu.FixedTab == 1,
+				ShowBadge:     menu.ShowBadge == 1,
+				ShowTextBadge: menu.TextBadge,
+				ActivePath:    menu.ActivePath,
+				IsFullPage:    menu.FullPage == 1,
+			},
 		}
+		if menu.Roles != "" {
+			node.Meta.Roles = strings.Split(menu.Roles, ",")
+		}
+		node.Meta.AuthList = u.buildAuthList(idx, menu.ID)
+		node.Children = u.buildMenuTree(idx, menu.ID)
+		tree = append(tree, node)
 	}
 	return tree
 }
+
+// buildAuthList 收集页面下的按钮权限标识
+func (u *MenuUsecase) buildAuthList(idx *menuIndex, parentID uint64) []response.MenuAuth {
+	var authList []response.MenuAuth
+	for _, menu := range idx.children[parentID] {
+		if menu.IsButton() {
+			authList = append(authList, response.MenuAuth{
+				ID:       menu.ID,
+				Title:    menu.Title,
+				AuthMark: menu.AuthCode,
+			})
+		}
+	}
+	return authList
+}
+
+// GetButtonApis 获取按钮关联的接口ID列表
+func (u *MenuUsecase) GetButtonApis(ctx context.Context, menuId int64) ([]uint64, error) {
+	if _, err := u.findButton(ctx, menuId); err != nil {
+		return nil, err
+	}
+
+	apiIds, err := u.menuApiRepo.GetApiIdsByMenuIds(ctx, []uint64{uint64(menuId)})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuApiRepo.GetApiIdsByMenuIds error", zap.Any("menuId", menuId), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if apiIds == nil {
+		apiIds = []uint64{}
+	}
+	return apiIds, nil
+}
+
+// BindButtonApis 覆盖按钮关联的接口，并同步已拥有该按钮的角色的 Casbin 策略
+func (u *MenuUsecase) BindButtonApis(ctx context.Context, req *request.BindMenuApisReq) error {
+	button, err := u.findButton(ctx, int64(req.MenuId))
+	if err != nil {
+		return err
+	}
+
+	apiIds := uniqueIds(req.ApiIds)
+	if len(apiIds) > 0 {
+		apis, err := u.apiRepo.FindByIds(ctx, toInt64s(apiIds))
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] apiRepo.FindByIds error", zap.Any("req", req), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if len(apis) != len(apiIds) {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] api not found", zap.Any("req", req))
+			return errorx.ErrApiNotFound
+		}
+	}
+
+	var affected int
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.menuApiRepo.ReplaceApis(ctx, button.ID, apiIds); err != nil {
+			return err
+		}
+		var err error
+		affected, err = u.policy.syncByMenus(ctx, []uint64{button.ID})
+		return err
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] bind button apis error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	return nil
+}
+
+func (u *MenuUsecase) findButton(ctx context.Context, menuId int64) (*model.Menu, error) {
+	menu, err := u.menuRepo.Find(ctx, menuId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.Find error", zap.Any("menuId", menuId), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if menu == nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menu not found", zap.Any("menuId", menuId))
+		return nil, errorx.ErrMenuNotFound
+	}
+	if !menu.IsButton() {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menu is not button", zap.Any("menuId", menuId))
+		return nil, errorx.ErrMenuNotButton
+	}
+	return menu, nil
+}
+
+// RecycleList 回收站中的菜单列表
+func (u *MenuUsecase) RecycleList(ctx context.Context, req *request.RecycleListReq) (*reply.PageReply, error) {
+	menus, total, err := u.menuRepo.ListDeleted(ctx, req)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.ListDeleted error", zap.Any("req", req), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	return reply.BuilderPageReply(reply.BuilderMenuRecycleList(menus), total, offset, limit), nil
+}
+
+// Restore 从回收站恢复菜单，路由名称已被占用时拒绝恢复
+func (u *MenuUsecase) Restore(ctx context.Context, req *request.RecycleIdsReq) error {
+	menus, err := u.findDeleted(ctx, req.Ids)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(menus))
+	for _, menu := range menus {
+		if _, ok := names[menu.Name]; ok {
+			return errorx.ErrMenuAlreadyExists
+		}
+		names[menu.Name] = struct{}{}
+
+		exist, err := u.menuRepo.FindByName(ctx, menu.Name)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.FindByName error", zap.Any("name", menu.Name), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if exist != nil {
+			u.logger.WithContext(ctx).Warn("[MenuUsecase] restore menu name conflict", zap.Any("name", menu.Name))
+			return errorx.ErrMenuAlreadyExists
+		}
+	}
+
+	// 上级菜单仍在回收站且不随本次恢复时，恢复后会成为孤儿菜单
+	all, err := u.menuRepo.GetAll(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.GetAll error", zap.Error(err))
+		return errorx.ErrInternal
+	}
+	idx := newMenuIndex(all)
+	restoring := make(map[uint64]bool, len(menus))
+	for _, menu := range menus {
+		restoring[menu.ID] = true
+	}
+	for _, menu := range menus {
+		if menu.ParentID != 0 && idx.byID[menu.ParentID] == nil && !restoring[menu.ParentID] {
+			u.logger.WithContext(ctx).Warn("[MenuUsecase] restore menu parent missing", zap.Any("id", menu.ID), zap.Any("parentId", menu.ParentID))
+			return errorx.ErrMenuParentInvalid
+		}
+	}
+
+	// 恢复的按钮重新为仍拥有它的角色授权
+	var buttonIds []uint64
+	for _, menu := range menus {
+		if menu.IsButton() {
+			buttonIds = append(buttonIds, menu.ID)
+		}
+	}
+	var affected int
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.menuRepo.Restore(ctx, req.Ids); err != nil {
+			return err
+		}
+		var err error
+		affected, err = u.policy.syncByMenus(ctx, buttonIds)
+		return err
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] restore menu error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	u.InvalidateMenuTree(ctx)
+	return nil
+}
+
+// Purge 彻底删除回收站中的菜单，同一事务内重新生成曾拥有这些菜单的角色的策略
+func (u *MenuUsecase) Purge(ctx context.Context, req *request.RecycleIdsReq) error {
+	if _, err := u.findDeleted(ctx, req.Ids); err != nil {
+		return err
+	}
+
+	var affected int
+	err := u.transaction.InTx(ctx, func(ctx context.Context) error {
+		// 关联随菜单一起清理，需先查出受影响的角色
+		roleIds, err := u.roleMenuRepo.GetRoleIdsByMenuIds(ctx, toUint64s(req.Ids))
+		if err != nil {
+			return err
+		}
+		if err := u.menuRepo.Purge(ctx, req.Ids); err != nil {
+			return err
+		}
+		if len(roleIds) == 0 {
+			return nil
+		}
+		roles, err := u.roleRepo.FindByIDs(ctx, toInt64s(roleIds))
+		if err != nil {
+			return err
+		}
+		affected = len(roles)
+		return u.policy.sync(ctx, roles...)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] purge menu error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy(ctx, affected)
+	u.InvalidateMenuTree(ctx)
+	return nil
+}
+
+func (u *MenuUsecase) findDeleted(ctx context.Context, ids []int64) ([]*model.Menu, error) {
+	ids = uniqueInt64s(ids)
+	if len(ids) == 0 {
+		return nil, errorx.ErrInvalidParam
+	}
+	menus, err := u.menuRepo.FindDeletedByIds(ctx, ids)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.FindDeletedByIds error", zap.Any("ids", ids), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if len(menus) < len(ids) {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] deleted menus not found", zap.Any("ids", ids))
+		return nil, errorx.ErrMenuNotFound
+	}
+	return menus, nil
+}
@@ -0,0 +1,135 @@
+package biz
+
+import (
+	"context"
+	"server/internal/module/system/biz/repo"
+	"server/internal/module/system/model"
+)
+
+// findButtonApis 查询菜单中按钮所关联的接口，非按钮菜单忽略
+func findButtonApis(ctx context.Context, menuRepo repo.MenuRepo, menuApiRepo repo.MenuApiRepo, apiRepo repo.ApiRepo, menuIds []uint64) ([]*model.Api, error) {
+	if len(menuIds) == 0 {
+		return nil, nil
+	}
+
+	menus, err := menuRepo.FindByIds(ctx, menuIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var buttonIds []uint64
+	for _, menu := range menus {
+		if menu.IsButton() {
+			buttonIds = append(buttonIds, menu.ID)
+		}
+	}
+	if len(buttonIds) == 0 {
+		return nil, nil
+	}
+
+	apiIds, err := menuApiRepo.GetApiIdsByMenuIds(ctx, buttonIds)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiIds) == 0 {
+		return nil, nil
+	}
+
+	return apiRepo.FindByIds(ctx, toInt64s(apiIds))
+}
+
+// rolePolicy 生成角色的 Casbin 策略：直接分配的接口（sys_role_api）与已分配按钮关联的接口（sys_menu_api）合并，
+// 任一来源变化后整体重新生成，因此回收按钮不会影响直接分配的接口，反之亦然
+type rolePolicy struct {
+	roleRepo     repo.RoleRepo
+	menuRepo     repo.MenuRepo
+	menuApiRepo  repo.MenuApiRepo
+	apiRepo      repo.ApiRepo
+	roleApiRepo  repo.RoleApiRepo
+	roleMenuRepo repo.RoleMenuRepo
+	casbinRepo   repo.CasbinRepo
+}
+
+// directApis 角色直接分配且未删除的接口
+func (p *rolePolicy) directApis(ctx context.Context, roleId uint64) ([]*model.Api, error) {
+	apiIds, err := p.roleApiRepo.GetApiIdsByRoleId(ctx, roleId)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiIds) == 0 {
+		return nil, nil
+	}
+	return p.apiRepo.FindByIds(ctx, toInt64s(apiIds))
+}
+
+// sync 重新生成角色的策略，需与修改授权来源的操作在同一事务内执行；已删除的接口与按钮不再授权
+func (p *rolePolicy) sync(ctx context.Context, roles ...*model.Role) error {
+	for _, role := range roles {
+		apis, err := p.directApis(ctx, role.ID)
+		if err != nil {
+			return err
+		}
+		menuIds, err := p.roleMenuRepo.GetMenuIdsByRoleId(ctx, role.ID)
+		if err != nil {
+			return err
+		}
+		buttonApis, err := findButtonApis(ctx, p.menuRepo, p.menuApiRepo, p.apiRepo, menuIds)
+		if err != nil {
+			return err
+		}
+		if err := p.casbinRepo.RemovePoliciesBySubject(ctx, role.Key); err != nil {
+			return err
+		}
+		if err := p.casbinRepo.AddPolicies(ctx, buildPolicies(role.Key, apis, buttonApis)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncByMenus 重新生成拥有指定菜单的角色的策略，用于按钮的接口绑定变化或按钮被删除、恢复后，返回受影响的角色数
+func (p *rolePolicy) syncByMenus(ctx context.Context, menuIds []uint64) (int, error) {
+	roleIds, err := p.roleMenuRepo.GetRoleIdsByMenuIds(ctx, menuIds)
+	if err != nil || len(roleIds) == 0 {
+		return 0, err
+	}
+	roles, err := p.roleRepo.FindByIDs(ctx, toInt64s(roleIds))
+	if err != nil {
+		return 0, err
+	}
+	return len(roles), p.sync(ctx, roles...)
+}
+
+// rolesByApis 直接分配了指定接口或拥有关联这些接口的按钮的角色。
+// 需在修改接口之前调用：彻底删除接口会一并清理 sys_role_api、sys_menu_api 关联
+func (p *rolePolicy) rolesByApis(ctx context.Context, apiIds []uint64) ([]*model.Role, error) {
+	roleIds, err := p.roleApiRepo.GetRoleIdsByApiIds(ctx, apiIds)
+	if err != nil {
+		return nil, err
+	}
+	menuIds, err := p.menuApiRepo.GetMenuIdsByApiIds(ctx, apiIds)
+	if err != nil {
+		return nil, err
+	}
+	menuRoleIds, err := p.roleMenuRepo.GetRoleIdsByMenuIds(ctx, menuIds)
+	if err != nil {
+		return nil, err
+	}
+	roleIds = uniqueIds(append(roleIds, menuRoleIds...))
+	if len(roleIds) == 0 {
+		return nil, nil
+	}
+	return p.roleRepo.FindByIDs(ctx, toInt64s(roleIds))
+}
+
+// syncByApis 在同一事务内执行接口变更 fn，并重新生成受影响角色的策略，返回受影响的角色数
+func (p *rolePolicy) syncByApis(ctx context.Context, apiIds []uint64, fn func(ctx context.Context) error) (int, error) {
+	roles, err := p.rolesByApis(ctx, apiIds)
+	if err != nil {
+		return 0, err
+	}
+	if err := fn(ctx); err != nil {
+		return 0, err
+	}
+	return len(roles), p.sync(ctx, roles...)
+}
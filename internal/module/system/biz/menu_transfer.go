@@ -0,0 +1,656 @@
+package biz
+
+import (
+	"context"
+	"server/internal/module/system/model"
+	"server/internal/module/system/model/reply"
+	"server/internal/module/system/model/request"
+	"server/pkg/errorx"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// menuImportPlan 菜单导入计划，预览与实际导入共用
+type menuImportPlan struct {
+	reply    *reply.MenuImportReply
+	items    []*request.MenuTransferItem // 已按层级排序，上级菜单在前
+	existing map[string]*model.Menu
+	// apiChanges 关联接口发生变化的按钮，菜单名称 -> 新的接口ID
+	apiChanges map[string][]uint64
+	// roleMenus 需要追加的角色菜单关联，角色ID -> 菜单名称
+	roleMenus map[uint64][]string
+	roles     map[uint64]*model.Role
+}
+
+// Export 导出全部菜单及角色菜单关联，以菜单名称作为标识，便于在不同环境之间迁移
+func (u *MenuUsecase) Export(ctx context.Context) (*request.MenuTransferDoc, error) {
+	menus, err := u.menuRepo.GetAll(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.GetAll error", zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+
+	roleKeys, err := u.exportRoleKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	buttonApis, err := u.exportButtonApis(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &request.MenuTransferDoc{
+		Version: request.MenuTransferVersion,
+		Menus:   make([]*request.MenuTransferItem, 0, len(menus)),
+	}
+
+	// 深度优先遍历，保证上级菜单排在下级之前
+	idx := newMenuIndex(menus)
+	var walk func(parentID uint64, parentName string)
+	walk = func(parentID uint64, parentName string) {
+		for _, menu := range idx.children[parentID] {
+			item := toTransferItem(menu, parentName)
+			item.RoleKeys = roleKeys[menu.ID]
+			item.Apis = buttonApis[menu.ID]
+			doc.Menus = append(doc.Menus, item)
+			walk(menu.ID, menu.Name)
+		}
+	}
+	walk(0, "")
+
+	return doc, nil
+}
+
+// exportRoleKeys 返回菜单ID -> 拥有该菜单的角色编码
+func (u *MenuUsecase) exportRoleKeys(ctx context.Context) (map[uint64][]string, error) {
+	roleMenus, err := u.roleMenuRepo.List(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] roleMenuRepo.List error", zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+
+	roleIds := make([]uint64, 0, len(roleMenus))
+	for _, rm := range roleMenus {
+		roleIds = append(roleIds, rm.RoleID)
+	}
+	roleIds = uniqueIds(roleIds)
+	if len(roleIds) == 0 {
+		return nil, nil
+	}
+
+	roles, err := u.roleRepo.FindByIDs(ctx, toInt64s(roleIds))
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] roleRepo.FindByIDs error", zap.Any("roleIds", roleIds), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	keys := make(map[uint64]string, len(roles))
+	for _, role := range roles {
+		keys[role.ID] = role.Key
+	}
+
+	result := make(map[uint64][]string)
+	for _, rm := range roleMenus {
+		if key, ok := keys[rm.RoleID]; ok {
+			result[rm.MenuID] = append(result[rm.MenuID], key)
+		}
+	}
+	for _, list := range result {
+		sort.Strings(list)
+	}
+	return result, nil
+}
+
+// exportButtonApis 返回按钮ID -> 关联接口（"METHOD /path"）
+func (u *MenuUsecase) exportButtonApis(ctx context.Context) (map[uint64][]string, error) {
+	menuApis, err := u.menuApiRepo.List(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuApiRepo.List error", zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+
+	apiIds := make([]uint64, 0, len(menuApis))
+	for _, ma := range menuApis {
+		apiIds = append(apiIds, ma.ApiID)
+	}
+	apiIds = uniqueIds(apiIds)
+	if len(apiIds) == 0 {
+		return nil, nil
+	}
+
+	apis, err := u.apiRepo.FindByIds(ctx, toInt64s(apiIds))
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] apiRepo.FindByIds error", zap.Any("apiIds", apiIds), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	names := make(map[uint64]string, len(apis))
+	for _, api := range apis {
+		names[api.ID] = transferApiKey(api.Method, api.Path)
+	}
+
+	result := make(map[uint64][]string)
+	for _, ma := range menuApis {
+		if name, ok := names[ma.ApiID]; ok {
+			result[ma.MenuID] = append(result[ma.MenuID], name)
+		}
+	}
+	for _, list := range result {
+		sort.Strings(list)
+	}
+	return result, nil
+}
+
+// Import 按菜单名称导入菜单：不存在则新建，已存在则更新，并按名称重新计算上级菜单ID。
+// 角色菜单关联只追加不回收，未知的角色或接口记录为警告并跳过。apply 为 false 时只返回变更预览
+func (u *MenuUsecase) Import(ctx context.Context, doc *request.MenuTransferDoc, apply bool) (*reply.MenuImportReply, error) {
+	plan, err := u.planImport(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	if !apply {
+		return plan.reply, nil
+	}
+
+	if err := u.applyImport(ctx, plan); err != nil {
+		return nil, err
+	}
+	plan.reply.Applied = true
+	return plan.reply, nil
+}
+
+func (u *MenuUsecase) planImport(ctx context.Context, doc *request.MenuTransferDoc) (*menuImportPlan, error) {
+	if doc == nil || doc.Version != request.MenuTransferVersion {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] unsupported menu import version", zap.Any("doc", doc))
+		return nil, errorx.ErrMenuImportInvalid
+	}
+
+	items := make(map[string]*request.MenuTransferItem, len(doc.Menus))
+	for _, item := range doc.Menus {
+		if item == nil || item.Name == "" {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] menu import item without name")
+			return nil, errorx.ErrMenuImportInvalid
+		}
+		if _, ok := items[item.Name]; ok {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] duplicate menu in import", zap.String("name", item.Name))
+			return nil, errorx.ErrMenuImportInvalid
+		}
+		if err := u.checkTransferType(item); err != nil {
+			return nil, err
+		}
+		items[item.Name] = item
+	}
+
+	menus, err := u.menuRepo.GetAll(ctx)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] menuRepo.GetAll error", zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+
+	plan := &menuImportPlan{
+		reply:      reply.NewMenuImportReply(),
+		existing:   make(map[string]*model.Menu, len(menus)),
+		apiChanges: make(map[string][]uint64),
+		roleMenus:  make(map[uint64][]string),
+		roles:      make(map[uint64]*model.Role),
+	}
+
+	// 导入后的菜单结构：先取现有菜单，再用导入内容覆盖
+	idx := newMenuIndex(menus)
+	parentOf := make(map[string]string, len(menus)+len(items))
+	typeOf := make(map[string]string, len(menus)+len(items))
+	for _, menu := range menus {
+		plan.existing[menu.Name] = menu
+		if parent, ok := idx.byID[menu.ParentID]; ok {
+			parentOf[menu.Name] = parent.Name
+		} else {
+			parentOf[menu.Name] = ""
+		}
+		typeOf[menu.Name] = menu.Type
+	}
+	for name, item := range items {
+		parentOf[name] = item.ParentName
+		typeOf[name] = item.Type
+	}
+
+	depth := make(map[string]int, len(items))
+	for name, item := range items {
+		d, ok := transferDepth(name, parentOf, typeOf)
+		if !ok {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] invalid parent menu in import", zap.String("name", name), zap.String("parentName", item.ParentName))
+			return nil, errorx.ErrMenuParentInvalid
+		}
+		depth[name] = d
+	}
+	// 按钮只能挂在页面下：校验导入的按钮，以及上级被导入内容修改的按钮
+	for name, parent := range parentOf {
+		if typeOf[name] != model.MenuTypeButton {
+			continue
+		}
+		_, imported := items[name]
+		_, parentImported := items[parent]
+		if (imported || parentImported) && typeOf[parent] != model.MenuTypePage {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] button menu requires page parent in import", zap.String("name", name), zap.String("parentName", parent))
+			return nil, errorx.ErrMenuParentInvalid
+		}
+	}
+
+	plan.items = make([]*request.MenuTransferItem, 0, len(doc.Menus))
+	plan.items = append(plan.items, doc.Menus...)
+	sort.SliceStable(plan.items, func(i, j int) bool {
+		return depth[plan.items[i].Name] < depth[plan.items[j].Name]
+	})
+
+	apiChanged, err := u.planButtonApis(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range plan.items {
+		menu, ok := plan.existing[item.Name]
+		if !ok {
+			plan.reply.Created = append(plan.reply.Created, item.Name)
+			continue
+		}
+		fields := diffTransferItem(toTransferItem(menu, ""), item)
+		if apiChanged[item.Name] {
+			fields = append(fields, "apis")
+		}
+		// 上级菜单按名称比较，数据库中的上级ID在不同环境之间无意义
+		if parent, ok := idx.byID[menu.ParentID]; (ok && parent.Name != item.ParentName) || (!ok && item.ParentName != "") {
+			fields = append([]string{"parentName"}, fields...)
+		}
+		if len(fields) == 0 {
+			plan.reply.Unchanged = append(plan.reply.Unchanged, item.Name)
+			continue
+		}
+		plan.reply.Updated = append(plan.reply.Updated, &reply.MenuImportChange{Name: item.Name, Fields: fields})
+	}
+
+	if err := u.planRoleMenus(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// checkTransferType 校验导入菜单的类型，规则同 checkMenuType
+func (u *MenuUsecase) checkTransferType(item *request.MenuTransferItem) error {
+	switch item.Type {
+	case "":
+		item.Type = model.MenuTypePage
+	case model.MenuTypeDir, model.MenuTypePage:
+	case model.MenuTypeButton:
+		if item.ParentName == "" || item.AuthCode == "" {
+			u.logger.Error("[MenuUsecase] button menu requires parent and auth code", zap.String("name", item.Name))
+			return errorx.ErrMenuTypeInvalid
+		}
+	default:
+		u.logger.Error("[MenuUsecase] invalid menu type", zap.String("name", item.Name), zap.String("type", item.Type))
+		return errorx.ErrMenuTypeInvalid
+	}
+	return nil
+}
+
+// planButtonApis 解析按钮关联的接口，记录与现有绑定不同的按钮。未填写 apis 的按钮保持原有绑定
+func (u *MenuUsecase) planButtonApis(ctx context.Context, plan *menuImportPlan) (map[string]bool, error) {
+	var pathMethods []struct {
+		Path   string
+		Method string
+	}
+	for _, item := range plan.items {
+		if len(item.Apis) == 0 {
+			continue
+		}
+		if item.Type != model.MenuTypeButton {
+			plan.reply.Warnings = append(plan.reply.Warnings, "菜单 "+item.Name+" 不是按钮，忽略关联接口")
+			continue
+		}
+		for _, key := range item.Apis {
+			method, path, ok := parseTransferApiKey(key)
+			if !ok {
+				plan.reply.Warnings = append(plan.reply.Warnings, "按钮 "+item.Name+" 的接口格式错误："+key)
+				continue
+			}
+			pathMethods = append(pathMethods, struct {
+				Path   string
+				Method string
+			}{Path: path, Method: method})
+		}
+	}
+	if len(pathMethods) == 0 {
+		return nil, nil
+	}
+
+	apis, err := u.apiRepo.FindByPathMethods(ctx, pathMethods)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] apiRepo.FindByPathMethods error", zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	apiIds := make(map[string]uint64, len(apis))
+	for _, api := range apis {
+		apiIds[transferApiKey(api.Method, api.Path)] = api.ID
+	}
+
+	changed := make(map[string]bool)
+	for _, item := range plan.items {
+		if len(item.Apis) == 0 || item.Type != model.MenuTypeButton {
+			continue
+		}
+		var ids []uint64
+		for _, key := range item.Apis {
+			method, path, ok := parseTransferApiKey(key)
+			if !ok {
+				continue
+			}
+			id, ok := apiIds[transferApiKey(method, path)]
+			if !ok {
+				plan.reply.Warnings = append(plan.reply.Warnings, "按钮 "+item.Name+" 关联的接口不存在："+key)
+				continue
+			}
+			ids = append(ids, id)
+		}
+		ids = uniqueIds(ids)
+
+		var oldIds []uint64
+		if menu, ok := plan.existing[item.Name]; ok {
+			oldIds, err = u.menuApiRepo.GetApiIdsByMenuIds(ctx, []uint64{menu.ID})
+			if err != nil {
+				u.logger.WithContext(ctx).Error("[MenuUsecase] menuApiRepo.GetApiIdsByMenuIds error", zap.String("name", item.Name), zap.Error(err))
+				return nil, errorx.ErrInternal
+			}
+		}
+		if sameIds(oldIds, ids) {
+			continue
+		}
+		plan.apiChanges[item.Name] = ids
+		changed[item.Name] = true
+	}
+	return changed, nil
+}
+
+// planRoleMenus 计算需要追加的角色菜单关联
+func (u *MenuUsecase) planRoleMenus(ctx context.Context, plan *menuImportPlan) error {
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, item := range plan.items {
+		for _, key := range item.RoleKeys {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	roles, err := u.roleRepo.FindByKeys(ctx, keys)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] roleRepo.FindByKeys error", zap.Any("keys", keys), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	byKey := make(map[string]*model.Role, len(roles))
+	for _, role := range roles {
+		byKey[role.Key] = role
+	}
+	for _, key := range keys {
+		if _, ok := byKey[key]; !ok {
+			plan.reply.Warnings = append(plan.reply.Warnings, "角色不存在："+key)
+		}
+	}
+
+	owned := make(map[uint64]map[uint64]struct{}, len(roles))
+	for _, role := range roles {
+		menuIds, err := u.roleMenuRepo.GetMenuIdsByRoleId(ctx, role.ID)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] roleMenuRepo.GetMenuIdsByRoleId error", zap.Any("roleId", role.ID), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		set := make(map[uint64]struct{}, len(menuIds))
+		for _, id := range menuIds {
+			set[id] = struct{}{}
+		}
+		owned[role.ID] = set
+	}
+
+	for _, item := range plan.items {
+		for _, key := range uniqueStrings(item.RoleKeys) {
+			role, ok := byKey[key]
+			if !ok {
+				continue
+			}
+			if menu, ok := plan.existing[item.Name]; ok {
+				if _, ok := owned[role.ID][menu.ID]; ok {
+					continue
+				}
+			}
+			plan.roles[role.ID] = role
+			plan.roleMenus[role.ID] = append(plan.roleMenus[role.ID], item.Name)
+			plan.reply.RoleMenus = append(plan.reply.RoleMenus, key+":"+item.Name)
+		}
+	}
+	return nil
+}
+
+func (u *MenuUsecase) applyImport(ctx context.Context, plan *menuImportPlan) error {
+	// 关联接口变化的按钮，已拥有它的角色也需要同步策略
+	var extraRoleIds []uint64
+	for name := range plan.apiChanges {
+		menu, ok := plan.existing[name]
+		if !ok {
+			continue
+		}
+		roleIds, err := u.roleMenuRepo.GetRoleIdsByMenuId(ctx, menu.ID)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] roleMenuRepo.GetRoleIdsByMenuId error", zap.String("name", name), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		for _, id := range roleIds {
+			if _, ok := plan.roles[id]; !ok {
+				extraRoleIds = append(extraRoleIds, id)
+			}
+		}
+	}
+	if extraRoleIds = uniqueIds(extraRoleIds); len(extraRoleIds) > 0 {
+		roles, err := u.roleRepo.FindByIDs(ctx, toInt64s(extraRoleIds))
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[MenuUsecase] roleRepo.FindByIDs error", zap.Any("roleIds", extraRoleIds), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		for _, role := range roles {
+			plan.roles[role.ID] = role
+		}
+	}
+
+	err := u.transaction.InTx(ctx, func(ctx context.Context) error {
+		ids := make(map[string]uint64, len(plan.existing)+len(plan.items))
+		for name, menu := range plan.existing {
+			ids[name] = menu.ID
+		}
+		for _, item := range plan.items {
+			menu, ok := plan.existing[item.Name]
+			if !ok {
+				menu = &model.Menu{}
+			}
+			applyTransferItem(menu, item)
+			menu.ParentID = ids[item.ParentName]
+			if ok {
+				if err := u.menuRepo.Update(ctx, menu); err != nil {
+					return err
+				}
+			} else if err := u.menuRepo.Create(ctx, menu); err != nil {
+				return err
+			}
+			ids[item.Name] = menu.ID
+		}
+
+		for name, apiIds := range plan.apiChanges {
+			if err := u.menuApiRepo.ReplaceApis(ctx, ids[name], apiIds); err != nil {
+				return err
+			}
+		}
+
+		for _, role := range plan.roles {
+			menuIds := make([]uint64, 0, len(plan.roleMenus[role.ID]))
+			for _, name := range plan.roleMenus[role.ID] {
+				menuIds = append(menuIds, ids[name])
+			}
+			if err := u.roleMenuRepo.AddMenus(ctx, role.ID, menuIds); err != nil {
+				return err
+			}
+			if err := u.policy.sync(ctx, role); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[MenuUsecase] import menus error", zap.Any("reply", plan.reply), zap.Error(err))
+		return errorx.ErrInternal
+	}
+
+	u.reloadPolicy(ctx, len(plan.roles))
+	u.InvalidateMenuTree(ctx)
+	return nil
+}
+
+// transferDepth 计算菜单在导入后树中的层级，上级不存在、上级为按钮或存在环时返回 false
+func transferDepth(name string, parentOf, typeOf map[string]string) (int, bool) {
+	depth := 0
+	visited := map[string]bool{name: true}
+	for parent := parentOf[name]; parent != ""; parent = parentOf[parent] {
+		if _, ok := parentOf[parent]; !ok || visited[parent] || typeOf[parent] == model.MenuTypeButton {
+			return 0, false
+		}
+		visited[parent] = true
+		depth++
+	}
+	return depth, true
+}
+
+func toTransferItem(menu *model.Menu, parentName string) *request.MenuTransferItem {
+	return &request.MenuTransferItem{
+		Name:       menu.Name,
+		ParentName: parentName,
+		Type:       menu.Type,
+		AuthCode:   menu.AuthCode,
+		Title:      menu.Title,
+		Path:       menu.Path,
+		Component:  menu.Component,
+		Icon:       menu.Icon,
+		Redirect:   menu.Redirect,
+		Link:       menu.Link,
+		Roles:      menu.Roles,
+		IsIframe:   menu.IsIframe,
+		Hidden:     menu.Hidden,
+		HideTab:    menu.HideTab,
+		KeepAlive:  menu.KeepAlive,
+		FullPage:   menu.FullPage,
+		FixedTab:   menu.FixedTab,
+		ShowBadge:  menu.ShowBadge,
+		TextBadge:  menu.TextBadge,
+		ActivePath: menu.ActivePath,
+		Sort:       menu.Sort,
+		Status:     menu.Status,
+	}
+}
+
+// applyTransferItem 将导入内容写入菜单，上级菜单ID由调用方按名称解析
+func applyTransferItem(menu *model.Menu, item *request.MenuTransferItem) {
+	menu.Name = item.Name
+	menu.Type = item.Type
+	menu.AuthCode = item.AuthCode
+	menu.Title = item.Title
+	menu.Path = item.Path
+	menu.Component = item.Component
+	menu.Icon = item.Icon
+	menu.Redirect = item.Redirect
+	menu.Link = item.Link
+	menu.Roles = item.Roles
+	menu.IsIframe = item.IsIframe
+	menu.Hidden = item.Hidden
+	menu.HideTab = item.HideTab
+	menu.KeepAlive = item.KeepAlive
+	menu.FullPage = item.FullPage
+	menu.FixedTab = item.FixedTab
+	menu.ShowBadge = item.ShowBadge
+	menu.TextBadge = item.TextBadge
+	menu.ActivePath = item.ActivePath
+	menu.Sort = item.Sort
+	menu.Status = item.Status
+}
+
+// diffTransferItem 返回发生变化的字段名（不含上级菜单、角色和接口）
+func diffTransferItem(old, new *request.MenuTransferItem) []string {
+	var fields []string
+	check := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+	check("type", old.Type != new.Type)
+	check("authCode", old.AuthCode != new.AuthCode)
+	check("title", old.Title != new.Title)
+	check("path", old.Path != new.Path)
+	check("component", old.Component != new.Component)
+	check("icon", old.Icon != new.Icon)
+	check("redirect", old.Redirect != new.Redirect)
+	check("link", old.Link != new.Link)
+	check("roles", old.Roles != new.Roles)
+	check("isIframe", old.IsIframe != new.IsIframe)
+	check("hidden", old.Hidden != new.Hidden)
+	check("hideTab", old.HideTab != new.HideTab)
+	check("keepAlive", old.KeepAlive != new.KeepAlive)
+	check("fullPage", old.FullPage != new.FullPage)
+	check("fixedTab", old.FixedTab != new.FixedTab)
+	check("showBadge", old.ShowBadge != new.ShowBadge)
+	check("textBadge", old.TextBadge != new.TextBadge)
+	check("activePath", old.ActivePath != new.ActivePath)
+	check("sort", old.Sort != new.Sort)
+	check("status", old.Status != new.Status)
+	return fields
+}
+
+func transferApiKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// parseTransferApiKey 解析 "METHOD /path" 格式的接口标识
+func parseTransferApiKey(key string) (method, path string, ok bool) {
+	method, path, ok = strings.Cut(strings.TrimSpace(key), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || path == "" {
+		return "", "", false
+	}
+	return strings.ToUpper(method), path, true
+}
+
+func sameIds(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[uint64]struct{}, len(a))
+	for _, id := range a {
+		set[id] = struct{}{}
+	}
+	for _, id := range b {
+		if _, ok := set[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueStrings(list []string) []string {
+	result := make([]string, 0, len(list))
+	seen := make(map[string]struct{}, len(list))
+	for _, s := range list {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		result = append(result, s)
+	}
+	return result
+}
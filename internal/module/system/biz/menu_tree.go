@@ -0,0 +1,134 @@
+package biz
+
+import (
+	"server/internal/module/system/model"
+	"sort"
+)
+
+// menuIndex 菜单索引，按 ID 和父 ID 分组，避免构建树时反复遍历全部菜单
+type menuIndex struct {
+	byID     map[uint64]*model.Menu
+	children map[uint64][]*model.Menu
+}
+
+func newMenuIndex(menus []*model.Menu) *menuIndex {
+	idx := &menuIndex{
+		byID:     make(map[uint64]*model.Menu, len(menus)),
+		children: make(map[uint64][]*model.Menu),
+	}
+	for _, menu := range menus {
+		idx.byID[menu.ID] = menu
+		idx.children[menu.ParentID] = append(idx.children[menu.ParentID], menu)
+	}
+	for _, children := range idx.children {
+		sort.SliceStable(children, func(i, j int) bool {
+			if children[i].Sort != children[j].Sort {
+				return children[i].Sort < children[j].Sort
+			}
+			return children[i].ID < children[j].ID
+		})
+	}
+	return idx
+}
+
+// ancestors 返回菜单的所有上级菜单 ID，遇到环或缺失的上级时停止
+func (idx *menuIndex) ancestors(id uint64) []uint64 {
+	var result []uint64
+	visited := map[uint64]bool{id: true}
+	menu := idx.byID[id]
+	for menu != nil && menu.ParentID != 0 && !visited[menu.ParentID] {
+		visited[menu.ParentID] = true
+		result = append(result, menu.ParentID)
+		menu = idx.byID[menu.ParentID]
+	}
+	return result
+}
+
+// descendants 返回菜单的所有下级菜单 ID（广度优先）
+func (idx *menuIndex) descendants(id uint64) []uint64 {
+	var result []uint64
+	visited := map[uint64]bool{id: true}
+	queue := []uint64{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range idx.children[current] {
+			if visited[child.ID] {
+				continue
+			}
+			visited[child.ID] = true
+			result = append(result, child.ID)
+			queue = append(queue, child.ID)
+		}
+	}
+	return result
+}
+
+// buttons 返回 ids 中的按钮
+func (idx *menuIndex) buttons(ids []uint64) []uint64 {
+	var result []uint64
+	for _, id := range ids {
+		if menu := idx.byID[id]; menu != nil && menu.IsButton() {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// validParent 校验 parentID 能否作为 id 的上级：上级需存在且不是按钮，也不能是自身或其下级
+func (idx *menuIndex) validParent(id, parentID uint64) bool {
+	if parentID == 0 {
+		return true
+	}
+	parent := idx.byID[parentID]
+	if parent == nil || parent.IsButton() {
+		return false
+	}
+	if id == 0 {
+		return true
+	}
+	if parentID == id {
+		return false
+	}
+	for _, ancestor := range idx.ancestors(parentID) {
+		if ancestor == id {
+			return false
+		}
+	}
+	return true
+}
+
+// validButtonParent 按钮只能挂在页面下，非按钮不做限制
+func (idx *menuIndex) validButtonParent(menu *model.Menu) bool {
+	if !menu.IsButton() {
+		return true
+	}
+	parent := idx.byID[menu.ParentID]
+	return parent != nil && parent.Type == model.MenuTypePage
+}
+
+// hasButtons 判断菜单下是否挂有按钮
+func (idx *menuIndex) hasButtons(id uint64) bool {
+	for _, child := range idx.children[id] {
+		if child.IsButton() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCycle 判断是否存在无法回到顶级菜单的环
+func (idx *menuIndex) hasCycle() bool {
+	for id := range idx.byID {
+		visited := map[uint64]bool{}
+		current := idx.byID[id]
+		for current != nil && current.ParentID != 0 {
+			if visited[current.ID] {
+				return true
+			}
+			visited[current.ID] = true
+			current = idx.byID[current.ParentID]
+		}
+	}
+	return false
+}
@@ -21,4 +21,5 @@ var ProviderSet = wire.NewSet(
 	NewRoleUsecase,
 	NewApiUsecase,
 	NewMenuUsecase,
+	wire.Bind(new(menuTreeCache), new(*MenuUsecase)),
 )
@@ -19,4 +19,8 @@ type ApiRepo interface {
 		Path   string
 		Method string
 	}) ([]*model.Api, error)
+	ListDeleted(context.Context, *request.RecycleListReq) ([]*model.Api, int64, error)
+	FindDeletedByIds(context.Context, []int64) ([]*model.Api, error)
+	Restore(context.Context, []int64) error
+	Purge(context.Context, []int64) error
 }
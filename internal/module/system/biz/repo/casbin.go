@@ -1,7 +1,16 @@
 package repo
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
 
 type CasbinRepo interface {
 	AdapterDB() *gorm.DB
+	RemovePoliciesBySubject(context.Context, ...string) error
+	UpdateSubject(ctx context.Context, oldSub, newSub string) error
+	AddPolicies(context.Context, [][]string) error
+	RemovePolicies(context.Context, [][]string) error
+	ListPolicies(context.Context) ([][]string, error)
 }
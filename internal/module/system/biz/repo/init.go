@@ -1,9 +1,15 @@
 package repo
 
-import "gorm.io/gorm/schema"
+import (
+	"server/internal/core/migrate"
+
+	"gorm.io/gorm/schema"
+)
 
 type InitRepo interface {
+	// Store 迁移记录存储在 sys_init 中
+	migrate.Store
 	AutoMigrate([]schema.Tabler) error
-	IsInitialized(string) (bool, error)
-	SetInitialized(string, string, string) error
+	DropIndexes(schema.Tabler, ...string) error
+	BackfillDeleteMark([]schema.Tabler) error
 }
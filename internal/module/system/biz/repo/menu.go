@@ -15,4 +15,12 @@ type MenuRepo interface {
 	BatchDelete(context.Context, []int64) error
 	GetAllEnabled(context.Context) ([]*model.Menu, error)
 	GetAll(context.Context) ([]*model.Menu, error)
+	FindByName(context.Context, string) (*model.Menu, error)
+	FindByIds(context.Context, []uint64) ([]*model.Menu, error)
+	UpdatePosition(ctx context.Context, id, parentId uint64, sort int64) error
+	UpdateParent(ctx context.Context, ids []uint64, parentId uint64) error
+	ListDeleted(context.Context, *request.RecycleListReq) ([]*model.Menu, int64, error)
+	FindDeletedByIds(context.Context, []int64) ([]*model.Menu, error)
+	Restore(context.Context, []int64) error
+	Purge(context.Context, []int64) error
 }
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/system/model"
+)
+
+type MenuApiRepo interface {
+	// ReplaceApis 覆盖按钮关联的接口
+	ReplaceApis(ctx context.Context, menuId uint64, apiIds []uint64) error
+	// GetApiIdsByMenuIds 获取按钮关联的接口ID列表（已去重）
+	GetApiIdsByMenuIds(ctx context.Context, menuIds []uint64) ([]uint64, error)
+	// GetMenuIdsByApiIds 获取关联了指定接口的按钮ID列表（已去重）
+	GetMenuIdsByApiIds(ctx context.Context, apiIds []uint64) ([]uint64, error)
+	// List 获取全部按钮接口关联
+	List(ctx context.Context) ([]*model.MenuApi, error)
+}
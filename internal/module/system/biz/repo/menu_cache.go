@@ -0,0 +1,13 @@
+package repo
+
+import "context"
+
+// MenuCacheRepo 菜单树缓存，配置了 Redis 时使用 Redis，否则使用进程内 LRU
+type MenuCacheRepo interface {
+	// Get 读取缓存，未命中返回 nil
+	Get(ctx context.Context, field string) ([]byte, error)
+	Set(ctx context.Context, field string, value []byte) error
+	Del(ctx context.Context, fields ...string) error
+	// Clear 清空全部菜单树缓存
+	Clear(ctx context.Context) error
+}
@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/system/model"
+	"server/internal/module/system/model/request"
+)
+
+type PermissionTemplateRepo interface {
+	Create(context.Context, *model.PermissionTemplate) error
+	Update(context.Context, *model.PermissionTemplate) error
+	Delete(context.Context, int64) error
+	Find(context.Context, int64) (*model.PermissionTemplate, error)
+	FindByName(context.Context, string) (*model.PermissionTemplate, error)
+	List(context.Context, *request.PermissionTemplateListReq) ([]*model.PermissionTemplate, int64, error)
+}
@@ -16,4 +16,9 @@ type RoleRepo interface {
 	BatchDelete(context.Context, []int64) error
 	FindByIDs(context.Context, []int64) ([]*model.Role, error)
 	FindByKeys(context.Context, []string) ([]*model.Role, error)
+	ListDeleted(context.Context, *request.RecycleListReq) ([]*model.Role, int64, error)
+	FindDeletedByIds(context.Context, []int64) ([]*model.Role, error)
+	Restore(context.Context, []int64) error
+	Purge(context.Context, []int64) error
+	CountUsers(context.Context, []int64) (int64, error)
 }
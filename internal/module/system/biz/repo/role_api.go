@@ -0,0 +1,16 @@
+package repo
+
+import "context"
+
+type RoleApiRepo interface {
+	// AssignApis 覆盖角色直接分配的接口
+	AssignApis(ctx context.Context, roleId uint64, apiIds []uint64) error
+	// AddApis 为角色追加直接分配的接口，已存在的关联忽略
+	AddApis(ctx context.Context, roleId uint64, apiIds []uint64) error
+	// RemoveApis 移除角色直接分配的接口
+	RemoveApis(ctx context.Context, roleId uint64, apiIds []uint64) error
+	// GetApiIdsByRoleId 获取角色直接分配的接口ID列表
+	GetApiIdsByRoleId(ctx context.Context, roleId uint64) ([]uint64, error)
+	// GetRoleIdsByApiIds 获取直接分配了指定接口的角色ID列表（已去重）
+	GetRoleIdsByApiIds(ctx context.Context, apiIds []uint64) ([]uint64, error)
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"server/internal/module/system/model"
 )
 
 type RoleMenuRepo interface {
@@ -11,4 +12,12 @@ type RoleMenuRepo interface {
 	GetMenuIdsByRoleId(ctx context.Context, roleId uint64) ([]uint64, error)
 	// DeleteByRoleId 删除角色的所有菜单权限
 	DeleteByRoleId(ctx context.Context, roleId uint64) error
+	// GetRoleIdsByMenuId 获取拥有该菜单的角色ID列表
+	GetRoleIdsByMenuId(ctx context.Context, menuId uint64) ([]uint64, error)
+	// GetRoleIdsByMenuIds 获取拥有任一菜单的角色ID列表（已去重）
+	GetRoleIdsByMenuIds(ctx context.Context, menuIds []uint64) ([]uint64, error)
+	// AddMenus 为角色追加菜单权限，已存在的关联忽略
+	AddMenus(ctx context.Context, roleId uint64, menuIds []uint64) error
+	// List 获取全部角色菜单关联
+	List(ctx context.Context) ([]*model.RoleMenu, error)
 }
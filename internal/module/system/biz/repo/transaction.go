@@ -0,0 +1,8 @@
+package repo
+
+import "context"
+
+type Transaction interface {
+	// InTx 在同一个数据库事务中执行 fn，fn 内的 repo 调用必须使用传入的 ctx
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -17,5 +17,15 @@ type UserRepo interface {
 	FindByPhone(context.Context, string) (*model.User, error)
 	FindByIds(context.Context, []int64) ([]*model.User, error)
 	FindByEmail(context.Context, string) (*model.User, error)
+	// Search 按用户名精确匹配或昵称前缀匹配已启用的用户，供 IM 查找联系人
+	Search(ctx context.Context, keyword string, limit int) ([]*model.User, error)
 	UpdateLastLogin(context.Context, uint, string) error
+	ListDeleted(context.Context, *request.RecycleListReq) ([]*model.User, int64, error)
+	FindDeletedByIds(context.Context, []int64) ([]*model.User, error)
+	Restore(context.Context, []int64) error
+	Purge(context.Context, []int64) error
+	// AddRoles 为用户追加角色关联，已存在的关联保持不变
+	AddRoles(ctx context.Context, userId uint64, roleIds []uint64) error
+	// SetRoles 以给定角色整体替换用户的角色关联
+	SetRoles(ctx context.Context, userId uint64, roleIds []uint64) error
 }
@@ -9,75 +9,111 @@ import (
 	"server/internal/module/system/model/request"
 	"server/pkg/errorx"
 
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
 type RoleUsecase struct {
 	logger        logger.Logger
+	transaction   repo.Transaction
 	roleRepo      repo.RoleRepo
 	apiRepo       repo.ApiRepo
 	roleMenuRepo  repo.RoleMenuRepo
+	menuRepo      repo.MenuRepo
+	menuApiRepo   repo.MenuApiRepo
+	roleApiRepo   repo.RoleApiRepo
+	userRepo      repo.UserRepo
+	templateRepo  repo.PermissionTemplateRepo
+	casbinRepo    repo.CasbinRepo
 	casbinUsecase casbinUsecase
+	menuTreeCache menuTreeCache
+	policy        *rolePolicy
 }
 
 func NewRoleUsecase(
 	logger logger.Logger,
+	transaction repo.Transaction,
 	roleRepo repo.RoleRepo,
 	apiRepo repo.ApiRepo,
 	roleMenuRepo repo.RoleMenuRepo,
+	menuRepo repo.MenuRepo,
+	menuApiRepo repo.MenuApiRepo,
+	roleApiRepo repo.RoleApiRepo,
+	userRepo repo.UserRepo,
+	templateRepo repo.PermissionTemplateRepo,
+	casbinRepo repo.CasbinRepo,
 	casbinUsecase casbinUsecase,
+	menuTreeCache menuTreeCache,
 ) *RoleUsecase {
 	return &RoleUsecase{
 		logger:        logger,
+		transaction:   transaction,
 		roleRepo:      roleRepo,
 		apiRepo:       apiRepo,
 		roleMenuRepo:  roleMenuRepo,
+		menuRepo:      menuRepo,
+		menuApiRepo:   menuApiRepo,
+		roleApiRepo:   roleApiRepo,
+		userRepo:      userRepo,
+		templateRepo:  templateRepo,
+		casbinRepo:    casbinRepo,
 		casbinUsecase: casbinUsecase,
+		menuTreeCache: menuTreeCache,
+		policy: &rolePolicy{
+			roleRepo:     roleRepo,
+			menuRepo:     menuRepo,
+			menuApiRepo:  menuApiRepo,
+			apiRepo:      apiRepo,
+			roleApiRepo:  roleApiRepo,
+			roleMenuRepo: roleMenuRepo,
+			casbinRepo:   casbinRepo,
+		},
 	}
 }
 
+// reloadPolicy 事务提交后同步内存中的 Casbin 策略，数据库已是最终状态，失败仅记录日志。
+// 只重新加载当前实例，多实例部署时其他实例的策略在重启前保持旧状态
+func (u *RoleUsecase) reloadPolicy() {
+	if err := u.casbinUsecase.LoadPolicy(); err != nil {
+		u.logger.Error("[ RoleUsecase ] casbinUsecase.LoadPolicy error", zap.Error(err))
+	}
+}
+
+// AssignApiPermissions 覆盖角色直接分配的接口，按钮带来的接口权限不受影响
 func (u *RoleUsecase) AssignApiPermissions(ctx context.Context, roleId int64, apiIds []int64) error {
 	role, err := u.roleRepo.FindByID(ctx, roleId)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
 		return errorx.ErrInternal
 	}
 	if role == nil {
-		u.logger.Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
 		return errorx.ErrRoleNotFound
 	}
 
-	apis, err := u.apiRepo.FindByIds(ctx, apiIds)
+	ids := uniqueIds(toUint64s(apiIds))
+	apis, err := u.apiRepo.FindByIds(ctx, toInt64s(ids))
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] apiRepo.FindByIds error", zap.Any("apiIds", apiIds), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] apiRepo.FindByIds error", zap.Any("apiIds", apiIds), zap.Error(err))
 		return errorx.ErrInternal
 	}
 
-	if len(apis) != len(apiIds) {
-		u.logger.Error("[ RoleUsecase ] api not found", zap.Any("apiIds", apiIds))
+	if len(apis) != len(ids) {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] api not found", zap.Any("apiIds", apiIds))
 		return errorx.ErrApiNotFound
 	}
 
-	var policies [][]string
-	for i := range apis {
-		policies = append(policies, []string{role.Key, apis[i].Path, apis[i].Method})
-	}
-
-	if err = u.casbinUsecase.DeletePermissionsForRole(role.Key); err != nil {
-		u.logger.Error("[ RoleUsecase ] casbinUsecase.DeletePermissionsForRole error", zap.Any("roleId", roleId), zap.Error(err))
-		return errorx.ErrAddPoliciesFail
-	}
-
-	ok, err := u.casbinUsecase.AddPolicies(policies)
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.roleApiRepo.AssignApis(ctx, role.ID, ids); err != nil {
+			return err
+		}
+		return u.policy.sync(ctx, role)
+	})
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] casbinUsecase.AddPolicies error", zap.Any("policies", policies), zap.Error(err))
-		return errorx.ErrInternal
-	}
-
-	if !ok {
-		u.logger.Error("[ RoleUsecase ] casbinUsecase.AddPolicies failed", zap.Any("policies", policies))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] assign api permissions error", zap.Any("roleId", roleId), zap.Any("apiIds", apiIds), zap.Error(err))
 		return errorx.ErrAddPoliciesFail
 	}
+	u.reloadPolicy()
 
 	return nil
 }
@@ -86,12 +122,12 @@ func (u *RoleUsecase) Create(ctx context.Context, req *request.CreateRoleReq) er
 
 	role, err := u.roleRepo.FindByKey(ctx, req.Key)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByKey error", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByKey error", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrInternal
 	}
 
 	if role != nil {
-		u.logger.Error("[ RoleUsecase ] role already exists", zap.Any("req", req))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role already exists", zap.Any("req", req))
 		return errorx.ErrRoleAlreadyExists
 	}
 
@@ -106,7 +142,7 @@ func (u *RoleUsecase) Create(ctx context.Context, req *request.CreateRoleReq) er
 	}
 
 	if err := u.roleRepo.Create(ctx, createRole); err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.Create error", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.Create error", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrInternal
 	}
 
@@ -115,35 +151,64 @@ func (u *RoleUsecase) Create(ctx context.Context, req *request.CreateRoleReq) er
 	return nil
 }
 
-func (u *RoleUsecase) Delete(ctx context.Context, id int64) error {
+// Delete 软删除角色并移除其 Casbin 策略，用户、菜单及接口关联保留，恢复后按关联重新生成策略，彻底删除时才清理关联；
+// 仍有用户持有该角色时，cascade 为 false 则拒绝删除，为 true 时这些用户在角色恢复前失去该角色
+func (u *RoleUsecase) Delete(ctx context.Context, id int64, cascade bool) error {
 	ids := []int64{id}
 	roles, err := u.roleRepo.FindByIDs(ctx, ids)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("id", id), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("id", id), zap.Error(err))
 		return errorx.ErrInternal
 	}
 
 	if len(roles) != len(ids) {
-		u.logger.Error("[ RoleUsecase ] role not found", zap.Any("id", id))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("id", id))
 		return errorx.ErrRoleNotFound
 	}
 
-	var deleteIds []int64
+	var (
+		deleteIds []int64
+		keys      []string
+	)
 	for i := range roles {
 		if roles[i].IsSystem != model.RoleIsSystem {
 			deleteIds = append(deleteIds, int64(roles[i].ID))
+			keys = append(keys, roles[i].Key)
 		}
 	}
 
 	if len(deleteIds) == 0 {
-		u.logger.Error("[ RoleUsecase ] role is system", zap.Any("id", id))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role is system", zap.Any("id", id))
 		return errorx.ErrRoleIsSystem
 	}
 
-	if err := u.roleRepo.BatchDelete(ctx, deleteIds); err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.BatchDelete error", zap.Any("id", id), zap.Error(err))
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		// 在事务内统计并加共享锁，避免检查通过后并发为用户分配该角色
+		if !cascade {
+			count, err := u.roleRepo.CountUsers(ctx, deleteIds)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				u.logger.WithContext(ctx).Warn("[ RoleUsecase ] role in use", zap.Any("id", id), zap.Int64("users", count))
+				return errorx.ErrRoleInUse
+			}
+		}
+		if err := u.roleRepo.BatchDelete(ctx, deleteIds); err != nil {
+			return err
+		}
+		return u.casbinRepo.RemovePoliciesBySubject(ctx, keys...)
+	})
+	if err != nil {
+		var bizErr *errorx.BizError
+		if errors.As(err, &bizErr) {
+			return bizErr
+		}
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] delete role error", zap.Any("id", id), zap.Error(err))
 		return errorx.ErrInternal
 	}
+	u.reloadPolicy()
+	u.menuTreeCache.InvalidateMenuTree(ctx)
 
 	return nil
 }
@@ -152,29 +217,52 @@ func (u *RoleUsecase) Update(ctx context.Context, req *request.UpdateRoleReq) er
 
 	role, err := u.roleRepo.FindByID(ctx, *req.Id)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrInternal
 	}
 
 	if role == nil {
-		u.logger.Error("[ RoleUsecase ] role not found", zap.Any("req", req))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("req", req))
 		return errorx.ErrRoleNotFound
 	}
 
 	if role.IsSystem == model.RoleIsSystem {
-		u.logger.Error("[ RoleUsecase ] role is system", zap.Any("req", req))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role is system", zap.Any("req", req))
 		return errorx.ErrRoleIsSystem
 	}
 
+	oldKey := role.Key
+	if req.Key != oldKey {
+		exist, err := u.roleRepo.FindByKey(ctx, req.Key)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByKey error", zap.Any("req", req), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if exist != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] role already exists", zap.Any("req", req))
+			return errorx.ErrRoleAlreadyExists
+		}
+	}
+
 	role.Name = req.Name
 	role.Key = req.Key
 	role.Remark = req.Remark
 	role.Sort = *req.Sort
 
-	if err := u.roleRepo.Update(ctx, role); err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.Update error", zap.Any("req", req), zap.Error(err))
+	// 角色编码变更时，同一事务内迁移以旧编码为主体的 Casbin 策略
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.Update(ctx, role); err != nil {
+			return err
+		}
+		return u.casbinRepo.UpdateSubject(ctx, oldKey, role.Key)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] update role error", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrInternal
 	}
+	if oldKey != role.Key {
+		u.reloadPolicy()
+	}
 
 	return nil
 }
@@ -183,12 +271,12 @@ func (u *RoleUsecase) Get(ctx context.Context, req *request.GetRoleReq) (*reply.
 
 	role, err := u.roleRepo.FindByID(ctx, *req.Id)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("req", req), zap.Error(err))
 		return nil, errorx.ErrInternal
 	}
 
 	if role == nil {
-		u.logger.Error("[ RoleUsecase ] role not found", zap.Any("req", req))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("req", req))
 		return nil, errorx.ErrRoleNotFound
 	}
 
@@ -199,33 +287,50 @@ func (u *RoleUsecase) List(ctx context.Context, req *request.RoleListReq) (*repl
 
 	roles, total, err := u.roleRepo.List(ctx, req)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.List error", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.List error", zap.Any("req", req), zap.Error(err))
 		return nil, errorx.ErrInternal
 	}
 
 	return reply.BuilderListRoleReply(roles, total, *req.Page, *req.PageSize), nil
 }
 
-// GetRoleApiPermissions 获取角色的API权限列表
+// GetRoleApiPermissions 获取角色直接分配的API权限列表，不含按钮带来的接口
 func (u *RoleUsecase) GetRoleApiPermissions(ctx context.Context, roleId int64) ([]int64, error) {
 	role, err := u.roleRepo.FindByID(ctx, roleId)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
 		return nil, errorx.ErrInternal
 	}
 	if role == nil {
-		u.logger.Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
 		return nil, errorx.ErrRoleNotFound
 	}
 
+	apis, err := u.policy.directApis(ctx, role.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] policy.directApis error", zap.Any("roleId", roleId), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+
+	// 提取 API ID
+	apiIds := []int64{}
+	for _, api := range apis {
+		apiIds = append(apiIds, int64(api.ID))
+	}
+
+	return apiIds, nil
+}
+
+// findRoleApis 根据角色的 Casbin 策略查询对应的 API 列表，即角色实际可访问的接口
+func (u *RoleUsecase) findRoleApis(ctx context.Context, role *model.Role) ([]*model.Api, error) {
 	// 从 Casbin 获取角色的所有权限策略
 	policies, err := u.casbinUsecase.GetPermissionsForRole(role.Key)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] casbinUsecase.GetPermissionsForRole error", zap.Any("roleId", roleId), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] casbinUsecase.GetPermissionsForRole error", zap.Any("roleId", role.ID), zap.Error(err))
 		return nil, errorx.ErrInternal
 	}
 
-	// 提取所有的 path 和 method，然后查询对应的 API ID
+	// 提取所有的 path 和 method，然后查询对应的 API 列表
 	var pathMethods []struct {
 		Path   string
 		Method string
@@ -243,42 +348,42 @@ func (u *RoleUsecase) GetRoleApiPermissions(ctx context.Context, roleId int64) (
 	}
 
 	if len(pathMethods) == 0 {
-		return []int64{}, nil
+		return nil, nil
 	}
 
-	// 根据 path 和 method 查询 API 列表
 	apis, err := u.apiRepo.FindByPathMethods(ctx, pathMethods)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] apiRepo.FindByPathMethods error", zap.Any("pathMethods", pathMethods), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] apiRepo.FindByPathMethods error", zap.Any("pathMethods", pathMethods), zap.Error(err))
 		return nil, errorx.ErrInternal
 	}
-
-	// 提取 API ID
-	var apiIds []int64
-	for _, api := range apis {
-		apiIds = append(apiIds, int64(api.ID))
-	}
-
-	return apiIds, nil
+	return apis, nil
 }
 
 // AssignMenuPermissions 分配菜单权限给角色
 func (u *RoleUsecase) AssignMenuPermissions(ctx context.Context, roleId int64, menuIds []uint64) error {
 	role, err := u.roleRepo.FindByID(ctx, roleId)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
 		return errorx.ErrInternal
 	}
 	if role == nil {
-		u.logger.Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
 		return errorx.ErrRoleNotFound
 	}
 
-	// 分配菜单权限
-	if err := u.roleMenuRepo.AssignMenus(ctx, uint64(roleId), menuIds); err != nil {
-		u.logger.Error("[ RoleUsecase ] roleMenuRepo.AssignMenus error", zap.Any("roleId", roleId), zap.Any("menuIds", menuIds), zap.Error(err))
+	// 分配菜单权限，先删后插需在同一事务内完成；按钮变化时重新生成角色的 Casbin 策略
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.roleMenuRepo.AssignMenus(ctx, role.ID, uniqueIds(menuIds)); err != nil {
+			return err
+		}
+		return u.policy.sync(ctx, role)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] assign menu permissions error", zap.Any("roleId", roleId), zap.Any("menuIds", menuIds), zap.Error(err))
 		return errorx.ErrInternal
 	}
+	u.reloadPolicy()
+	u.menuTreeCache.InvalidateMenuTree(ctx)
 
 	return nil
 }
@@ -287,20 +392,518 @@ func (u *RoleUsecase) AssignMenuPermissions(ctx context.Context, roleId int64, m
 func (u *RoleUsecase) GetRoleMenuPermissions(ctx context.Context, roleId int64) ([]uint64, error) {
 	role, err := u.roleRepo.FindByID(ctx, roleId)
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("roleId", roleId), zap.Error(err))
 		return nil, errorx.ErrInternal
 	}
 	if role == nil {
-		u.logger.Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("roleId", roleId))
 		return nil, errorx.ErrRoleNotFound
 	}
 
 	// 获取菜单权限
 	menuIds, err := u.roleMenuRepo.GetMenuIdsByRoleId(ctx, uint64(roleId))
 	if err != nil {
-		u.logger.Error("[ RoleUsecase ] roleMenuRepo.GetMenuIdsByRoleId error", zap.Any("roleId", roleId), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleMenuRepo.GetMenuIdsByRoleId error", zap.Any("roleId", roleId), zap.Error(err))
 		return nil, errorx.ErrInternal
 	}
 
 	return menuIds, nil
 }
+
+// RecycleList 回收站中的角色列表
+func (u *RoleUsecase) RecycleList(ctx context.Context, req *request.RecycleListReq) (*reply.PageReply, error) {
+	roles, total, err := u.roleRepo.ListDeleted(ctx, req)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.ListDeleted error", zap.Any("req", req), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	return reply.BuilderPageReply(reply.BuilderRoleRecycleList(roles), total, offset, limit), nil
+}
+
+// Restore 从回收站恢复角色，按保留的菜单及接口关联重新生成 Casbin 策略，角色编码已被占用时拒绝恢复
+func (u *RoleUsecase) Restore(ctx context.Context, req *request.RecycleIdsReq) error {
+	roles, err := u.findDeleted(ctx, req.Ids)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		if _, ok := keys[role.Key]; ok {
+			return errorx.ErrRoleAlreadyExists
+		}
+		keys[role.Key] = struct{}{}
+
+		exist, err := u.roleRepo.FindByKey(ctx, role.Key)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByKey error", zap.Any("key", role.Key), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if exist != nil {
+			u.logger.WithContext(ctx).Warn("[ RoleUsecase ] restore role key conflict", zap.Any("key", role.Key))
+			return errorx.ErrRoleAlreadyExists
+		}
+	}
+
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.Restore(ctx, req.Ids); err != nil {
+			return err
+		}
+		return u.policy.sync(ctx, roles...)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] restore role error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy()
+	u.menuTreeCache.InvalidateMenuTree(ctx)
+	return nil
+}
+
+// Purge 彻底删除回收站中的角色，同时清理其 Casbin 策略
+func (u *RoleUsecase) Purge(ctx context.Context, req *request.RecycleIdsReq) error {
+	roles, err := u.findDeleted(ctx, req.Ids)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(roles))
+	for _, role := range roles {
+		keys = append(keys, role.Key)
+	}
+
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.Purge(ctx, req.Ids); err != nil {
+			return err
+		}
+		return u.casbinRepo.RemovePoliciesBySubject(ctx, keys...)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] purge role error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy()
+	return nil
+}
+
+func (u *RoleUsecase) findDeleted(ctx context.Context, ids []int64) ([]*model.Role, error) {
+	ids = uniqueInt64s(ids)
+	if len(ids) == 0 {
+		return nil, errorx.ErrInvalidParam
+	}
+	roles, err := u.roleRepo.FindDeletedByIds(ctx, ids)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindDeletedByIds error", zap.Any("ids", ids), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if len(roles) < len(ids) {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] deleted roles not found", zap.Any("ids", ids))
+		return nil, errorx.ErrRoleNotFound
+	}
+	return roles, nil
+}
+
+// Clone 以现有角色为源创建新角色，并复制其直接分配的接口与菜单权限
+func (u *RoleUsecase) Clone(ctx context.Context, sourceId int64, req *request.CloneRoleReq) error {
+	source, err := u.roleRepo.FindByID(ctx, sourceId)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByID error", zap.Any("sourceId", sourceId), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	if source == nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("sourceId", sourceId))
+		return errorx.ErrRoleNotFound
+	}
+
+	exist, err := u.roleRepo.FindByKey(ctx, req.Key)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByKey error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	if exist != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role already exists", zap.Any("req", req))
+		return errorx.ErrRoleAlreadyExists
+	}
+
+	apiIds, err := u.roleApiRepo.GetApiIdsByRoleId(ctx, source.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleApiRepo.GetApiIdsByRoleId error", zap.Any("sourceId", sourceId), zap.Error(err))
+		return errorx.ErrInternal
+	}
+
+	menuIds, err := u.roleMenuRepo.GetMenuIdsByRoleId(ctx, source.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleMenuRepo.GetMenuIdsByRoleId error", zap.Any("sourceId", sourceId), zap.Error(err))
+		return errorx.ErrInternal
+	}
+
+	role := &model.Role{
+		Name:      req.Name,
+		Key:       req.Key,
+		Status:    model.RoleStatusEnable,
+		DataScope: source.DataScope,
+		Sort:      *req.Sort,
+		IsSystem:  model.RoleNotSystem,
+		Remark:    req.Remark,
+	}
+
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.Create(ctx, role); err != nil {
+			return err
+		}
+		if err := u.roleApiRepo.AssignApis(ctx, role.ID, apiIds); err != nil {
+			return err
+		}
+		if err := u.roleMenuRepo.AssignMenus(ctx, role.ID, menuIds); err != nil {
+			return err
+		}
+		return u.policy.sync(ctx, role)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] clone role error", zap.Any("sourceId", sourceId), zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.reloadPolicy()
+
+	return nil
+}
+
+// Diff 对比两个角色的有效 API 与菜单权限
+func (u *RoleUsecase) Diff(ctx context.Context, req *request.RoleDiffReq) (*reply.RoleDiffReply, error) {
+	roles, err := u.roleRepo.FindByIDs(ctx, []int64{req.SourceId, req.TargetId})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByIDs error", zap.Any("req", req), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+
+	var source, target *model.Role
+	for _, role := range roles {
+		if int64(role.ID) == req.SourceId {
+			source = role
+		}
+		if int64(role.ID) == req.TargetId {
+			target = role
+		}
+	}
+	if source == nil || target == nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("req", req))
+		return nil, errorx.ErrRoleNotFound
+	}
+
+	sourceApis, err := u.findRoleApis(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	targetApis, err := u.findRoleApis(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceMenus, err := u.findRoleMenus(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	targetMenus, err := u.findRoleMenus(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply.BuilderRoleDiffReply(source, target, sourceApis, targetApis, sourceMenus, targetMenus), nil
+}
+
+// findRoleMenus 查询角色已分配且仍存在的菜单
+func (u *RoleUsecase) findRoleMenus(ctx context.Context, role *model.Role) ([]*model.Menu, error) {
+	menuIds, err := u.roleMenuRepo.GetMenuIdsByRoleId(ctx, role.ID)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleMenuRepo.GetMenuIdsByRoleId error", zap.Any("roleId", role.ID), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if len(menuIds) == 0 {
+		return nil, nil
+	}
+
+	menus, err := u.menuRepo.FindByIds(ctx, menuIds)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] menuRepo.FindByIds error", zap.Any("menuIds", menuIds), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	return menus, nil
+}
+
+// ListPermissionTemplates 权限模板列表
+func (u *RoleUsecase) ListPermissionTemplates(ctx context.Context, req *request.PermissionTemplateListReq) (*reply.PageReply, error) {
+	templates, total, err := u.templateRepo.List(ctx, req)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] templateRepo.List error", zap.Any("req", req), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	return reply.BuilderPageReply(reply.BuilderPermissionTemplateList(templates), total, offset, limit), nil
+}
+
+// CreatePermissionTemplate 创建权限模板
+func (u *RoleUsecase) CreatePermissionTemplate(ctx context.Context, req *request.CreatePermissionTemplateReq) error {
+	exist, err := u.templateRepo.FindByName(ctx, req.Name)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] templateRepo.FindByName error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	if exist != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] permission template already exists", zap.Any("req", req))
+		return errorx.ErrPermissionTemplateAlreadyExists
+	}
+
+	if err := u.checkTemplatePermissions(ctx, req.ApiIds, req.MenuIds); err != nil {
+		return err
+	}
+
+	template := &model.PermissionTemplate{
+		Name:    req.Name,
+		Remark:  req.Remark,
+		ApiIds:  uniqueIds(req.ApiIds),
+		MenuIds: uniqueIds(req.MenuIds),
+	}
+	if err := u.templateRepo.Create(ctx, template); err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] templateRepo.Create error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	return nil
+}
+
+// UpdatePermissionTemplate 更新权限模板，已应用过该模板的角色不受影响
+func (u *RoleUsecase) UpdatePermissionTemplate(ctx context.Context, req *request.UpdatePermissionTemplateReq) error {
+	template, err := u.findPermissionTemplate(ctx, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != template.Name {
+		exist, err := u.templateRepo.FindByName(ctx, req.Name)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] templateRepo.FindByName error", zap.Any("req", req), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if exist != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] permission template already exists", zap.Any("req", req))
+			return errorx.ErrPermissionTemplateAlreadyExists
+		}
+	}
+
+	if err := u.checkTemplatePermissions(ctx, req.ApiIds, req.MenuIds); err != nil {
+		return err
+	}
+
+	template.Name = req.Name
+	template.Remark = req.Remark
+	template.ApiIds = uniqueIds(req.ApiIds)
+	template.MenuIds = uniqueIds(req.MenuIds)
+	if err := u.templateRepo.Update(ctx, template); err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] templateRepo.Update error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	return nil
+}
+
+// DeletePermissionTemplate 删除权限模板
+func (u *RoleUsecase) DeletePermissionTemplate(ctx context.Context, id int64) error {
+	if _, err := u.findPermissionTemplate(ctx, id); err != nil {
+		return err
+	}
+	if err := u.templateRepo.Delete(ctx, id); err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] templateRepo.Delete error", zap.Any("id", id), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	return nil
+}
+
+// ApplyPermissionTemplate 将模板权限应用到角色，Merge 时保留角色原有权限，否则覆盖
+func (u *RoleUsecase) ApplyPermissionTemplate(ctx context.Context, req *request.ApplyPermissionTemplateReq) error {
+	template, err := u.findPermissionTemplate(ctx, req.TemplateId)
+	if err != nil {
+		return err
+	}
+
+	roleIds := uniqueInt64s(req.RoleIds)
+	roles, err := u.roleRepo.FindByIDs(ctx, roleIds)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByIDs error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	if len(roles) != len(roleIds) {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.Any("req", req))
+		return errorx.ErrRoleNotFound
+	}
+	for _, role := range roles {
+		if role.IsSystem == model.RoleIsSystem {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] role is system", zap.Any("roleId", role.ID))
+			return errorx.ErrRoleIsSystem
+		}
+	}
+
+	// 模板创建后被删除的 API 与菜单直接忽略
+	templateApis, err := u.apiRepo.FindByIds(ctx, toInt64s(template.ApiIds))
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] apiRepo.FindByIds error", zap.Any("templateId", template.ID), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	templateMenus, err := u.menuRepo.FindByIds(ctx, template.MenuIds)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] menuRepo.FindByIds error", zap.Any("templateId", template.ID), zap.Error(err))
+		return errorx.ErrInternal
+	}
+
+	type rolePermissions struct {
+		role    *model.Role
+		apiIds  []uint64
+		menuIds []uint64
+	}
+	plans := make([]rolePermissions, 0, len(roles))
+	for _, role := range roles {
+		apis := templateApis
+		menus := templateMenus
+		if req.Merge {
+			roleApis, err := u.policy.directApis(ctx, role.ID)
+			if err != nil {
+				u.logger.WithContext(ctx).Error("[ RoleUsecase ] policy.directApis error", zap.Any("roleId", role.ID), zap.Error(err))
+				return errorx.ErrInternal
+			}
+			roleMenus, err := u.findRoleMenus(ctx, role)
+			if err != nil {
+				return err
+			}
+			apis = append(append([]*model.Api{}, roleApis...), templateApis...)
+			menus = append(append([]*model.Menu{}, roleMenus...), templateMenus...)
+		}
+
+		plan := rolePermissions{role: role}
+		for _, api := range apis {
+			plan.apiIds = append(plan.apiIds, api.ID)
+		}
+		for _, menu := range menus {
+			plan.menuIds = append(plan.menuIds, menu.ID)
+		}
+		plan.apiIds, plan.menuIds = uniqueIds(plan.apiIds), uniqueIds(plan.menuIds)
+		plans = append(plans, plan)
+	}
+
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		for _, plan := range plans {
+			if err := u.roleApiRepo.AssignApis(ctx, plan.role.ID, plan.apiIds); err != nil {
+				return err
+			}
+			if err := u.roleMenuRepo.AssignMenus(ctx, plan.role.ID, plan.menuIds); err != nil {
+				return err
+			}
+			if err := u.policy.sync(ctx, plan.role); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] apply permission template error", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrAddPoliciesFail
+	}
+	u.reloadPolicy()
+	u.menuTreeCache.InvalidateMenuTree(ctx)
+
+	return nil
+}
+
+func (u *RoleUsecase) findPermissionTemplate(ctx context.Context, id int64) (*model.PermissionTemplate, error) {
+	template, err := u.templateRepo.Find(ctx, id)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] templateRepo.Find error", zap.Any("id", id), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if template == nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] permission template not found", zap.Any("id", id))
+		return nil, errorx.ErrPermissionTemplateNotFound
+	}
+	return template, nil
+}
+
+// checkTemplatePermissions 校验模板中的 API 与菜单均存在
+func (u *RoleUsecase) checkTemplatePermissions(ctx context.Context, apiIds, menuIds []uint64) error {
+	apiIds, menuIds = uniqueIds(apiIds), uniqueIds(menuIds)
+	if len(apiIds) > 0 {
+		apis, err := u.apiRepo.FindByIds(ctx, toInt64s(apiIds))
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] apiRepo.FindByIds error", zap.Any("apiIds", apiIds), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if len(apis) != len(apiIds) {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] api not found", zap.Any("apiIds", apiIds))
+			return errorx.ErrApiNotFound
+		}
+	}
+
+	if len(menuIds) > 0 {
+		menus, err := u.menuRepo.FindByIds(ctx, menuIds)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] menuRepo.FindByIds error", zap.Any("menuIds", menuIds), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if len(menus) != len(menuIds) {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] menu not found", zap.Any("menuIds", menuIds))
+			return errorx.ErrMenuNotFound
+		}
+	}
+	return nil
+}
+
+// buildPolicies 将接口转换为角色的 Casbin 策略，按 path、method 去重
+// buildPolicies 合并多个来源的接口生成角色的 p 策略，禁用的接口不授权
+func buildPolicies(roleKey string, apiGroups ...[]*model.Api) [][]string {
+	var policies [][]string
+	seen := make(map[string]struct{})
+	for _, apis := range apiGroups {
+		for _, api := range apis {
+			if api.Status != model.ApiStatusEnable {
+				continue
+			}
+			key := api.Path + " " + api.Method
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			policies = append(policies, []string{roleKey, api.Path, api.Method})
+		}
+	}
+	return policies
+}
+
+func uniqueIds(ids []uint64) []uint64 {
+	result := make([]uint64, 0, len(ids))
+	seen := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+// uniqueInt64s 去重，重复的 ID 只能查到一条记录，按数量判断是否存在前需先去重
+func uniqueInt64s(ids []int64) []int64 {
+	return toInt64s(uniqueIds(toUint64s(ids)))
+}
+
+func toUint64s(ids []int64) []uint64 {
+	result := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, uint64(id))
+	}
+	return result
+}
+
+func toInt64s(ids []uint64) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, int64(id))
+	}
+	return result
+}
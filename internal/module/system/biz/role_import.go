@@ -0,0 +1,162 @@
+package biz
+
+import (
+	"context"
+	"server/internal/module/system/model"
+	"server/pkg/errorx"
+
+	"go.uber.org/zap"
+)
+
+// policyImport 导入文件解析后的授权关系，按角色编码、用户名归集
+type policyImport struct {
+	// roleApis 角色编码 -> 直接分配的接口
+	roleApis map[string][]*model.Api
+	// userRoles 用户名 -> 角色编码
+	userRoles map[string][]string
+}
+
+// ImportPolicies 导入 casbin dump 格式的授权规则：
+// "p, 角色编码, 路径, 方法" 写入 sys_role_api，"g, 用户名, 角色编码" 写入 sys_user_role，
+// 随后在同一事务内重新生成相关角色的 Casbin 策略。角色、接口或用户不存在时整体拒绝。
+// replace 为 true 时只覆盖文件中出现的角色的直接接口和用户的角色，其他角色、用户保持不变
+func (u *RoleUsecase) ImportPolicies(ctx context.Context, rules [][]string, replace bool) error {
+	parsed, err := u.parsePolicies(ctx, rules)
+	if err != nil {
+		return err
+	}
+
+	roleKeys := make([]string, 0, len(parsed.roleApis)+len(parsed.userRoles))
+	for key := range parsed.roleApis {
+		roleKeys = append(roleKeys, key)
+	}
+	for _, keys := range parsed.userRoles {
+		roleKeys = append(roleKeys, keys...)
+	}
+	roles, err := u.roleRepo.FindByKeys(ctx, roleKeys)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] roleRepo.FindByKeys error", zap.Strings("keys", roleKeys), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	roleByKey := make(map[string]*model.Role, len(roles))
+	for _, role := range roles {
+		roleByKey[role.Key] = role
+	}
+	for _, key := range roleKeys {
+		if _, ok := roleByKey[key]; !ok {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] role not found", zap.String("key", key))
+			return errorx.ErrRoleNotFound
+		}
+	}
+
+	userIds := make(map[string]uint64, len(parsed.userRoles))
+	for username := range parsed.userRoles {
+		user, err := u.userRepo.FindByUsername(ctx, username)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] userRepo.FindByUsername error", zap.String("username", username), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if user == nil {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] user not found", zap.String("username", username))
+			return errorx.ErrUserNotFound
+		}
+		userIds[username] = user.ID
+	}
+
+	var synced []*model.Role
+	err = u.transaction.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		for key, apis := range parsed.roleApis {
+			role := roleByKey[key]
+			apiIds := make([]uint64, 0, len(apis))
+			for _, api := range apis {
+				apiIds = append(apiIds, api.ID)
+			}
+			apiIds = uniqueIds(apiIds)
+			if replace {
+				err = u.roleApiRepo.AssignApis(ctx, role.ID, apiIds)
+			} else {
+				err = u.roleApiRepo.AddApis(ctx, role.ID, apiIds)
+			}
+			if err != nil {
+				return err
+			}
+			synced = append(synced, role)
+		}
+		for username, keys := range parsed.userRoles {
+			roleIds := make([]uint64, 0, len(keys))
+			for _, key := range keys {
+				roleIds = append(roleIds, roleByKey[key].ID)
+			}
+			roleIds = uniqueIds(roleIds)
+			if replace {
+				err = u.userRepo.SetRoles(ctx, userIds[username], roleIds)
+			} else {
+				err = u.userRepo.AddRoles(ctx, userIds[username], roleIds)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return u.policy.sync(ctx, synced...)
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] import policies error", zap.Int("rules", len(rules)), zap.Bool("replace", replace), zap.Error(err))
+		return errorx.ErrAddPoliciesFail
+	}
+	u.reloadPolicy()
+	if len(userIds) > 0 {
+		ids := make([]uint64, 0, len(userIds))
+		for _, id := range userIds {
+			ids = append(ids, id)
+		}
+		u.menuTreeCache.InvalidateUserMenuTree(ctx, ids...)
+	}
+
+	u.logger.WithContext(ctx).Info("[ RoleUsecase ] import policies success", zap.Int("roles", len(parsed.roleApis)), zap.Int("users", len(parsed.userRoles)), zap.Bool("replace", replace))
+	return nil
+}
+
+// parsePolicies 校验规则格式并把 p 规则解析为已存在的接口，接口不存在时返回 ErrApiNotFound
+func (u *RoleUsecase) parsePolicies(ctx context.Context, rules [][]string) (*policyImport, error) {
+	parsed := &policyImport{
+		roleApis:  make(map[string][]*model.Api),
+		userRoles: make(map[string][]string),
+	}
+	var wanted []*model.Api
+	subjects := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		switch {
+		case len(rule) == 4 && rule[0] == "p":
+			wanted = append(wanted, &model.Api{Path: rule[2], Method: rule[3]})
+			subjects = append(subjects, rule[1])
+		case len(rule) == 3 && rule[0] == "g":
+			parsed.userRoles[rule[1]] = append(parsed.userRoles[rule[1]], rule[2])
+		default:
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] invalid policy", zap.Strings("rule", rule))
+			return nil, errorx.ErrInvalidParam
+		}
+	}
+	if len(wanted) == 0 {
+		return parsed, nil
+	}
+
+	apis, err := u.apiRepo.FindByPathMethods(ctx, apiPathMethods(wanted))
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[ RoleUsecase ] apiRepo.FindByPathMethods error", zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	apiByKey := make(map[string]*model.Api, len(apis))
+	for _, api := range apis {
+		apiByKey[api.Method+" "+api.Path] = api
+	}
+	for i, want := range wanted {
+		api, ok := apiByKey[want.Method+" "+want.Path]
+		if !ok {
+			u.logger.WithContext(ctx).Error("[ RoleUsecase ] api not found", zap.String("path", want.Path), zap.String("method", want.Method))
+			return nil, errorx.ErrApiNotFound
+		}
+		parsed.roleApis[subjects[i]] = append(parsed.roleApis[subjects[i]], api)
+	}
+	return parsed, nil
+}
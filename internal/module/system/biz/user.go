@@ -18,31 +18,34 @@ import (
 )
 
 type UserUsecase struct {
-	logger   logger.Logger
-	userRepo repo.UserRepo
-	roleRepo repo.RoleRepo
+	logger        logger.Logger
+	userRepo      repo.UserRepo
+	roleRepo      repo.RoleRepo
+	menuTreeCache menuTreeCache
 }
 
 func NewUserUsecase(
 	logger logger.Logger,
 	userRepo repo.UserRepo,
 	roleRepo repo.RoleRepo,
+	menuTreeCache menuTreeCache,
 ) *UserUsecase {
 	return &UserUsecase{
-		logger:   logger,
-		userRepo: userRepo,
-		roleRepo: roleRepo,
+		logger:        logger,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		menuTreeCache: menuTreeCache,
 	}
 }
 
 func (u *UserUsecase) GetInfo(ctx context.Context, userId int) (*reply.GetUserInfoReply, error) {
 	user, err := u.userRepo.Find(ctx, int64(userId))
 	if err != nil {
-		u.logger.Error("[UserUsecase] userRepo.Find err", zap.Any("userId", userId), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.Find err", zap.Any("userId", userId), zap.Error(err))
 		return nil, err
 	}
 	if user == nil {
-		u.logger.Warn("[UserUsecase] userRepo.Find user not find", zap.Any("userId", userId), zap.Error(err))
+		u.logger.WithContext(ctx).Warn("[UserUsecase] userRepo.Find user not find", zap.Any("userId", userId), zap.Error(err))
 		return nil, errorx.ErrUserNotFound
 	}
 	return reply.BuilderGetUserInfoReply(user), nil
@@ -51,18 +54,18 @@ func (u *UserUsecase) GetInfo(ctx context.Context, userId int) (*reply.GetUserIn
 func (u *UserUsecase) Create(ctx context.Context, req *request.CreateUserReq) error {
 	roles, err := u.roleRepo.FindByKeys(ctx, req.RoleKey)
 	if err != nil {
-		u.logger.Error("[UserUsecase] roleRepo.FindByKeys err", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[UserUsecase] roleRepo.FindByKeys err", zap.Any("req", req), zap.Error(err))
 		return err
 	}
 	if len(roles) == 0 || len(roles) < len(req.RoleKey) {
-		u.logger.Error("[UserUsecase] roleRepo.FindByKeys roles is empty", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[UserUsecase] roleRepo.FindByKeys roles is empty", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrRoleNotFound
 	}
 
 	createUser := u.randomUser(roles, req.Username, pkg.HashPassword(req.Password))
 	err = u.userRepo.Create(ctx, createUser)
 	if err != nil {
-		u.logger.Error("[UserUsecase] userRepo.Create err", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.Create err", zap.Any("req", req), zap.Error(err))
 		return err
 	}
 
@@ -153,13 +156,14 @@ func (u *UserUsecase) List(ctx context.Context, req *request.UserListReq) (*repl
 }
 
 func (u *UserUsecase) Delete(ctx context.Context, req *request.DeleteUserReq) error {
-	users, err := u.userRepo.FindByIds(ctx, req.Ids)
+	ids := uniqueInt64s(req.Ids)
+	users, err := u.userRepo.FindByIds(ctx, ids)
 	if err != nil {
-		u.logger.Error("[UserUsecase] userRepo.FindByIds err", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindByIds err", zap.Any("req", req), zap.Error(err))
 		return err
 	}
-	if len(users) == 0 || len(users) < len(req.Ids) {
-		u.logger.Error("[UserUsecase] userRepo.FindByIds users is empty", zap.Any("req", req), zap.Error(err))
+	if len(users) == 0 || len(users) < len(ids) {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindByIds users is empty", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrUserNotFound
 	}
 
@@ -168,16 +172,171 @@ func (u *UserUsecase) Delete(ctx context.Context, req *request.DeleteUserReq) er
 		deleteUserIds = append(deleteUserIds, int64(user.ID))
 	}
 
-	if len(deleteUserIds) < len(req.Ids) {
-		u.logger.Error("[UserUsecase] userRepo.FindByIds users is empty", zap.Any("req", req), zap.Error(err))
+	if len(deleteUserIds) < len(ids) {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindByIds users is empty", zap.Any("req", req), zap.Error(err))
 		return errorx.ErrUserIsSystem
 	}
 
 	err = u.userRepo.BatchDelete(ctx, deleteUserIds)
 	if err != nil {
-		u.logger.Error("[UserUsecase] userRepo.Delete err", zap.Any("req", req), zap.Error(err))
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.Delete err", zap.Any("req", req), zap.Error(err))
 		return err
 	}
+	u.menuTreeCache.InvalidateUserMenuTree(ctx, toUint64s(deleteUserIds)...)
 
 	return nil
 }
+
+// RecycleList 回收站中的用户列表
+func (u *UserUsecase) RecycleList(ctx context.Context, req *request.RecycleListReq) (*reply.PageReply, error) {
+	users, total, err := u.userRepo.ListDeleted(ctx, req)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.ListDeleted err", zap.Any("req", req), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	offset, limit := req.BuilderOffsetAndLimit()
+	return reply.BuilderPageReply(reply.BuilderUserRecycleList(users), total, offset, limit), nil
+}
+
+// Restore 从回收站恢复用户，用户名或手机号已被其他用户占用时拒绝恢复
+func (u *UserUsecase) Restore(ctx context.Context, req *request.RecycleIdsReq) error {
+	users, err := u.findDeleted(ctx, req.Ids)
+	if err != nil {
+		return err
+	}
+
+	usernames := make(map[string]struct{}, len(users))
+	phones := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		if _, ok := usernames[user.Username]; ok {
+			return errorx.ErrUserConflict
+		}
+		usernames[user.Username] = struct{}{}
+
+		exist, err := u.userRepo.FindByUsername(ctx, user.Username)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindByUsername err", zap.Any("user", user.Username), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if exist != nil {
+			u.logger.WithContext(ctx).Warn("[UserUsecase] restore username conflict", zap.Any("user", user.Username))
+			return errorx.ErrUserConflict
+		}
+
+		if user.Phone == "" {
+			continue
+		}
+		if _, ok := phones[user.Phone]; ok {
+			return errorx.ErrUserConflict
+		}
+		phones[user.Phone] = struct{}{}
+
+		exist, err = u.userRepo.FindByPhone(ctx, user.Phone)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindByPhone err", zap.Any("phone", user.Phone), zap.Error(err))
+			return errorx.ErrInternal
+		}
+		if exist != nil {
+			u.logger.WithContext(ctx).Warn("[UserUsecase] restore phone conflict", zap.Any("phone", user.Phone))
+			return errorx.ErrUserConflict
+		}
+	}
+
+	if err := u.userRepo.Restore(ctx, req.Ids); err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.Restore err", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.menuTreeCache.InvalidateUserMenuTree(ctx, toUint64s(req.Ids)...)
+	return nil
+}
+
+// Purge 彻底删除回收站中的用户
+func (u *UserUsecase) Purge(ctx context.Context, req *request.RecycleIdsReq) error {
+	users, err := u.findDeleted(ctx, req.Ids)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.IsAdmin == model.UserIsSystem {
+			u.logger.WithContext(ctx).Error("[UserUsecase] purge system user", zap.Any("userId", user.ID))
+			return errorx.ErrUserIsSystem
+		}
+	}
+
+	if err := u.userRepo.Purge(ctx, req.Ids); err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.Purge err", zap.Any("req", req), zap.Error(err))
+		return errorx.ErrInternal
+	}
+	u.menuTreeCache.InvalidateUserMenuTree(ctx, toUint64s(req.Ids)...)
+	return nil
+}
+
+func (u *UserUsecase) findDeleted(ctx context.Context, ids []int64) ([]*model.User, error) {
+	ids = uniqueInt64s(ids)
+	if len(ids) == 0 {
+		return nil, errorx.ErrInvalidParam
+	}
+	users, err := u.userRepo.FindDeletedByIds(ctx, ids)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindDeletedByIds err", zap.Any("ids", ids), zap.Error(err))
+		return nil, errorx.ErrInternal
+	}
+	if len(users) < len(ids) {
+		u.logger.WithContext(ctx).Error("[UserUsecase] deleted users not found", zap.Any("ids", ids))
+		return nil, errorx.ErrUserNotFound
+	}
+	return users, nil
+}
+
+// CreateAdmin 创建超级管理员用户，用于命令行初始化或找回管理员
+func (u *UserUsecase) CreateAdmin(ctx context.Context, username, password, nickname string) error {
+	exist, err := u.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindByUsername err", zap.String("username", username), zap.Error(err))
+		return err
+	}
+	if exist != nil {
+		return errorx.ErrUserConflict
+	}
+
+	role, err := u.roleRepo.FindByKey(ctx, model.RoleKeyAdmin)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] roleRepo.FindByKey err", zap.Error(err))
+		return err
+	}
+	if role == nil {
+		return errorx.ErrAdminRoleNotFound
+	}
+
+	user := u.randomUser([]*model.Role{role}, username, pkg.HashPassword(password))
+	if nickname != "" {
+		user.Nickname = nickname
+	}
+	if err := u.userRepo.Create(ctx, user); err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.Create err", zap.String("username", username), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ResetPassword 重置用户密码
+func (u *UserUsecase) ResetPassword(ctx context.Context, username, password string) error {
+	user, err := u.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.FindByUsername err", zap.String("username", username), zap.Error(err))
+		return err
+	}
+	if user == nil {
+		return errorx.ErrUserNotFound
+	}
+
+	err = u.userRepo.Update(ctx, &model.User{
+		BaseModel: model.BaseModel{ID: user.ID},
+		Password:  pkg.HashPassword(password),
+	})
+	if err != nil {
+		u.logger.WithContext(ctx).Error("[UserUsecase] userRepo.Update err", zap.String("username", username), zap.Error(err))
+		return err
+	}
+	return nil
+}
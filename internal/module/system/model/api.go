@@ -3,13 +3,20 @@ package model
 type Api struct {
 	BaseModel
 	Name        string `gorm:"size:128;not null;comment:接口名称，比如 用户列表接口" json:"name"`
-	Path        string `gorm:"size:256;not null;uniqueIndex:uk_path_method;comment:接口路径，比如 /api/user" json:"path"`
-	Method      string `gorm:"size:16;not null;uniqueIndex:uk_path_method;comment:请求方法，比如 GET、POST" json:"method"`
+	Path        string `gorm:"size:256;not null;uniqueIndex:uk_api_path_method;comment:接口路径，比如 /api/user" json:"path"`
+	Method      string `gorm:"size:16;not null;uniqueIndex:uk_api_path_method;comment:请求方法，比如 GET、POST" json:"method"`
 	Description string `gorm:"size:512;not null;default:'';comment:接口描述" json:"description"`
 	Group       string `gorm:"size:64;not null;default:'';comment:接口分组，比如用户管理、订单管理" json:"group"`
 	Status      int64  `gorm:"type:tinyint(1);not null;default:1;comment:状态（1启用，0禁用）" json:"status"`
+
+	DeleteMark uint64 `gorm:"not null;default:0;uniqueIndex:uk_api_path_method;comment:软删除唯一标记（未删除为0，删除后为自身ID）" json:"-"`
 }
 
+const (
+	ApiStatusEnable  = 1
+	ApiStatusDisable = 0
+)
+
 func (m *Api) TableName() string {
 	return "sys_api"
 }
@@ -24,6 +31,8 @@ var ApiCol = struct {
 	Status      string
 	CreatedAt   string
 	UpdatedAt   string
+	DeletedAt   string
+	DeleteMark  string
 }{
 	ID:          "id",
 	Name:        "name",
@@ -34,4 +43,6 @@ var ApiCol = struct {
 	Status:      "status",
 	CreatedAt:   "created_at",
 	UpdatedAt:   "updated_at",
+	DeletedAt:   "deleted_at",
+	DeleteMark:  "delete_mark",
 }
@@ -1,10 +1,13 @@
 package model
 
+// Init 初始化及迁移记录，旧版以模块名称记录初始化标记，迁移以 模块:版本:步骤 记录
 type Init struct {
 	BaseModel
-	Name        string `gorm:"type:varchar(64);unique;not null;comment:模块名称"`
+	Name        string `gorm:"type:varchar(128);unique;not null;comment:模块名称或迁移名称"`
+	Module      string `gorm:"type:varchar(64);not null;default:'';comment:迁移所属模块"`
 	Initialized int64  `gorm:"not null;default:0;comment:是否已初始化"`
 	Version     string `gorm:"type:varchar(32);default:'';comment:初始化版本"`
+	Checksum    string `gorm:"type:varchar(64);not null;default:'';comment:迁移内容校验和"`
 	Description string `gorm:"type:varchar(255);default:'';comment:备注信息"`
 }
 
@@ -16,6 +19,7 @@ func (m *Init) TableName() string {
 	return "sys_init"
 }
 
+// 旧版初始化标记，迁移框架据此识别已初始化的旧库
 const (
 	InitInitialized    = 1
 	InitNotInitialized = 0
@@ -32,15 +36,19 @@ var InitCol = struct {
 	CreatedAt   string
 	UpdatedAt   string
 	Name        string
+	Module      string
 	Initialized string
 	Version     string
+	Checksum    string
 	Description string
 }{
 	ID:          "id",
 	CreatedAt:   "created_at",
 	UpdatedAt:   "updated_at",
 	Name:        "name",
+	Module:      "module",
 	Initialized: "initialized",
 	Version:     "version",
+	Checksum:    "checksum",
 	Description: "description",
 }
@@ -3,15 +3,17 @@ package model
 type Menu struct {
 	BaseModel
 
-	ParentID  uint64 `gorm:"not null;default:0" json:"parentId"`            // 父菜单 ID，顶级菜单为 0
-	Name      string `gorm:"size:64;not null;unique" json:"name"`           // 路由名称（唯一标识）
-	Title     string `gorm:"size:128;not null" json:"title"`                // 菜单标题
-	Path      string `gorm:"size:255;not null" json:"path"`                 // 路由路径
-	Component string `gorm:"size:255;not null;default:''" json:"component"` // 对应前端组件路径
-	Icon      string `gorm:"size:128;default:''" json:"icon"`               // 图标
-	Redirect  string `gorm:"size:255;default:''" json:"redirect"`           // 重定向路径
-	Link      string `gorm:"size:255;default:''" json:"link"`               // iframe 或外链地址
-	Roles     string `gorm:"type:text" json:"roles"`                        // 角色权限，逗号分隔
+	ParentID  uint64 `gorm:"not null;default:0" json:"parentId"`                    // 父菜单 ID，顶级菜单为 0
+	Type      string `gorm:"size:16;not null;default:'page'" json:"type"`           // 菜单类型：dir 目录，page 页面，button 按钮
+	AuthCode  string `gorm:"size:128;not null;default:''" json:"authCode"`          // 按钮权限标识，比如 user:add
+	Name      string `gorm:"size:64;not null;uniqueIndex:uk_menu_name" json:"name"` // 路由名称（唯一标识）
+	Title     string `gorm:"size:128;not null" json:"title"`                        // 菜单标题
+	Path      string `gorm:"size:255;not null" json:"path"`                         // 路由路径
+	Component string `gorm:"size:255;not null;default:''" json:"component"`         // 对应前端组件路径
+	Icon      string `gorm:"size:128;default:''" json:"icon"`                       // 图标
+	Redirect  string `gorm:"size:255;default:''" json:"redirect"`                   // 重定向路径
+	Link      string `gorm:"size:255;default:''" json:"link"`                       // iframe 或外链地址
+	Roles     string `gorm:"type:text" json:"roles"`                                // 角色权限，逗号分隔
 
 	IsIframe   int64  `gorm:"not null;default:0" json:"isIframe"`    // 是否 iframe 链接
 	Hidden     int64  `gorm:"not null;default:0" json:"hidden"`      // 是否隐藏菜单
@@ -24,17 +26,33 @@ type Menu struct {
 	ActivePath string `gorm:"size:255;default:''" json:"activePath"` // 激活的路径
 	Sort       int64  `gorm:"not null;default:0" json:"sort"`        // 排序字段
 	Status     int64  `gorm:"not null;default:1" json:"status"`      // 状态：1启用 0禁用
+
+	DeleteMark uint64 `gorm:"not null;default:0;uniqueIndex:uk_menu_name" json:"-"` // 软删除唯一标记（未删除为0，删除后为自身ID）
 }
 
 func (m *Menu) TableName() string {
 	return "sys_menu"
 }
 
+const (
+	MenuTypeDir    = "dir"
+	MenuTypePage   = "page"
+	MenuTypeButton = "button"
+)
+
+// IsButton 是否为按钮，按钮不作为路由节点，而是作为所属页面的权限标识
+func (m *Menu) IsButton() bool {
+	return m.Type == MenuTypeButton
+}
+
 var MenuCol = struct {
 	ID         string
 	CreatedAt  string
 	UpdatedAt  string
+	DeletedAt  string
 	ParentID   string
+	Type       string
+	AuthCode   string
 	Name       string
 	Title      string
 	Path       string
@@ -54,11 +72,15 @@ var MenuCol = struct {
 	ActivePath string
 	Sort       string
 	Status     string
+	DeleteMark string
 }{
 	ID:         "id",
 	CreatedAt:  "created_at",
 	UpdatedAt:  "updated_at",
+	DeletedAt:  "deleted_at",
 	ParentID:   "parent_id",
+	Type:       "type",
+	AuthCode:   "auth_code",
 	Name:       "name",
 	Title:      "title",
 	Path:       "path",
@@ -78,4 +100,5 @@ var MenuCol = struct {
 	ActivePath: "active_path",
 	Sort:       "sort",
 	Status:     "status",
+	DeleteMark: "delete_mark",
 }
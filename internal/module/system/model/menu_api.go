@@ -0,0 +1,11 @@
+package model
+
+// MenuApi 按钮与接口关联表，角色获得按钮权限时同时获得其关联接口的访问权限
+type MenuApi struct {
+	MenuID uint64 `gorm:"primaryKey;not null;comment:菜单ID" json:"menuId"`
+	ApiID  uint64 `gorm:"primaryKey;not null;index;comment:接口ID" json:"apiId"`
+}
+
+func (m *MenuApi) TableName() string {
+	return "sys_menu_api"
+}
@@ -0,0 +1,34 @@
+package model
+
+// PermissionTemplate 权限模板，保存一组 API 与菜单权限，可批量应用到角色
+type PermissionTemplate struct {
+	BaseModel
+	Name    string   `gorm:"size:64;not null;uniqueIndex:uk_permission_template_name;comment:模板名称" json:"name"`
+	Remark  string   `gorm:"size:255;default:'';not null;comment:备注信息" json:"remark"`
+	ApiIds  []uint64 `gorm:"type:json;serializer:json;comment:API ID列表" json:"apiIds"`
+	MenuIds []uint64 `gorm:"type:json;serializer:json;comment:菜单ID列表" json:"menuIds"`
+}
+
+func (m *PermissionTemplate) TableName() string {
+	return "sys_permission_template"
+}
+
+var PermissionTemplateCol = struct {
+	ID        string
+	CreatedAt string
+	UpdatedAt string
+	DeletedAt string
+	Name      string
+	Remark    string
+	ApiIds    string
+	MenuIds   string
+}{
+	ID:        "id",
+	CreatedAt: "created_at",
+	UpdatedAt: "updated_at",
+	DeletedAt: "deleted_at",
+	Name:      "name",
+	Remark:    "remark",
+	ApiIds:    "api_ids",
+	MenuIds:   "menu_ids",
+}
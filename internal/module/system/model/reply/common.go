@@ -6,3 +6,16 @@ type PageReply struct {
 	Total    int64 `json:"total"`
 	List     any   `json:"list"`
 }
+
+func BuilderPageReply(list any, total int64, offset, limit int) *PageReply {
+	page := 1
+	if limit > 0 {
+		page = offset/limit + 1
+	}
+	return &PageReply{
+		Page:     page,
+		PageSize: limit,
+		Total:    total,
+		List:     list,
+	}
+}
@@ -16,6 +16,8 @@ type MenuReply struct {
 	Name      string       `json:"name"`
 	Path      string       `json:"path"`
 	Component string       `json:"component,omitempty"`
+	Type      string       `json:"type"`
+	AuthCode  string       `json:"authCode,omitempty"`
 	Meta      *MenuMeta    `json:"meta"`
 	Status    int          `json:"status"`
 	UpdatedAt string       `json:"updatedAt"`
@@ -30,37 +32,40 @@ func BuilderListMenuReply(menus []*model.Menu) *ListMenuReply {
 	if menus == nil {
 		return &ListMenuReply{List: []*MenuReply{}}
 	}
-	return &ListMenuReply{List: buildMenuList(menus, 0)}
+	children := make(map[uint64][]*model.Menu)
+	for _, menu := range menus {
+		children[menu.ParentID] = append(children[menu.ParentID], menu)
+	}
+	return &ListMenuReply{List: buildMenuList(children, 0)}
 }
 
-func buildMenuList(menus []*model.Menu, parentID uint64) []*MenuReply {
+func buildMenuList(children map[uint64][]*model.Menu, parentID uint64) []*MenuReply {
 	var list []*MenuReply
-	for _, menu := range menus {
-		if menu.ParentID == parentID {
-			var roles []string
-			if menu.Roles != "" {
-				roles = strings.Split(menu.Roles, ",")
-			}
+	for _, menu := range children[parentID] {
+		var roles []string
+		if menu.Roles != "" {
+			roles = strings.Split(menu.Roles, ",")
+		}
 
-			item := &MenuReply{
-				ID:        int64(menu.ID),
-				Name:      menu.Name,
-				Path:      menu.Path,
-				Component: menu.Component,
-				Meta: &MenuMeta{
-					Title: menu.Title,
-					Icon:  menu.Icon,
-					Roles: roles,
-				},
-				Status:    int(menu.Status),
-				UpdatedAt: menu.UpdatedAt.Format("2006-01-02 15:04:05"),
-			}
-			children := buildMenuList(menus, menu.ID)
-			if len(children) > 0 {
-				item.Children = children
-			}
-			list = append(list, item)
+		item := &MenuReply{
+			ID:        int64(menu.ID),
+			Name:      menu.Name,
+			Path:      menu.Path,
+			Component: menu.Component,
+			Type:      menu.Type,
+			AuthCode:  menu.AuthCode,
+			Meta: &MenuMeta{
+				Title: menu.Title,
+				Icon:  menu.Icon,
+				Roles: roles,
+			},
+			Status:    int(menu.Status),
+			UpdatedAt: menu.UpdatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if items := buildMenuList(children, menu.ID); len(items) > 0 {
+			item.Children = items
 		}
+		list = append(list, item)
 	}
 	return list
 }
@@ -0,0 +1,27 @@
+package reply
+
+// MenuImportChange 已存在菜单的字段变化
+type MenuImportChange struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+}
+
+// MenuImportReply 菜单导入计划，Applied 为 false 时仅为预览，未写入数据库
+type MenuImportReply struct {
+	Applied   bool                `json:"applied"`
+	Created   []string            `json:"created"`
+	Updated   []*MenuImportChange `json:"updated"`
+	Unchanged []string            `json:"unchanged"`
+	RoleMenus []string            `json:"roleMenus"` // 新增的角色菜单关联，格式为 "角色编码:菜单名称"
+	Warnings  []string            `json:"warnings"`
+}
+
+func NewMenuImportReply() *MenuImportReply {
+	return &MenuImportReply{
+		Created:   []string{},
+		Updated:   []*MenuImportChange{},
+		Unchanged: []string{},
+		RoleMenus: []string{},
+		Warnings:  []string{},
+	}
+}
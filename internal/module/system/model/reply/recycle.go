@@ -0,0 +1,63 @@
+package reply
+
+import "server/internal/module/system/model"
+
+// RecycleItem 回收站记录
+type RecycleItem struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`   // 展示名称
+	Detail    string `json:"detail"` // 辅助标识，如用户名、角色编码、接口路径
+	DeletedAt string `json:"deletedAt"`
+}
+
+func BuilderUserRecycleList(users []*model.User) []*RecycleItem {
+	list := make([]*RecycleItem, 0, len(users))
+	for _, user := range users {
+		list = append(list, &RecycleItem{
+			ID:        int64(user.ID),
+			Name:      user.Nickname,
+			Detail:    user.Username,
+			DeletedAt: user.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return list
+}
+
+func BuilderRoleRecycleList(roles []*model.Role) []*RecycleItem {
+	list := make([]*RecycleItem, 0, len(roles))
+	for _, role := range roles {
+		list = append(list, &RecycleItem{
+			ID:        int64(role.ID),
+			Name:      role.Name,
+			Detail:    role.Key,
+			DeletedAt: role.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return list
+}
+
+func BuilderApiRecycleList(apis []*model.Api) []*RecycleItem {
+	list := make([]*RecycleItem, 0, len(apis))
+	for _, api := range apis {
+		list = append(list, &RecycleItem{
+			ID:        int64(api.ID),
+			Name:      api.Name,
+			Detail:    api.Method + " " + api.Path,
+			DeletedAt: api.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return list
+}
+
+func BuilderMenuRecycleList(menus []*model.Menu) []*RecycleItem {
+	list := make([]*RecycleItem, 0, len(menus))
+	for _, menu := range menus {
+		list = append(list, &RecycleItem{
+			ID:        int64(menu.ID),
+			Name:      menu.Title,
+			Detail:    menu.Name,
+			DeletedAt: menu.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return list
+}
@@ -69,3 +69,132 @@ func BuilderListRoleReply(roles []*model.Role, total int64, page, pageSize int64
 		PageSize: pageSize,
 	}
 }
+
+type PermissionTemplateReply struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Remark    string   `json:"remark"`
+	ApiIds    []uint64 `json:"apiIds"`
+	MenuIds   []uint64 `json:"menuIds"`
+	CreatedAt string   `json:"createdAt"`
+	UpdatedAt string   `json:"updatedAt"`
+}
+
+func BuilderPermissionTemplateList(templates []*model.PermissionTemplate) []*PermissionTemplateReply {
+	list := make([]*PermissionTemplateReply, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, &PermissionTemplateReply{
+			ID:        int64(t.ID),
+			Name:      t.Name,
+			Remark:    t.Remark,
+			ApiIds:    t.ApiIds,
+			MenuIds:   t.MenuIds,
+			CreatedAt: t.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt: t.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return list
+}
+
+type RoleDiffApiItem struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Method string `json:"method"`
+}
+
+type RoleDiffMenuItem struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Title string `json:"title"`
+}
+
+type RoleApiDiff struct {
+	OnlySource []*RoleDiffApiItem `json:"onlySource"`
+	OnlyTarget []*RoleDiffApiItem `json:"onlyTarget"`
+	Common     []*RoleDiffApiItem `json:"common"`
+}
+
+type RoleMenuDiff struct {
+	OnlySource []*RoleDiffMenuItem `json:"onlySource"`
+	OnlyTarget []*RoleDiffMenuItem `json:"onlyTarget"`
+	Common     []*RoleDiffMenuItem `json:"common"`
+}
+
+// RoleDiffReply 两个角色的有效 API 与菜单权限对比结果
+type RoleDiffReply struct {
+	Source *RoleReply    `json:"source"`
+	Target *RoleReply    `json:"target"`
+	Apis   *RoleApiDiff  `json:"apis"`
+	Menus  *RoleMenuDiff `json:"menus"`
+}
+
+func BuilderRoleDiffReply(source, target *model.Role, sourceApis, targetApis []*model.Api, sourceMenus, targetMenus []*model.Menu) *RoleDiffReply {
+	result := &RoleDiffReply{
+		Source: builderRoleReply(source),
+		Target: builderRoleReply(target),
+		Apis: &RoleApiDiff{
+			OnlySource: []*RoleDiffApiItem{},
+			OnlyTarget: []*RoleDiffApiItem{},
+			Common:     []*RoleDiffApiItem{},
+		},
+		Menus: &RoleMenuDiff{
+			OnlySource: []*RoleDiffMenuItem{},
+			OnlyTarget: []*RoleDiffMenuItem{},
+			Common:     []*RoleDiffMenuItem{},
+		},
+	}
+
+	targetApiSet := make(map[uint64]struct{}, len(targetApis))
+	for _, api := range targetApis {
+		targetApiSet[api.ID] = struct{}{}
+	}
+	sourceApiSet := make(map[uint64]struct{}, len(sourceApis))
+	for _, api := range sourceApis {
+		sourceApiSet[api.ID] = struct{}{}
+		item := &RoleDiffApiItem{ID: int64(api.ID), Name: api.Name, Path: api.Path, Method: api.Method}
+		if _, ok := targetApiSet[api.ID]; ok {
+			result.Apis.Common = append(result.Apis.Common, item)
+		} else {
+			result.Apis.OnlySource = append(result.Apis.OnlySource, item)
+		}
+	}
+	for _, api := range targetApis {
+		if _, ok := sourceApiSet[api.ID]; !ok {
+			result.Apis.OnlyTarget = append(result.Apis.OnlyTarget, &RoleDiffApiItem{ID: int64(api.ID), Name: api.Name, Path: api.Path, Method: api.Method})
+		}
+	}
+
+	targetMenuSet := make(map[uint64]struct{}, len(targetMenus))
+	for _, menu := range targetMenus {
+		targetMenuSet[menu.ID] = struct{}{}
+	}
+	sourceMenuSet := make(map[uint64]struct{}, len(sourceMenus))
+	for _, menu := range sourceMenus {
+		sourceMenuSet[menu.ID] = struct{}{}
+		item := &RoleDiffMenuItem{ID: int64(menu.ID), Name: menu.Name, Title: menu.Title}
+		if _, ok := targetMenuSet[menu.ID]; ok {
+			result.Menus.Common = append(result.Menus.Common, item)
+		} else {
+			result.Menus.OnlySource = append(result.Menus.OnlySource, item)
+		}
+	}
+	for _, menu := range targetMenus {
+		if _, ok := sourceMenuSet[menu.ID]; !ok {
+			result.Menus.OnlyTarget = append(result.Menus.OnlyTarget, &RoleDiffMenuItem{ID: int64(menu.ID), Name: menu.Name, Title: menu.Title})
+		}
+	}
+
+	return result
+}
+
+func builderRoleReply(role *model.Role) *RoleReply {
+	return &RoleReply{
+		ID:          int64(role.ID),
+		Name:        role.Name,
+		Key:         role.Key,
+		Status:      int(role.Status),
+		Description: role.Remark,
+		CreatedAt:   role.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
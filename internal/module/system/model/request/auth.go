@@ -6,7 +6,7 @@ type LoginReq struct {
 }
 
 type RegisterReq struct {
-	Phone    string `json:"phone" validate:"required,len=11,numeric"`
+	Phone    string `json:"phone" validate:"required,phone"`
 	Password string `json:"password" validate:"required,min=6,max=128"`
 	Nickname string `json:"nickname" validate:"required,min=2,max=50"`
 }
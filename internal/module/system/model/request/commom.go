@@ -13,3 +13,14 @@ func (p *PageInfo) BuilderOffsetAndLimit() (offset, limit int) {
 	limit = int(*p.PageSize)
 	return
 }
+
+// RecycleListReq 回收站列表请求
+type RecycleListReq struct {
+	PageInfo
+	Keyword string `json:"keyword" form:"keyword"`
+}
+
+// RecycleIdsReq 回收站恢复/彻底删除请求
+type RecycleIdsReq struct {
+	Ids []int64 `json:"ids" validate:"required,min=1,dive,gt=0"`
+}
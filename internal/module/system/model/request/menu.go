@@ -8,9 +8,9 @@ type MenuListReq struct {
 
 type MenuCreateReq struct {
 	ParentID   uint64 `json:"parentId"`
-	Name       string `json:"name" binding:"required"`
-	Title      string `json:"title" binding:"required"`
-	Path       string `json:"path" binding:"required"`
+	Name       string `json:"name" validate:"required"`
+	Title      string `json:"title" validate:"required"`
+	Path       string `json:"path" validate:"required"`
 	Component  string `json:"component"`
 	Icon       string `json:"icon"`
 	Redirect   string `json:"redirect"`
@@ -30,10 +30,33 @@ type MenuCreateReq struct {
 }
 
 type MenuUpdateReq struct {
-	ID uint64 `json:"id" binding:"required"`
+	ID uint64 `json:"id" validate:"required"`
 	MenuCreateReq
 }
 
 type MenuDeleteReq struct {
-	ID uint64 `json:"id" binding:"required"`
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// 按钮绑定接口请求
+type BindMenuApisReq struct {
+	MenuId uint64   `json:"menuId" validate:"required"` // 按钮菜单ID
+	ApiIds []uint64 `json:"apiIds"`                     // 接口ID列表
+}
+
+// 删除菜单时子菜单的处理方式
+const (
+	MenuDeleteChildrenCascade  = "cascade"  // 连同子菜单一并删除
+	MenuDeleteChildrenReparent = "reparent" // 子菜单挂到被删除菜单的上级
+)
+
+// 菜单拖拽排序请求，Items 为拖拽后受影响菜单的新位置
+type MenuSortReq struct {
+	Items []MenuSortItem `json:"items" validate:"required,min=1,dive"`
+}
+
+type MenuSortItem struct {
+	ID       uint64 `json:"id" validate:"required"`
+	ParentID uint64 `json:"parentId"`
+	Sort     int64  `json:"sort"`
 }
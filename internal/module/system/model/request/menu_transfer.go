@@ -0,0 +1,38 @@
+package request
+
+// MenuTransferVersion 菜单导入导出文件格式版本
+const MenuTransferVersion = 1
+
+// MenuTransferDoc 菜单导入导出文件，菜单以 Name 作为跨环境的唯一标识，不包含数据库 ID
+type MenuTransferDoc struct {
+	Version int                 `json:"version" yaml:"version"`
+	Menus   []*MenuTransferItem `json:"menus" yaml:"menus"`
+}
+
+type MenuTransferItem struct {
+	Name       string `json:"name" yaml:"name"`
+	ParentName string `json:"parentName,omitempty" yaml:"parentName,omitempty"` // 上级菜单名称，顶级菜单为空
+	Type       string `json:"type" yaml:"type"`
+	AuthCode   string `json:"authCode,omitempty" yaml:"authCode,omitempty"`
+	Title      string `json:"title" yaml:"title"`
+	Path       string `json:"path" yaml:"path"`
+	Component  string `json:"component,omitempty" yaml:"component,omitempty"`
+	Icon       string `json:"icon,omitempty" yaml:"icon,omitempty"`
+	Redirect   string `json:"redirect,omitempty" yaml:"redirect,omitempty"`
+	Link       string `json:"link,omitempty" yaml:"link,omitempty"`
+	Roles      string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	IsIframe   int64  `json:"isIframe" yaml:"isIframe"`
+	Hidden     int64  `json:"hidden" yaml:"hidden"`
+	HideTab    int64  `json:"hideTab" yaml:"hideTab"`
+	KeepAlive  int64  `json:"keepAlive" yaml:"keepAlive"`
+	FullPage   int64  `json:"fullPage" yaml:"fullPage"`
+	FixedTab   int64  `json:"fixedTab" yaml:"fixedTab"`
+	ShowBadge  int64  `json:"showBadge" yaml:"showBadge"`
+	TextBadge  string `json:"textBadge,omitempty" yaml:"textBadge,omitempty"`
+	ActivePath string `json:"activePath,omitempty" yaml:"activePath,omitempty"`
+	Sort       int64  `json:"sort" yaml:"sort"`
+	Status     int64  `json:"status" yaml:"status"`
+
+	RoleKeys []string `json:"roleKeys,omitempty" yaml:"roleKeys,omitempty"` // 拥有该菜单的角色编码
+	Apis     []string `json:"apis,omitempty" yaml:"apis,omitempty"`         // 按钮关联的接口，格式为 "METHOD /path"
+}
@@ -40,3 +40,44 @@ type AssignMenuPermissionsReq struct {
 	MenuIds         []uint64 `json:"menuIds" validate:"required"`        // 菜单ID列表
 	HalfCheckedKeys []uint64 `json:"halfCheckedKeys"`                    // 半选中的节点（父节点）
 }
+
+// 克隆角色请求，复制源角色的 API 与菜单权限
+type CloneRoleReq struct {
+	Name   string `json:"name" validate:"required"`
+	Key    string `json:"key" validate:"required"`
+	Remark string `json:"remark"`
+	Sort   *int64 `json:"sort" validate:"required,notzero"`
+}
+
+// 角色权限对比请求
+type RoleDiffReq struct {
+	SourceId int64 `json:"sourceId" form:"sourceId" validate:"required,notzero"` // 源角色ID
+	TargetId int64 `json:"targetId" form:"targetId" validate:"required,notzero"` // 目标角色ID
+}
+
+type PermissionTemplateListReq struct {
+	PageInfo
+	Name string `json:"name" form:"name"`
+}
+
+type CreatePermissionTemplateReq struct {
+	Name    string   `json:"name" validate:"required"`
+	Remark  string   `json:"remark"`
+	ApiIds  []uint64 `json:"apiIds"`  // API ID列表
+	MenuIds []uint64 `json:"menuIds"` // 菜单ID列表
+}
+
+type UpdatePermissionTemplateReq struct {
+	Id      int64    `json:"id" validate:"required,notzero"`
+	Name    string   `json:"name" validate:"required"`
+	Remark  string   `json:"remark"`
+	ApiIds  []uint64 `json:"apiIds"`  // API ID列表
+	MenuIds []uint64 `json:"menuIds"` // 菜单ID列表
+}
+
+// 应用权限模板请求，Merge 为 true 时与角色现有权限合并，否则覆盖
+type ApplyPermissionTemplateReq struct {
+	TemplateId int64   `json:"templateId" validate:"required,notzero"`      // 模板ID
+	RoleIds    []int64 `json:"roleIds" validate:"required,min=1,dive,gt=0"` // 角色ID列表
+	Merge      bool    `json:"merge"`
+}
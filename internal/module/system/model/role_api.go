@@ -0,0 +1,12 @@
+package model
+
+// RoleApi 角色直接分配的接口。角色的 Casbin 策略由直接分配的接口与已分配按钮关联的接口合并生成，
+// 分别记录来源，回收按钮时不会误删直接分配的接口权限
+type RoleApi struct {
+	RoleID uint64 `gorm:"primaryKey;not null;comment:角色ID" json:"roleId"`
+	ApiID  uint64 `gorm:"primaryKey;not null;index;comment:接口ID" json:"apiId"`
+}
+
+func (m *RoleApi) TableName() string {
+	return "sys_role_api"
+}
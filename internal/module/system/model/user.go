@@ -5,11 +5,11 @@ import "time"
 type User struct {
 	BaseModel
 
-	Username string `gorm:"size:64;not null;uniqueIndex;comment:用户名" json:"username"`
+	Username string `gorm:"size:64;not null;uniqueIndex:uk_user_username;comment:用户名" json:"username"`
 	Password string `gorm:"size:128;not null;comment:密码" json:"-"`
 	Nickname string `gorm:"size:64;not null;default:'';comment:用户昵称" json:"nickname"`
 	Email    string `gorm:"size:128;not null;default:'';comment:邮箱" json:"email"`
-	Phone    string `gorm:"size:20;not null;uniqueIndex;comment:手机号" json:"phone"`
+	Phone    string `gorm:"size:20;not null;uniqueIndex:uk_user_phone;comment:手机号" json:"phone"`
 	Avatar   string `gorm:"size:255;not null;default:'';comment:头像URL" json:"avatar"`
 
 	Gender  int64 `gorm:"type:tinyint(1);not null;default:0;comment:性别（0未知 1男 2女）" json:"gender"`
@@ -33,6 +33,8 @@ type User struct {
 	LastLoginAt *time.Time `gorm:"comment:最后登录时间" json:"lastLoginAt"`
 	LastLoginIP string     `gorm:"size:45;not null;default:'';comment:最后登录IP" json:"lastLoginIP"`
 
+	DeleteMark uint64 `gorm:"not null;default:0;uniqueIndex:uk_user_username;uniqueIndex:uk_user_phone;comment:软删除唯一标记（未删除为0，删除后为自身ID）" json:"-"`
+
 	Roles []*Role `gorm:"many2many:sys_user_role;" json:"roles"`
 }
 
@@ -73,6 +75,7 @@ var UserCol = struct {
 	Tags        string
 	LastLoginAt string
 	LastLoginIP string
+	DeleteMark  string
 	Roles       string
 }{
 	ID:          "id",
@@ -98,5 +101,6 @@ var UserCol = struct {
 	Tags:        "tags",
 	LastLoginAt: "last_login_at",
 	LastLoginIP: "last_login_ip",
+	DeleteMark:  "delete_mark",
 	Roles:       "Roles",
 }
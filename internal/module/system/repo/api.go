@@ -19,23 +19,23 @@ func NewApiRepo(systemDB *mysql.SystemDB) repo.ApiRepo {
 }
 
 func (r *apiRepo) Create(ctx context.Context, api *model.Api) error {
-	err := r.db.WithContext(ctx).Create(api).Error
+	err := getDB(ctx, r.db).Create(api).Error
 	return errors.WithStack(err)
 }
 
 func (r *apiRepo) Delete(ctx context.Context, id int64) error {
-	err := r.db.WithContext(ctx).Delete(&model.Api{}, id).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, r.db, &model.Api{}, []int64{id})
 }
 
+// Update 保存接口全部字段，包括禁用时的零值状态
 func (r *apiRepo) Update(ctx context.Context, api *model.Api) error {
-	err := r.db.WithContext(ctx).Updates(api).Error
+	err := getDB(ctx, r.db).Save(api).Error
 	return errors.WithStack(err)
 }
 
 func (r *apiRepo) Find(ctx context.Context, id int64) (*model.Api, error) {
 	var api model.Api
-	err := r.db.WithContext(ctx).First(&api, id).Error
+	err := getDB(ctx, r.db).First(&api, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -51,7 +51,7 @@ func (r *apiRepo) List(ctx context.Context, req *request.ApiListReq) ([]*model.A
 		total int64
 	)
 
-	db := r.db.WithContext(ctx).Model(&model.Api{})
+	db := getDB(ctx, r.db).Model(&model.Api{})
 
 	if req.Path != "" {
 		db = db.Where("path LIKE ?", "%"+req.Path+"%")
@@ -77,18 +77,17 @@ func (r *apiRepo) List(ctx context.Context, req *request.ApiListReq) ([]*model.A
 }
 
 func (r *apiRepo) BatchDelete(ctx context.Context, ids []int64) error {
-	err := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.Api{}).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, r.db, &model.Api{}, ids)
 }
 
 func (r *apiRepo) FindByIds(ctx context.Context, ids []int64) ([]*model.Api, error) {
 	var apis []*model.Api
-	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&apis).Error
+	err := getDB(ctx, r.db).Where("id IN ?", ids).Find(&apis).Error
 	return apis, errors.WithStack(err)
 }
 
 func (r *apiRepo) BatchCreate(ctx context.Context, list []*model.Api) error {
-	err := r.db.WithContext(ctx).Create(&list).Error
+	err := getDB(ctx, r.db).Create(&list).Error
 	return errors.WithStack(err)
 }
 
@@ -101,7 +100,7 @@ func (r *apiRepo) FindByPathMethods(ctx context.Context, pathMethods []struct {
 	}
 
 	var apis []*model.Api
-	db := r.db.WithContext(ctx)
+	db := getDB(ctx, r.db)
 
 	// 构建 OR 查询条件
 	for i, pm := range pathMethods {
@@ -115,3 +114,34 @@ func (r *apiRepo) FindByPathMethods(ctx context.Context, pathMethods []struct {
 	err := db.Find(&apis).Error
 	return apis, errors.WithStack(err)
 }
+
+func (r *apiRepo) ListDeleted(ctx context.Context, req *request.RecycleListReq) ([]*model.Api, int64, error) {
+	offset, limit := req.BuilderOffsetAndLimit()
+	return listDeleted[model.Api](ctx, r.db, &model.Api{}, offset, limit, func(db *gorm.DB) *gorm.DB {
+		if req.Keyword == "" {
+			return db
+		}
+		return db.Where(model.ApiCol.Name+" LIKE ? OR "+model.ApiCol.Path+" LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%")
+	})
+}
+
+func (r *apiRepo) FindDeletedByIds(ctx context.Context, ids []int64) ([]*model.Api, error) {
+	return findDeleted[model.Api](ctx, r.db, ids)
+}
+
+func (r *apiRepo) Restore(ctx context.Context, ids []int64) error {
+	return restoreDeleted(ctx, r.db, &model.Api{}, ids)
+}
+
+// Purge 彻底删除接口并级联清理 sys_menu_api、sys_role_api 关联
+func (r *apiRepo) Purge(ctx context.Context, ids []int64) error {
+	return getDB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("api_id IN ?", ids).Delete(&model.MenuApi{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if err := tx.Where("api_id IN ?", ids).Delete(&model.RoleApi{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return purgeDeleted(ctx, tx, &model.Api{}, ids)
+	})
+}
@@ -1,10 +1,19 @@
 package repo
 
 import (
+	"context"
 	"server/internal/core/mysql"
 	"server/internal/module/system/biz/repo"
 
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	casbinPtypePolicy = "p"
+	casbinPtypeGroup  = "g"
 )
 
 type casbinRepo struct {
@@ -20,3 +29,80 @@ func NewCasbinRepo(systemDB *mysql.SystemDB) repo.CasbinRepo {
 func (cr *casbinRepo) AdapterDB() *gorm.DB {
 	return cr.db
 }
+
+// RemovePoliciesBySubject 直接在 casbin_rule 表中删除指定主体的全部 p 策略，可参与外层事务
+func (cr *casbinRepo) RemovePoliciesBySubject(ctx context.Context, subjects ...string) error {
+	if len(subjects) == 0 {
+		return nil
+	}
+	err := getDB(ctx, cr.db).
+		Where("ptype = ? AND v0 IN ?", casbinPtypePolicy, subjects).
+		Delete(&gormadapter.CasbinRule{}).Error
+	return errors.WithStack(err)
+}
+
+// UpdateSubject 角色编码变更时迁移 p 策略的主体及 g 分组中的角色
+func (cr *casbinRepo) UpdateSubject(ctx context.Context, oldSub, newSub string) error {
+	if oldSub == newSub {
+		return nil
+	}
+	db := getDB(ctx, cr.db)
+	err := db.Model(&gormadapter.CasbinRule{}).
+		Where("ptype = ? AND v0 = ?", casbinPtypePolicy, oldSub).
+		Update("v0", newSub).Error
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = db.Model(&gormadapter.CasbinRule{}).
+		Where("ptype = ? AND v1 = ?", casbinPtypeGroup, oldSub).
+		Update("v1", newSub).Error
+	return errors.WithStack(err)
+}
+
+// AddPolicies 批量写入 p 策略，规则格式为 [sub, obj, act]，已存在的策略忽略
+func (cr *casbinRepo) AddPolicies(ctx context.Context, rules [][]string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	lines := make([]gormadapter.CasbinRule, 0, len(rules))
+	for _, rule := range rules {
+		line := gormadapter.CasbinRule{Ptype: casbinPtypePolicy}
+		fields := []*string{&line.V0, &line.V1, &line.V2, &line.V3, &line.V4, &line.V5}
+		for i := 0; i < len(rule) && i < len(fields); i++ {
+			*fields[i] = rule[i]
+		}
+		lines = append(lines, line)
+	}
+	err := getDB(ctx, cr.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&lines).Error
+	return errors.WithStack(err)
+}
+
+// RemovePolicies 按 [sub, obj, act] 精确删除 p 策略
+func (cr *casbinRepo) RemovePolicies(ctx context.Context, rules [][]string) error {
+	db := getDB(ctx, cr.db)
+	for _, rule := range rules {
+		if len(rule) < 3 {
+			continue
+		}
+		err := db.Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ?", casbinPtypePolicy, rule[0], rule[1], rule[2]).
+			Delete(&gormadapter.CasbinRule{}).Error
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// ListPolicies 直接从 casbin_rule 表读取全部 p 策略，格式为 [sub, obj, act]，不依赖内存中的 Enforcer
+func (cr *casbinRepo) ListPolicies(ctx context.Context) ([][]string, error) {
+	var lines []gormadapter.CasbinRule
+	err := getDB(ctx, cr.db).Where("ptype = ?", casbinPtypePolicy).Order("id").Find(&lines).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	policies := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		policies = append(policies, []string{line.V0, line.V1, line.V2})
+	}
+	return policies, nil
+}
@@ -1,15 +1,22 @@
 package repo
 
 import (
-	"errors"
+	"context"
+	"database/sql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
+	"server/internal/core/migrate"
 	"server/internal/core/mysql"
 	"server/internal/module/system/biz/repo"
 	"server/internal/module/system/model"
 )
 
+const (
+	migrateLockName    = "server:migrate"
+	migrateLockTimeout = 60 // 等待其他实例迁移完成的秒数
+)
+
 type initRepo struct {
 	db *gorm.DB
 }
@@ -27,30 +34,109 @@ func (r *initRepo) AutoMigrate(tables []schema.Tabler) error {
 	return nil
 }
 
-func (r *initRepo) IsInitialized(name string) (bool, error) {
-	var init model.Init
-	err := r.db.Where(model.InitCol.Name+" = ?", name).First(&init).Error
+// Prepare 同步 sys_init 表结构，迁移记录依赖其中的 module、checksum 字段
+func (r *initRepo) Prepare(ctx context.Context) error {
+	return r.db.WithContext(ctx).AutoMigrate(&model.Init{})
+}
+
+// Lock 使用 MySQL 命名锁保证同一时间只有一个实例执行迁移，锁与连接绑定，因此在独占连接上获取和释放
+func (r *initRepo) Lock(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var locked sql.NullInt64
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrateLockName, migrateLockTimeout).Row().Scan(&locked); err != nil {
+			return err
+		}
+		if !locked.Valid || locked.Int64 != 1 {
+			return migrate.ErrLocked
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", migrateLockName)
+		return fn(ctx)
+	})
+}
+
+func (r *initRepo) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return (&transaction{db: r.db}).InTx(ctx, fn)
+}
+
+func (r *initRepo) Records(ctx context.Context) (map[string]*migrate.Record, error) {
+	var inits []*model.Init
+	err := getDB(ctx, r.db).
+		Where(model.InitCol.Initialized+" = ?", model.InitInitialized).
+		Find(&inits).Error
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, nil //
+		return nil, err
+	}
+
+	records := make(map[string]*migrate.Record, len(inits))
+	for _, init := range inits {
+		records[init.Name] = &migrate.Record{
+			Name:        init.Name,
+			Module:      init.Module,
+			Version:     init.Version,
+			Checksum:    init.Checksum,
+			Description: init.Description,
+			AppliedAt:   init.UpdatedAt,
 		}
-		return false, err
 	}
-	return init.IsInitialized(), nil
+	return records, nil
 }
 
-func (r *initRepo) SetInitialized(name, version, description string) error {
+func (r *initRepo) Save(ctx context.Context, record *migrate.Record) error {
 	initRecord := model.Init{
-		Name:        name,
+		Name:        record.Name,
+		Module:      record.Module,
 		Initialized: model.InitInitialized,
-		Version:     version,
-		Description: description,
+		Version:     record.Version,
+		Checksum:    record.Checksum,
+		Description: record.Description,
 	}
 
-	return r.db.Clauses(
+	return getDB(ctx, r.db).Clauses(
 		clause.OnConflict{
-			Columns:   []clause.Column{{Name: "name"}},
-			DoUpdates: clause.AssignmentColumns([]string{"initialized", "version", "description", "updated_at"}),
+			Columns: []clause.Column{{Name: model.InitCol.Name}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				model.InitCol.Module, model.InitCol.Initialized, model.InitCol.Version,
+				model.InitCol.Checksum, model.InitCol.Description, model.InitCol.UpdatedAt,
+			}),
 		},
 	).Create(&initRecord).Error
 }
+
+// Remove 删除迁移记录，回滚后可重新执行
+func (r *initRepo) Remove(ctx context.Context, name string) error {
+	return getDB(ctx, r.db).
+		Unscoped().
+		Where(model.InitCol.Name+" = ?", name).
+		Delete(&model.Init{}).Error
+}
+
+// DropIndexes 删除存在的索引，用于清理已被替换的旧唯一索引
+func (r *initRepo) DropIndexes(table schema.Tabler, names ...string) error {
+	migrator := r.db.Migrator()
+	if !migrator.HasTable(table) {
+		return nil
+	}
+	for _, name := range names {
+		if !migrator.HasIndex(table, name) {
+			continue
+		}
+		if err := migrator.DropIndex(table, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackfillDeleteMark 为引入 delete_mark 前已软删除的记录补写标记
+func (r *initRepo) BackfillDeleteMark(tables []schema.Tabler) error {
+	for _, table := range tables {
+		err := r.db.Unscoped().
+			Model(table).
+			Where("deleted_at IS NOT NULL AND delete_mark = 0").
+			UpdateColumn("delete_mark", gorm.Expr("id")).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,23 +20,22 @@ func NewMenuRepo(systemDB *mysql.SystemDB) repo.MenuRepo {
 }
 
 func (m *menuRepo) Create(ctx context.Context, menu *model.Menu) error {
-	err := m.db.WithContext(ctx).Create(menu).Error
+	err := getDB(ctx, m.db).Create(menu).Error
 	return errors.WithStack(err)
 }
 
 func (m *menuRepo) Update(ctx context.Context, menu *model.Menu) error {
-	err := m.db.WithContext(ctx).Save(menu).Error
+	err := getDB(ctx, m.db).Save(menu).Error
 	return errors.WithStack(err)
 }
 
 func (m *menuRepo) Delete(ctx context.Context, id int64) error {
-	err := m.db.WithContext(ctx).Delete(&model.Menu{}, id).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, m.db, &model.Menu{}, []int64{id})
 }
 
 func (m *menuRepo) Find(ctx context.Context, id int64) (*model.Menu, error) {
 	var menu model.Menu
-	err := m.db.WithContext(ctx).First(&menu, id).Error
+	err := getDB(ctx, m.db).First(&menu, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // 没找到返回nil,nil
@@ -50,7 +49,7 @@ func (m *menuRepo) List(ctx context.Context, req *request.MenuListReq) ([]*model
 	var menus []*model.Menu
 	var total int64
 
-	db := m.db.WithContext(ctx).Model(&model.Menu{})
+	db := getDB(ctx, m.db).Model(&model.Menu{})
 
 	if req.Name != "" {
 		db = db.Where("name LIKE ?", "%"+req.Name+"%")
@@ -72,13 +71,12 @@ func (m *menuRepo) List(ctx context.Context, req *request.MenuListReq) ([]*model
 }
 
 func (m *menuRepo) BatchDelete(ctx context.Context, ids []int64) error {
-	err := m.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.Menu{}).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, m.db, &model.Menu{}, ids)
 }
 
 func (m *menuRepo) GetAllEnabled(ctx context.Context) ([]*model.Menu, error) {
 	var menus []*model.Menu
-	err := m.db.WithContext(ctx).Where("status = ?", 1).Order("sort ASC").Find(&menus).Error
+	err := getDB(ctx, m.db).Where("status = ?", 1).Order("sort ASC").Find(&menus).Error
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -87,9 +85,84 @@ func (m *menuRepo) GetAllEnabled(ctx context.Context) ([]*model.Menu, error) {
 
 func (m *menuRepo) GetAll(ctx context.Context) ([]*model.Menu, error) {
 	var menus []*model.Menu
-	err := m.db.WithContext(ctx).Order("id ASC").Find(&menus).Error
+	err := getDB(ctx, m.db).Order("id ASC").Find(&menus).Error
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return menus, nil
 }
+
+func (m *menuRepo) FindByName(ctx context.Context, name string) (*model.Menu, error) {
+	var menu model.Menu
+	err := getDB(ctx, m.db).Where(model.MenuCol.Name+" = ?", name).First(&menu).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &menu, nil
+}
+
+func (m *menuRepo) FindByIds(ctx context.Context, ids []uint64) ([]*model.Menu, error) {
+	var menus []*model.Menu
+	err := getDB(ctx, m.db).Where(model.MenuCol.ID+" IN ?", ids).Find(&menus).Error
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return menus, nil
+}
+
+// UpdatePosition 更新菜单在树中的位置（父菜单与排序）
+func (m *menuRepo) UpdatePosition(ctx context.Context, id, parentId uint64, sort int64) error {
+	err := getDB(ctx, m.db).
+		Model(&model.Menu{}).
+		Where(model.MenuCol.ID+" = ?", id).
+		Updates(map[string]interface{}{
+			model.MenuCol.ParentID: parentId,
+			model.MenuCol.Sort:     sort,
+		}).Error
+	return errors.WithStack(err)
+}
+
+func (m *menuRepo) UpdateParent(ctx context.Context, ids []uint64, parentId uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := getDB(ctx, m.db).
+		Model(&model.Menu{}).
+		Where(model.MenuCol.ID+" IN ?", ids).
+		Update(model.MenuCol.ParentID, parentId).Error
+	return errors.WithStack(err)
+}
+
+func (m *menuRepo) ListDeleted(ctx context.Context, req *request.RecycleListReq) ([]*model.Menu, int64, error) {
+	offset, limit := req.BuilderOffsetAndLimit()
+	return listDeleted[model.Menu](ctx, m.db, &model.Menu{}, offset, limit, func(db *gorm.DB) *gorm.DB {
+		if req.Keyword == "" {
+			return db
+		}
+		return db.Where(model.MenuCol.Name+" LIKE ? OR "+model.MenuCol.Title+" LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%")
+	})
+}
+
+func (m *menuRepo) FindDeletedByIds(ctx context.Context, ids []int64) ([]*model.Menu, error) {
+	return findDeleted[model.Menu](ctx, m.db, ids)
+}
+
+func (m *menuRepo) Restore(ctx context.Context, ids []int64) error {
+	return restoreDeleted(ctx, m.db, &model.Menu{}, ids)
+}
+
+// Purge 彻底删除菜单并级联清理 sys_role_menu、sys_menu_api 关联
+func (m *menuRepo) Purge(ctx context.Context, ids []int64) error {
+	return getDB(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_id IN ?", ids).Delete(&model.RoleMenu{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if err := tx.Where("menu_id IN ?", ids).Delete(&model.MenuApi{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return purgeDeleted(ctx, tx, &model.Menu{}, ids)
+	})
+}
@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/system/biz/repo"
+	"server/internal/module/system/model"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type menuApiRepo struct {
+	db *gorm.DB
+}
+
+func NewMenuApiRepo(systemDB *mysql.SystemDB) repo.MenuApiRepo {
+	return &menuApiRepo{db: systemDB.DB}
+}
+
+func (r *menuApiRepo) ReplaceApis(ctx context.Context, menuId uint64, apiIds []uint64) error {
+	db := getDB(ctx, r.db)
+	if err := db.Where("menu_id = ?", menuId).Delete(&model.MenuApi{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(apiIds) == 0 {
+		return nil
+	}
+
+	menuApis := make([]model.MenuApi, 0, len(apiIds))
+	for _, apiId := range apiIds {
+		menuApis = append(menuApis, model.MenuApi{MenuID: menuId, ApiID: apiId})
+	}
+	err := db.Create(&menuApis).Error
+	return errors.WithStack(err)
+}
+
+func (r *menuApiRepo) GetApiIdsByMenuIds(ctx context.Context, menuIds []uint64) ([]uint64, error) {
+	if len(menuIds) == 0 {
+		return nil, nil
+	}
+	var apiIds []uint64
+	err := getDB(ctx, r.db).
+		Model(&model.MenuApi{}).
+		Where("menu_id IN ?", menuIds).
+		Distinct().
+		Pluck("api_id", &apiIds).Error
+	return apiIds, errors.WithStack(err)
+}
+
+func (r *menuApiRepo) GetMenuIdsByApiIds(ctx context.Context, apiIds []uint64) ([]uint64, error) {
+	if len(apiIds) == 0 {
+		return nil, nil
+	}
+	var menuIds []uint64
+	err := getDB(ctx, r.db).
+		Model(&model.MenuApi{}).
+		Where("api_id IN ?", apiIds).
+		Distinct().
+		Pluck("menu_id", &menuIds).Error
+	return menuIds, errors.WithStack(err)
+}
+
+func (r *menuApiRepo) List(ctx context.Context) ([]*model.MenuApi, error) {
+	var menuApis []*model.MenuApi
+	err := getDB(ctx, r.db).Find(&menuApis).Error
+	return menuApis, errors.WithStack(err)
+}
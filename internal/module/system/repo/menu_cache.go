@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"context"
+	"server/internal/module/system/biz/repo"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	menuCacheKey  = "system:menu:tree"
+	menuCacheTTL  = 30 * time.Minute
+	menuCacheSize = 1024
+)
+
+func NewMenuCacheRepo(rdb *redis.Client) repo.MenuCacheRepo {
+	if rdb == nil {
+		return &menuLocalCache{lru: expirable.NewLRU[string, []byte](menuCacheSize, nil, menuCacheTTL)}
+	}
+	return &menuRedisCache{rdb: rdb}
+}
+
+// menuRedisCache 所有缓存项放在同一个 hash 中，多实例共享且可整体失效
+type menuRedisCache struct {
+	rdb *redis.Client
+}
+
+func (c *menuRedisCache) Get(ctx context.Context, field string) ([]byte, error) {
+	value, err := c.rdb.HGet(ctx, menuCacheKey, field).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	return value, nil
+}
+
+func (c *menuRedisCache) Set(ctx context.Context, field string, value []byte) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.HSet(ctx, menuCacheKey, field, value)
+	pipe.Expire(ctx, menuCacheKey, menuCacheTTL)
+	_, err := pipe.Exec(ctx)
+	return errors.WithStack(err)
+}
+
+func (c *menuRedisCache) Del(ctx context.Context, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return errors.WithStack(c.rdb.HDel(ctx, menuCacheKey, fields...).Err())
+}
+
+func (c *menuRedisCache) Clear(ctx context.Context) error {
+	return errors.WithStack(c.rdb.Del(ctx, menuCacheKey).Err())
+}
+
+type menuLocalCache struct {
+	lru *expirable.LRU[string, []byte]
+}
+
+func (c *menuLocalCache) Get(_ context.Context, field string) ([]byte, error) {
+	value, _ := c.lru.Get(field)
+	return value, nil
+}
+
+func (c *menuLocalCache) Set(_ context.Context, field string, value []byte) error {
+	c.lru.Add(field, value)
+	return nil
+}
+
+func (c *menuLocalCache) Del(_ context.Context, fields ...string) error {
+	for _, field := range fields {
+		c.lru.Remove(field)
+	}
+	return nil
+}
+
+func (c *menuLocalCache) Clear(_ context.Context) error {
+	c.lru.Purge()
+	return nil
+}
@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/system/biz/repo"
+	"server/internal/module/system/model"
+	"server/internal/module/system/model/request"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type permissionTemplateRepo struct {
+	db *gorm.DB
+}
+
+func NewPermissionTemplateRepo(systemDB *mysql.SystemDB) repo.PermissionTemplateRepo {
+	return &permissionTemplateRepo{db: systemDB.DB}
+}
+
+func (r *permissionTemplateRepo) Create(ctx context.Context, template *model.PermissionTemplate) error {
+	err := getDB(ctx, r.db).Create(template).Error
+	return errors.WithStack(err)
+}
+
+func (r *permissionTemplateRepo) Update(ctx context.Context, template *model.PermissionTemplate) error {
+	err := getDB(ctx, r.db).Save(template).Error
+	return errors.WithStack(err)
+}
+
+// Delete 模板不进回收站，直接物理删除以释放名称唯一索引
+func (r *permissionTemplateRepo) Delete(ctx context.Context, id int64) error {
+	err := getDB(ctx, r.db).Unscoped().Delete(&model.PermissionTemplate{}, id).Error
+	return errors.WithStack(err)
+}
+
+func (r *permissionTemplateRepo) Find(ctx context.Context, id int64) (*model.PermissionTemplate, error) {
+	var template model.PermissionTemplate
+	err := getDB(ctx, r.db).First(&template, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &template, nil
+}
+
+func (r *permissionTemplateRepo) FindByName(ctx context.Context, name string) (*model.PermissionTemplate, error) {
+	var template model.PermissionTemplate
+	err := getDB(ctx, r.db).Where(model.PermissionTemplateCol.Name+" = ?", name).First(&template).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &template, nil
+}
+
+func (r *permissionTemplateRepo) List(ctx context.Context, req *request.PermissionTemplateListReq) ([]*model.PermissionTemplate, int64, error) {
+	var (
+		templates []*model.PermissionTemplate
+		total     int64
+		db        = getDB(ctx, r.db).Model(&model.PermissionTemplate{})
+	)
+
+	if req.Name != "" {
+		db = db.Where(model.PermissionTemplateCol.Name+" LIKE ?", "%"+req.Name+"%")
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	offset, limit := req.BuilderOffsetAndLimit()
+	err := db.Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&templates).Error
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return templates, total, nil
+}
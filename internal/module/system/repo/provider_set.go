@@ -5,6 +5,7 @@ import (
 )
 
 var ProviderSet = wire.NewSet(
+	NewTransaction,
 	NewCasbinRepo,
 	NewInitRepo,
 	NewUserRepo,
@@ -12,4 +13,8 @@ var ProviderSet = wire.NewSet(
 	NewApiRepo,
 	NewMenuRepo,
 	NewRoleMenuRepo,
+	NewPermissionTemplateRepo,
+	NewMenuApiRepo,
+	NewRoleApiRepo,
+	NewMenuCacheRepo,
 )
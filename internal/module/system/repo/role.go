@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"server/internal/core/mysql"
 	"server/internal/module/system/biz/repo"
 	"server/internal/module/system/model"
@@ -19,15 +20,12 @@ func NewRoleRepo(systemDB *mysql.SystemDB) repo.RoleRepo {
 }
 
 func (r *roleRepo) Delete(ctx context.Context, id int64) error {
-	err := r.db.WithContext(ctx).
-		Where("id = ?", id).
-		Delete(&model.Role{}).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, r.db, &model.Role{}, []int64{id})
 }
 
 func (r *roleRepo) Update(ctx context.Context, role *model.Role) error {
 	// 建议使用 Select("*")，更新所有字段（包括零值）
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Model(&model.Role{}).
 		Where("id = ?", role.ID).
 		Select("*").
@@ -37,7 +35,7 @@ func (r *roleRepo) Update(ctx context.Context, role *model.Role) error {
 
 func (r *roleRepo) FindByID(ctx context.Context, id int64) (*model.Role, error) {
 	var role model.Role
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Where("id = ?", id).
 		First(&role).Error
 
@@ -54,7 +52,7 @@ func (r *roleRepo) List(ctx context.Context, req *request.RoleListReq) ([]*model
 	var (
 		roles []*model.Role
 		total int64
-		db    = r.db.WithContext(ctx).Model(&model.Role{})
+		db    = getDB(ctx, r.db).Model(&model.Role{})
 	)
 
 	if req.Name != "" {
@@ -80,20 +78,17 @@ func (r *roleRepo) List(ctx context.Context, req *request.RoleListReq) ([]*model
 }
 
 func (r *roleRepo) BatchDelete(ctx context.Context, ids []int64) error {
-	err := r.db.WithContext(ctx).
-		Where("id IN ?", ids).
-		Delete(&model.Role{}).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, r.db, &model.Role{}, ids)
 }
 
 func (r *roleRepo) Create(ctx context.Context, role *model.Role) error {
-	err := r.db.WithContext(ctx).Create(role).Error
+	err := getDB(ctx, r.db).Create(role).Error
 	return errors.WithStack(err)
 }
 
 func (r *roleRepo) FindByKey(ctx context.Context, key string) (*model.Role, error) {
 	var role model.Role
-	err := r.db.WithContext(ctx).Where(model.RoleCol.Key+" = ?", key).First(&role).Error
+	err := getDB(ctx, r.db).Where(model.RoleCol.Key+" = ?", key).First(&role).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -105,7 +100,7 @@ func (r *roleRepo) FindByKey(ctx context.Context, key string) (*model.Role, erro
 
 func (r *roleRepo) FindByIDs(ctx context.Context, ids []int64) ([]*model.Role, error) {
 	var roles []*model.Role
-	err := r.db.WithContext(ctx).Where(model.RoleCol.ID+" IN ?", ids).Find(&roles).Error
+	err := getDB(ctx, r.db).Where(model.RoleCol.ID+" IN ?", ids).Find(&roles).Error
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -114,9 +109,56 @@ func (r *roleRepo) FindByIDs(ctx context.Context, ids []int64) ([]*model.Role, e
 
 func (r *roleRepo) FindByKeys(ctx context.Context, keys []string) ([]*model.Role, error) {
 	var roles []*model.Role
-	err := r.db.WithContext(ctx).Where(model.RoleCol.Key+" IN ?", keys).Find(&roles).Error
+	err := getDB(ctx, r.db).Where(model.RoleCol.Key+" IN ?", keys).Find(&roles).Error
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return roles, nil
 }
+
+func (r *roleRepo) ListDeleted(ctx context.Context, req *request.RecycleListReq) ([]*model.Role, int64, error) {
+	offset, limit := req.BuilderOffsetAndLimit()
+	return listDeleted[model.Role](ctx, r.db, &model.Role{}, offset, limit, func(db *gorm.DB) *gorm.DB {
+		if req.Keyword == "" {
+			return db
+		}
+		return db.Where(model.RoleCol.Name+" LIKE ? OR "+model.RoleCol.Key+" LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%")
+	})
+}
+
+func (r *roleRepo) FindDeletedByIds(ctx context.Context, ids []int64) ([]*model.Role, error) {
+	return findDeleted[model.Role](ctx, r.db, ids)
+}
+
+// Restore 恢复角色，软删除时保留了用户、菜单及接口关联，恢复后即可生效
+func (r *roleRepo) Restore(ctx context.Context, ids []int64) error {
+	return restoreDeleted(ctx, r.db, &model.Role{}, ids)
+}
+
+// Purge 彻底删除角色并级联清理 sys_user_role、sys_role_menu、sys_role_api 关联
+func (r *roleRepo) Purge(ctx context.Context, ids []int64) error {
+	return getDB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id IN ?", ids).Delete(&model.UserRole{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if err := tx.Where("role_id IN ?", ids).Delete(&model.RoleMenu{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		if err := tx.Where("role_id IN ?", ids).Delete(&model.RoleApi{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return purgeDeleted(ctx, tx, &model.Role{}, ids)
+	})
+}
+
+// CountUsers 统计仍持有指定角色的未删除用户数，在事务内调用时对关联行加共享锁，提交前其他事务无法修改
+func (r *roleRepo) CountUsers(ctx context.Context, roleIds []int64) (int64, error) {
+	var total int64
+	err := getDB(ctx, r.db).
+		Clauses(clause.Locking{Strength: "SHARE"}).
+		Model(&model.UserRole{}).
+		Joins("JOIN "+(&model.User{}).TableName()+" u ON u.id = "+(&model.UserRole{}).TableName()+".user_id").
+		Where((&model.UserRole{}).TableName()+".role_id IN ? AND u.deleted_at IS NULL", roleIds).
+		Count(&total).Error
+	return total, errors.WithStack(err)
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/system/biz/repo"
+	"server/internal/module/system/model"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type roleApiRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleApiRepo(systemDB *mysql.SystemDB) repo.RoleApiRepo {
+	return &roleApiRepo{db: systemDB.DB}
+}
+
+func (r *roleApiRepo) AssignApis(ctx context.Context, roleId uint64, apiIds []uint64) error {
+	if err := getDB(ctx, r.db).Where("role_id = ?", roleId).Delete(&model.RoleApi{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return r.AddApis(ctx, roleId, apiIds)
+}
+
+func (r *roleApiRepo) AddApis(ctx context.Context, roleId uint64, apiIds []uint64) error {
+	if len(apiIds) == 0 {
+		return nil
+	}
+	roleApis := make([]model.RoleApi, 0, len(apiIds))
+	for _, apiId := range apiIds {
+		roleApis = append(roleApis, model.RoleApi{RoleID: roleId, ApiID: apiId})
+	}
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&roleApis).Error
+	return errors.WithStack(err)
+}
+
+func (r *roleApiRepo) RemoveApis(ctx context.Context, roleId uint64, apiIds []uint64) error {
+	if len(apiIds) == 0 {
+		return nil
+	}
+	err := getDB(ctx, r.db).
+		Where("role_id = ? AND api_id IN ?", roleId, apiIds).
+		Delete(&model.RoleApi{}).Error
+	return errors.WithStack(err)
+}
+
+func (r *roleApiRepo) GetApiIdsByRoleId(ctx context.Context, roleId uint64) ([]uint64, error) {
+	var apiIds []uint64
+	err := getDB(ctx, r.db).
+		Model(&model.RoleApi{}).
+		Where("role_id = ?", roleId).
+		Pluck("api_id", &apiIds).Error
+	return apiIds, errors.WithStack(err)
+}
+
+func (r *roleApiRepo) GetRoleIdsByApiIds(ctx context.Context, apiIds []uint64) ([]uint64, error) {
+	if len(apiIds) == 0 {
+		return nil, nil
+	}
+	var roleIds []uint64
+	err := getDB(ctx, r.db).
+		Model(&model.RoleApi{}).
+		Where("api_id IN ?", apiIds).
+		Distinct().
+		Pluck("role_id", &roleIds).Error
+	return roleIds, errors.WithStack(err)
+}
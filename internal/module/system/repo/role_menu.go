@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"server/internal/core/mysql"
 	"server/internal/module/system/biz/repo"
 	"server/internal/module/system/model"
@@ -37,13 +38,13 @@ func (r *roleMenuRepo) AssignMenus(ctx context.Context, roleId uint64, menuIds [
 		})
 	}
 
-	err := r.db.WithContext(ctx).Create(&roleMenus).Error
+	err := getDB(ctx, r.db).Create(&roleMenus).Error
 	return errors.WithStack(err)
 }
 
 func (r *roleMenuRepo) GetMenuIdsByRoleId(ctx context.Context, roleId uint64) ([]uint64, error) {
 	var roleMenus []model.RoleMenu
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Where("role_id = ?", roleId).
 		Find(&roleMenus).Error
 	if err != nil {
@@ -59,8 +60,49 @@ func (r *roleMenuRepo) GetMenuIdsByRoleId(ctx context.Context, roleId uint64) ([
 }
 
 func (r *roleMenuRepo) DeleteByRoleId(ctx context.Context, roleId uint64) error {
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Where("role_id = ?", roleId).
 		Delete(&model.RoleMenu{}).Error
 	return errors.WithStack(err)
 }
+
+func (r *roleMenuRepo) GetRoleIdsByMenuId(ctx context.Context, menuId uint64) ([]uint64, error) {
+	var roleIds []uint64
+	err := getDB(ctx, r.db).
+		Model(&model.RoleMenu{}).
+		Where("menu_id = ?", menuId).
+		Pluck("role_id", &roleIds).Error
+	return roleIds, errors.WithStack(err)
+}
+
+func (r *roleMenuRepo) GetRoleIdsByMenuIds(ctx context.Context, menuIds []uint64) ([]uint64, error) {
+	if len(menuIds) == 0 {
+		return nil, nil
+	}
+	var roleIds []uint64
+	err := getDB(ctx, r.db).
+		Model(&model.RoleMenu{}).
+		Where("menu_id IN ?", menuIds).
+		Distinct().
+		Pluck("role_id", &roleIds).Error
+	return roleIds, errors.WithStack(err)
+}
+
+// AddMenus 为角色追加菜单权限，已存在的关联忽略
+func (r *roleMenuRepo) AddMenus(ctx context.Context, roleId uint64, menuIds []uint64) error {
+	if len(menuIds) == 0 {
+		return nil
+	}
+	roleMenus := make([]model.RoleMenu, 0, len(menuIds))
+	for _, menuId := range menuIds {
+		roleMenus = append(roleMenus, model.RoleMenu{RoleID: roleId, MenuID: menuId})
+	}
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&roleMenus).Error
+	return errors.WithStack(err)
+}
+
+func (r *roleMenuRepo) List(ctx context.Context) ([]*model.RoleMenu, error) {
+	var roleMenus []*model.RoleMenu
+	err := getDB(ctx, r.db).Find(&roleMenus).Error
+	return roleMenus, errors.WithStack(err)
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	colDeletedAt  = "deleted_at"
+	colDeleteMark = "delete_mark"
+)
+
+// softDelete 软删除，同时把 delete_mark 写为记录自身 ID，使已删除记录不再占用唯一索引
+func softDelete(ctx context.Context, db *gorm.DB, table schema.Tabler, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := getDB(ctx, db).
+		Model(table).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			colDeletedAt:  time.Now(),
+			colDeleteMark: gorm.Expr("id"),
+		}).Error
+	return errors.WithStack(err)
+}
+
+// listDeleted 分页查询已软删除的记录
+func listDeleted[T any](ctx context.Context, db *gorm.DB, table schema.Tabler, offset, limit int, conds ...func(*gorm.DB) *gorm.DB) ([]*T, int64, error) {
+	var (
+		list  []*T
+		total int64
+	)
+
+	query := getDB(ctx, db).Unscoped().Model(table).Where(colDeletedAt + " IS NOT NULL")
+	for _, cond := range conds {
+		if cond != nil {
+			query = cond(query)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	err := query.Order(colDeletedAt + " DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return list, total, nil
+}
+
+// findDeleted 按 ID 查询已软删除的记录
+func findDeleted[T any](ctx context.Context, db *gorm.DB, ids []int64) ([]*T, error) {
+	var list []*T
+	err := getDB(ctx, db).
+		Unscoped().
+		Where("id IN ? AND "+colDeletedAt+" IS NOT NULL", ids).
+		Find(&list).Error
+	return list, errors.WithStack(err)
+}
+
+// restoreDeleted 恢复已软删除的记录
+func restoreDeleted(ctx context.Context, db *gorm.DB, table schema.Tabler, ids []int64) error {
+	err := getDB(ctx, db).
+		Unscoped().
+		Model(table).
+		Where("id IN ? AND "+colDeletedAt+" IS NOT NULL", ids).
+		Updates(map[string]interface{}{
+			colDeletedAt:  nil,
+			colDeleteMark: 0,
+		}).Error
+	return errors.WithStack(err)
+}
+
+// purgeDeleted 彻底删除已软删除的记录
+func purgeDeleted(ctx context.Context, db *gorm.DB, table schema.Tabler, ids []int64) error {
+	err := getDB(ctx, db).
+		Unscoped().
+		Where("id IN ? AND "+colDeletedAt+" IS NOT NULL", ids).
+		Delete(table).Error
+	return errors.WithStack(err)
+}
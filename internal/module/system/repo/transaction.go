@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"context"
+	"server/internal/core/mysql"
+	"server/internal/module/system/biz/repo"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type transaction struct {
+	db *gorm.DB
+}
+
+func NewTransaction(systemDB *mysql.SystemDB) repo.Transaction {
+	return &transaction{db: systemDB.DB}
+}
+
+// InTx 开启事务并通过 ctx 传递给各 repo，已处于事务中时直接复用外层事务
+func (t *transaction) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// getDB 优先返回 ctx 中的事务连接
+func getDB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
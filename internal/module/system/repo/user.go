@@ -6,10 +6,12 @@ import (
 	"server/internal/module/system/biz/repo"
 	"server/internal/module/system/model"
 	"server/internal/module/system/model/request"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepo struct {
@@ -24,7 +26,7 @@ func NewUserRepo(systemDB *mysql.SystemDB) repo.UserRepo {
 
 func (r *userRepo) Find(ctx context.Context, id int64) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Preload(model.UserCol.Roles).
 		First(&user, id).Error
 
@@ -38,7 +40,7 @@ func (r *userRepo) Find(ctx context.Context, id int64) (*model.User, error) {
 }
 
 func (r *userRepo) Update(ctx context.Context, user *model.User) error {
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Model(&model.User{}).
 		Where("id = ?", user.ID).
 		Updates(user).Error
@@ -46,15 +48,14 @@ func (r *userRepo) Update(ctx context.Context, user *model.User) error {
 }
 
 func (r *userRepo) Delete(ctx context.Context, id int64) error {
-	err := r.db.WithContext(ctx).Delete(&model.User{}, id).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, r.db, &model.User{}, []int64{id})
 }
 
 func (r *userRepo) List(ctx context.Context, req *request.UserListReq) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
-	db := r.db.WithContext(ctx).Model(&model.User{}).Preload("Roles")
+	db := getDB(ctx, r.db).Model(&model.User{}).Preload("Roles")
 
 	if req.Username != nil {
 		db = db.Where("username LIKE ?", "%"+*req.Username+"%")
@@ -85,20 +86,17 @@ func (r *userRepo) List(ctx context.Context, req *request.UserListReq) ([]*model
 }
 
 func (r *userRepo) BatchDelete(ctx context.Context, ids []int64) error {
-	err := r.db.WithContext(ctx).
-		Where("id IN (?)", ids).
-		Delete(&model.User{}).Error
-	return errors.WithStack(err)
+	return softDelete(ctx, r.db, &model.User{}, ids)
 }
 
 func (r *userRepo) Create(ctx context.Context, user *model.User) error {
-	err := r.db.WithContext(ctx).Create(user).Error
+	err := getDB(ctx, r.db).Create(user).Error
 	return errors.WithStack(err)
 }
 
 func (r *userRepo) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Preload(model.UserCol.Roles).
 		Where(model.UserCol.Username+" = ?", username).
 		First(&user).Error
@@ -114,7 +112,7 @@ func (r *userRepo) FindByUsername(ctx context.Context, username string) (*model.
 
 func (r *userRepo) FindByPhone(ctx context.Context, phone string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Preload(model.UserCol.Roles).
 		Where(model.UserCol.Phone+" = ?", phone).
 		First(&user).Error
@@ -130,7 +128,7 @@ func (r *userRepo) FindByPhone(ctx context.Context, phone string) (*model.User,
 
 func (r *userRepo) FindByIds(ctx context.Context, ids []int64) ([]*model.User, error) {
 	var users []*model.User
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Preload(model.UserCol.Roles).
 		Where("id IN (?)", ids).
 		Find(&users).Error
@@ -144,9 +142,24 @@ func (r *userRepo) FindByIds(ctx context.Context, ids []int64) ([]*model.User, e
 	return users, nil
 }
 
+// likeEscaper 转义 LIKE 通配符，避免用户输入的 % 和 _ 扩大匹配范围
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *userRepo) Search(ctx context.Context, keyword string, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := getDB(ctx, r.db).
+		Where(model.UserCol.Status+" = ?", model.UserStatusEnable).
+		Where(r.db.Where(model.UserCol.Username+" = ?", keyword).
+			Or(model.UserCol.Nickname+" LIKE ?", likeEscaper.Replace(keyword)+"%")).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, errors.WithStack(err)
+}
+
 func (r *userRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Preload(model.UserCol.Roles).
 		Where(model.UserCol.Email+" = ?", email).
 		First(&user).Error
@@ -162,7 +175,7 @@ func (r *userRepo) FindByEmail(ctx context.Context, email string) (*model.User,
 
 func (r *userRepo) UpdateLastLogin(ctx context.Context, userID uint, ip string) error {
 	now := time.Now()
-	err := r.db.WithContext(ctx).
+	err := getDB(ctx, r.db).
 		Model(&model.User{}).
 		Where("id = ?", userID).
 		Updates(map[string]interface{}{
@@ -171,3 +184,51 @@ func (r *userRepo) UpdateLastLogin(ctx context.Context, userID uint, ip string)
 		}).Error
 	return errors.WithStack(err)
 }
+
+func (r *userRepo) ListDeleted(ctx context.Context, req *request.RecycleListReq) ([]*model.User, int64, error) {
+	offset, limit := req.BuilderOffsetAndLimit()
+	return listDeleted[model.User](ctx, r.db, &model.User{}, offset, limit, func(db *gorm.DB) *gorm.DB {
+		if req.Keyword == "" {
+			return db
+		}
+		return db.Where(model.UserCol.Username+" LIKE ? OR "+model.UserCol.Nickname+" LIKE ?", "%"+req.Keyword+"%", "%"+req.Keyword+"%")
+	})
+}
+
+func (r *userRepo) FindDeletedByIds(ctx context.Context, ids []int64) ([]*model.User, error) {
+	return findDeleted[model.User](ctx, r.db, ids)
+}
+
+// Restore 恢复用户，软删除时 sys_user_role 关联被保留，恢复后随用户一并生效
+func (r *userRepo) Restore(ctx context.Context, ids []int64) error {
+	return restoreDeleted(ctx, r.db, &model.User{}, ids)
+}
+
+// Purge 彻底删除用户并级联清理 sys_user_role 关联
+func (r *userRepo) Purge(ctx context.Context, ids []int64) error {
+	return getDB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id IN ?", ids).Delete(&model.UserRole{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return purgeDeleted(ctx, tx, &model.User{}, ids)
+	})
+}
+
+func (r *userRepo) AddRoles(ctx context.Context, userId uint64, roleIds []uint64) error {
+	if len(roleIds) == 0 {
+		return nil
+	}
+	userRoles := make([]model.UserRole, 0, len(roleIds))
+	for _, roleId := range roleIds {
+		userRoles = append(userRoles, model.UserRole{UserID: userId, RoleID: roleId})
+	}
+	err := getDB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&userRoles).Error
+	return errors.WithStack(err)
+}
+
+func (r *userRepo) SetRoles(ctx context.Context, userId uint64, roleIds []uint64) error {
+	if err := getDB(ctx, r.db).Where("user_id = ?", userId).Delete(&model.UserRole{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return r.AddRoles(ctx, userId, roleIds)
+}
@@ -6,6 +6,7 @@ import (
 	"server/internal/middleware"
 	im "server/internal/module/im/api"
 	system "server/internal/module/system/api"
+	"server/pkg/response"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -18,6 +19,8 @@ type Group struct {
 	logger    logger.Logger
 	cfg       *config.HTTPServer
 	cors      *middleware.CorsMiddleware
+	accessLog *middleware.AccessLogMiddleware
+	recovery  *middleware.RecoveryMiddleware
 	systemApi *system.SystemApi
 	imApi     *im.IMApi
 }
@@ -26,6 +29,8 @@ func NewGroup(
 	logger logger.Logger,
 	cfg *config.HTTPServer,
 	corsMiddleware *middleware.CorsMiddleware,
+	accessLogMiddleware *middleware.AccessLogMiddleware,
+	recoveryMiddleware *middleware.RecoveryMiddleware,
 	systemApi *system.SystemApi,
 	imApi *im.IMApi,
 ) *Group {
@@ -33,15 +38,22 @@ func NewGroup(
 		logger:    logger,
 		cfg:       cfg,
 		cors:      corsMiddleware,
+		accessLog: accessLogMiddleware,
+		recovery:  recoveryMiddleware,
 		systemApi: systemApi,
 		imApi:     imApi,
 	}
 }
 
 func (a *Group) InitRouter(engine *gin.Engine) error {
-	if a.cfg.Cors.Enabled {
-		engine.Use(a.cors.Handler())
-	}
+	response.SetExposeErrors(a.cfg.ExposeErrors)
+
+	// 访问日志最先执行，生成请求 ID 供后续中间件和业务日志使用
+	engine.Use(a.accessLog.Handler())
+	// panic 恢复放在访问日志之后，日志中能带上请求 ID 并记录 500 状态
+	engine.Use(a.recovery.Handler())
+	// 是否启用跨域由中间件根据当前配置判断，支持热更新
+	engine.Use(a.cors.Handler())
 
 	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -50,5 +62,10 @@ func (a *Group) InitRouter(engine *gin.Engine) error {
 		a.systemApi.InitSystemApi(systemRouter)
 	}
 
+	{
+		imRouter := engine.Group("api/im")
+		a.imApi.InitIMApi(imRouter)
+	}
+
 	return nil
 }
@@ -0,0 +1,127 @@
+// Package ahocorasick 多模式串匹配，构建后只读，可在多个 goroutine 中并发使用
+package ahocorasick
+
+// Match 一次匹配，Start、End 为文本中的 rune 下标（End 不包含），Pattern 为模式串在构建时的下标
+type Match struct {
+	Start   int
+	End     int
+	Pattern int
+}
+
+type node struct {
+	next map[rune]int32
+	fail int32
+	// outputs 以该节点结尾的全部模式串，包含沿失败指针可达的模式串
+	outputs []int
+}
+
+// Matcher Aho-Corasick 自动机，按 rune 匹配以支持中文
+type Matcher struct {
+	nodes    []node
+	patterns [][]rune
+}
+
+// Build 构建自动机，空模式串会被忽略
+func Build(patterns []string) *Matcher {
+	m := &Matcher{nodes: []node{{}}, patterns: make([][]rune, len(patterns))}
+	for i, pattern := range patterns {
+		runes := []rune(pattern)
+		m.patterns[i] = runes
+		if len(runes) == 0 {
+			continue
+		}
+		cur := int32(0)
+		for _, r := range runes {
+			next, ok := m.nodes[cur].next[r]
+			if !ok {
+				next = int32(len(m.nodes))
+				m.nodes = append(m.nodes, node{})
+				if m.nodes[cur].next == nil {
+					m.nodes[cur].next = make(map[rune]int32)
+				}
+				m.nodes[cur].next[r] = next
+			}
+			cur = next
+		}
+		m.nodes[cur].outputs = append(m.nodes[cur].outputs, i)
+	}
+	m.link()
+	return m
+}
+
+// link 按层序计算失败指针，并合并失败指针指向节点的输出
+func (m *Matcher) link() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for {
+				if next, ok := m.nodes[fail].next[r]; ok {
+					m.nodes[child].fail = next
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if out := m.nodes[m.nodes[child].fail].outputs; len(out) > 0 {
+				m.nodes[child].outputs = append(m.nodes[child].outputs, out...)
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// FindAll 返回文本中的全部匹配，包括相互重叠的匹配，按结束位置升序排列
+func (m *Matcher) FindAll(text []rune) []Match {
+	var matches []Match
+	cur := int32(0)
+	for i, r := range text {
+		for {
+			if next, ok := m.nodes[cur].next[r]; ok {
+				cur = next
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		for _, p := range m.nodes[cur].outputs {
+			matches = append(matches, Match{Start: i + 1 - len(m.patterns[p]), End: i + 1, Pattern: p})
+		}
+	}
+	return matches
+}
+
+// Contains 文本中是否包含任意模式串
+func (m *Matcher) Contains(text []rune) bool {
+	cur := int32(0)
+	for _, r := range text {
+		for {
+			if next, ok := m.nodes[cur].next[r]; ok {
+				cur = next
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if len(m.nodes[cur].outputs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Len 模式串数量
+func (m *Matcher) Len() int {
+	return len(m.patterns)
+}
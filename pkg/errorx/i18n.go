@@ -0,0 +1,166 @@
+package errorx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LangZh = "zh"
+	LangEn = "en"
+
+	// DefaultLang 错误定义中的 Message 即为该语言的提示信息
+	DefaultLang = LangZh
+)
+
+// catalogs 按 Reason 索引的其他语言提示信息，缺失时回退到默认语言
+var catalogs = map[string]map[string]string{
+	LangEn: {
+		"SUCCESS":                 "success",
+		"INTERNAL":                "internal server error",
+		"INVALID_PARAM":           "invalid parameter",
+		"UNAUTHORIZED":            "unauthorized",
+		"INTERNAL_SERVER":         "internal server error",
+		"NOT_FOUND":               "resource not found",
+		"PERMISSION_DENY":         "access denied",
+		"AUTH_HEADER_MISSING":     "missing Authorization header",
+		"AUTH_HEADER_FORMAT":      "malformed Authorization header",
+		"FORBIDDEN":               "forbidden",
+		"INVALID_TOKEN":           "invalid token",
+		"TOKEN_EXPIRED":           "token expired",
+		"TOKEN_INVALID":           "invalid token",
+		"TOKEN_MALFORMED":         "malformed token",
+		"TOKEN_SIGNATURE_INVALID": "invalid token signature",
+		"TOKEN_PARSE_FAILED":      "failed to parse token",
+		"PERMISSION_DENIED":       "permission check failed",
+		"REQUEST_TOO_LARGE":       "request body too large",
+		"TOKEN_NOT_VALID_YET":     "token not valid yet",
+
+		"USER_NOT_FOUND":           "user not found",
+		"USER_CONFLICT":            "user already exists",
+		"USER_LOGIN_FAIL":          "incorrect username or password",
+		"USER_PASSWORD_NOT_MATCH":  "incorrect password",
+		"AUTH_GENERATE_TOKEN_FAIL": "failed to generate token",
+		"USER_IS_SYSTEM":           "user is a built-in system user",
+		"USER_NOT_ROLE":            "user has no available role",
+		"USER_DISABLED":            "user is disabled",
+
+		"ROLE_NOT_FOUND":                     "role not found",
+		"ADD_POLICIES_FAIL":                  "failed to assign permissions",
+		"ROLE_ALREADY_EXISTS":                "role already exists",
+		"ROLE_IS_SYSTEM":                     "role is a built-in system role",
+		"ROLE_IS_DISABLED":                   "role is disabled",
+		"ADMIN_ROLE_NOT_FOUND":               "admin role not found",
+		"ROLE_IN_USE":                        "role is still assigned to users",
+		"PERMISSION_TEMPLATE_NOT_FOUND":      "permission template not found",
+		"PERMISSION_TEMPLATE_ALREADY_EXISTS": "permission template already exists",
+
+		"API_NOT_FOUND":      "api not found",
+		"API_ALREADY_EXISTS": "api already exists",
+
+		"POLICY_IS_EXIST": "policy already exists",
+
+		"MENU_NOT_FOUND":      "menu not found",
+		"MENU_ALREADY_EXISTS": "menu already exists",
+		"MENU_NOT_BUTTON":     "menu is not a button",
+		"MENU_TYPE_INVALID":   "invalid menu type",
+		"MENU_HAS_CHILDREN":   "menu has children",
+		"MENU_PARENT_INVALID": "parent menu does not exist or is the menu itself or one of its children",
+		"MENU_IMPORT_INVALID": "invalid menu import file",
+
+		"IM_PROTOCOL_VERSION": "unsupported protocol version",
+		"IM_FRAME_INVALID":    "malformed frame",
+		"IM_EVENT_NOT_FOUND":  "unsupported event",
+
+		"IM_PEER_INVALID":            "recipient does not exist or is disabled",
+		"IM_CONVERSATION_NOT_FOUND":  "conversation not found",
+		"IM_NOT_CONVERSATION_MEMBER": "not a member of the conversation",
+		"IM_MESSAGE_NOT_FOUND":       "message not found",
+		"IM_MESSAGE_RECALLED":        "the message has been recalled",
+		"IM_MESSAGE_NOT_SENDER":      "you can only modify messages you sent",
+		"IM_RECALL_EXPIRED":          "the message can no longer be recalled",
+		"IM_EDIT_EXPIRED":            "the message can no longer be edited",
+		"IM_MESSAGE_NOT_EDITABLE":    "only text messages can be edited",
+		"IM_REACTION_LIMIT":          "this message has reached the reaction limit",
+
+		"IM_GROUP_NOT_FOUND":         "group not found",
+		"IM_NOT_GROUP_MEMBER":        "not a member of the group",
+		"IM_GROUP_PERMISSION":        "not allowed to perform this group operation",
+		"IM_GROUP_FULL":              "the group is full",
+		"IM_GROUP_MUTED":             "you have been muted",
+		"IM_GROUP_OWNER_LEAVE":       "the owner must transfer the group before leaving",
+		"IM_GROUP_INVITE_ONLY":       "the group can only be joined by invitation",
+		"IM_GROUP_MAX_MEMBERS":       "member limit is out of the allowed range",
+		"IM_GROUP_OWNED_LIMIT":       "you have reached the limit of groups you can create",
+		"IM_GROUP_TARGET_NOT_MEMBER": "the target user is not a member of the group",
+		"IM_GROUP_USER_INVALID":      "user does not exist or is disabled",
+
+		"IM_PRESENCE_SUBSCRIBE_LIMIT": "too many presence subscriptions",
+
+		"IM_FRIEND_SELF":              "you cannot add yourself as a friend",
+		"IM_ALREADY_FRIEND":           "already friends",
+		"IM_FRIEND_REQUEST_NOT_FOUND": "friend request not found or already handled",
+		"IM_NOT_FRIEND":               "the user is not your friend",
+		"IM_BLOCKED":                  "the recipient is not accepting your messages",
+		"IM_BLOCK_SELF":               "you cannot block yourself",
+
+		"IM_ATTACHMENT_TOO_LARGE": "the attachment exceeds the size limit",
+		"IM_ATTACHMENT_TYPE":      "unsupported attachment type",
+		"IM_ATTACHMENT_INVALID":   "invalid attachment",
+		"IM_ATTACHMENT_NOT_FOUND": "attachment not found",
+		"IM_ATTACHMENT_URL":       "the download link is invalid or has expired",
+
+		"IM_MESSAGE_BLOCKED":          "the message contains prohibited content",
+		"IM_SENSITIVE_WORD_INVALID":   "a sensitive word must be 1 to 64 characters",
+		"IM_SENSITIVE_WORD_NOT_FOUND": "sensitive word not found",
+		"IM_GROUP_WORD_LIMIT":         "too many custom sensitive words for the group",
+		"IM_REVIEW_NOT_FOUND":         "review record not found",
+		"IM_REVIEW_DONE":              "the record has already been reviewed",
+	},
+}
+
+// Localize 返回指定语言的提示信息
+func (e *BizError) Localize(lang string) string {
+	if lang != DefaultLang {
+		if msg, ok := catalogs[lang][e.Reason]; ok {
+			return msg
+		}
+	}
+	return e.Message
+}
+
+// MatchLanguage 根据 Accept-Language 请求头选择支持的语言，比如 "en-US,en;q=0.9,zh;q=0.8" -> en
+func MatchLanguage(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		candidates = append(candidates, candidate{lang: base, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if c.q <= 0 {
+			continue
+		}
+		if _, ok := catalogs[c.lang]; ok || c.lang == DefaultLang {
+			return c.lang
+		}
+	}
+	return DefaultLang
+}
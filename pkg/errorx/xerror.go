@@ -1,67 +1,197 @@
 package errorx
 
+import (
+	"errors"
+	"net/http"
+)
+
+// BizError 业务错误，Code 为稳定的业务码，Reason 为稳定的字符串原因（用于国际化和客户端判断），
+// Status 为响应的 HTTP 状态码，Message 为默认（中文）提示信息
 type BizError struct {
 	Code    int    `json:"code"`
+	Status  int    `json:"-"`
+	Reason  string `json:"reason"`
 	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+
+	cause error
 }
 
 func (e *BizError) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
 	return e.Message
 }
 
-func New(code int, msg string) *BizError {
+// Unwrap 返回被包装的原始错误
+func (e *BizError) Unwrap() error {
+	return e.cause
+}
+
+// Is 业务码相同即视为同一错误，包装或附加详情后仍可用 errors.Is 判断
+func (e *BizError) Is(target error) bool {
+	var t *BizError
+	if !errors.As(target, &t) {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// Wrap 返回包装了原始错误的副本，原始错误只写入日志和调试响应，不返回给客户端
+func (e *BizError) Wrap(cause error) *BizError {
+	c := *e
+	c.cause = cause
+	return &c
+}
+
+// WithDetails 返回附加了详情的副本，详情会原样返回给客户端，比如字段校验错误
+func (e *BizError) WithDetails(details any) *BizError {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// Cause 返回被包装的原始错误
+func (e *BizError) Cause() error {
+	return e.cause
+}
+
+func New(status, code int, reason, msg string) *BizError {
 	return &BizError{
 		Code:    code,
+		Status:  status,
+		Reason:  reason,
 		Message: msg,
 	}
 }
 
+// FromError 将任意错误转换为 BizError，非业务错误包装为 ErrInternal
+func FromError(err error) *BizError {
+	if err == nil {
+		return nil
+	}
+	var bizErr *BizError
+	if errors.As(err, &bizErr) {
+		return bizErr
+	}
+	return ErrInternal.Wrap(err)
+}
+
 // ========== 通用模块：10 开头 ==========
 var (
-	ErrSuccess               = New(0, "success")
-	ErrForbidden             = New(403, "权限不足")
-	ErrInternal              = New(500, "服务器错误")
-	ErrInvalidParam          = New(100001, "参数错误")
-	ErrUnauthorized          = New(100002, "未授权")
-	ErrInternalServer        = New(100003, "服务器内部错误")
-	ErrNotFound              = New(100004, "资源不存在")
-	ErrPermissionDeny        = New(100005, "无权限访问")
-	ErrAuthHeaderMissing     = New(100006, "未提供 Authorization header")
-	ErrAuthHeaderFormat      = New(100007, "Authorization 格式错误")
-	ErrInvalidToken          = New(401, "token 无效")
-	ErrTokenExpired          = New(401, "token 已过期")
-	ErrTokenInvalid          = New(401, "token 无效")
-	ErrTokenMalformed        = New(401, "token 格式错误")
-	ErrTokenNotValidYet      = New(401, "token 尚未生效")
-	ErrTokenSignatureInvalid = New(100013, "token 签名无效")
-	ErrTokenParseFailed      = New(100014, "token 解析失败")
-	ErrPermissionDenied      = New(100015, "权限校验失败")
+	ErrSuccess               = New(http.StatusOK, 0, "SUCCESS", "success")
+	ErrInternal              = New(http.StatusInternalServerError, 100000, "INTERNAL", "服务器错误")
+	ErrInvalidParam          = New(http.StatusBadRequest, 100001, "INVALID_PARAM", "参数错误")
+	ErrUnauthorized          = New(http.StatusUnauthorized, 100002, "UNAUTHORIZED", "未授权")
+	ErrInternalServer        = New(http.StatusInternalServerError, 100003, "INTERNAL_SERVER", "服务器内部错误")
+	ErrNotFound              = New(http.StatusNotFound, 100004, "NOT_FOUND", "资源不存在")
+	ErrPermissionDeny        = New(http.StatusForbidden, 100005, "PERMISSION_DENY", "无权限访问")
+	ErrAuthHeaderMissing     = New(http.StatusUnauthorized, 100006, "AUTH_HEADER_MISSING", "未提供 Authorization header")
+	ErrAuthHeaderFormat      = New(http.StatusUnauthorized, 100007, "AUTH_HEADER_FORMAT", "Authorization 格式错误")
+	ErrForbidden             = New(http.StatusForbidden, 100008, "FORBIDDEN", "权限不足")
+	ErrInvalidToken          = New(http.StatusUnauthorized, 100009, "INVALID_TOKEN", "token 无效")
+	ErrTokenExpired          = New(http.StatusUnauthorized, 100010, "TOKEN_EXPIRED", "token 已过期")
+	ErrTokenInvalid          = New(http.StatusUnauthorized, 100011, "TOKEN_INVALID", "token 无效")
+	ErrTokenMalformed        = New(http.StatusUnauthorized, 100012, "TOKEN_MALFORMED", "token 格式错误")
+	ErrTokenSignatureInvalid = New(http.StatusUnauthorized, 100013, "TOKEN_SIGNATURE_INVALID", "token 签名无效")
+	ErrTokenParseFailed      = New(http.StatusUnauthorized, 100014, "TOKEN_PARSE_FAILED", "token 解析失败")
+	ErrPermissionDenied      = New(http.StatusForbidden, 100015, "PERMISSION_DENIED", "权限校验失败")
+	ErrRequestTooLarge       = New(http.StatusRequestEntityTooLarge, 100016, "REQUEST_TOO_LARGE", "请求体过大")
+	ErrTokenNotValidYet      = New(http.StatusUnauthorized, 100017, "TOKEN_NOT_VALID_YET", "token 尚未生效")
+)
+
+var (
+	ErrUserNotFound          = New(http.StatusNotFound, 200001, "USER_NOT_FOUND", "用户不存在")
+	ErrUserConflict          = New(http.StatusConflict, 200002, "USER_CONFLICT", "用户已存在")
+	ErrUserLoginFail         = New(http.StatusUnauthorized, 200003, "USER_LOGIN_FAIL", "用户名或密码错误")
+	ErrUserPasswordNotMatch  = New(http.StatusBadRequest, 200004, "USER_PASSWORD_NOT_MATCH", "密码错误")
+	ErrAuthGenerateTokenFail = New(http.StatusInternalServerError, 200005, "AUTH_GENERATE_TOKEN_FAIL", "生成 token 失败")
+	ErrUserIsSystem          = New(http.StatusForbidden, 200006, "USER_IS_SYSTEM", "用户为系统内置用户")
+	ErrUserNotRole           = New(http.StatusForbidden, 200007, "USER_NOT_ROLE", "用户无可用角色")
+	ErrUserDisabled          = New(http.StatusForbidden, 200008, "USER_DISABLED", "用户已禁用")
+)
+
+var (
+	ErrRoleNotFound      = New(http.StatusNotFound, 300001, "ROLE_NOT_FOUND", "角色不存在")
+	ErrAddPoliciesFail   = New(http.StatusInternalServerError, 300002, "ADD_POLICIES_FAIL", "分配权限失败")
+	ErrRoleAlreadyExists = New(http.StatusConflict, 300003, "ROLE_ALREADY_EXISTS", "角色已存在")
+	ErrRoleIsSystem      = New(http.StatusForbidden, 300004, "ROLE_IS_SYSTEM", "角色为系统内置角色")
+	ErrRoleIsDisabled    = New(http.StatusForbidden, 300005, "ROLE_IS_DISABLED", "角色已禁用")
+	ErrAdminRoleNotFound = New(http.StatusInternalServerError, 300006, "ADMIN_ROLE_NOT_FOUND", "管理员角色不存在")
+	ErrRoleInUse         = New(http.StatusConflict, 300007, "ROLE_IN_USE", "角色仍被用户使用")
+
+	ErrPermissionTemplateNotFound      = New(http.StatusNotFound, 300008, "PERMISSION_TEMPLATE_NOT_FOUND", "权限模板不存在")
+	ErrPermissionTemplateAlreadyExists = New(http.StatusConflict, 300009, "PERMISSION_TEMPLATE_ALREADY_EXISTS", "权限模板已存在")
 )
 
 var (
-	ErrUserNotFound          = New(200001, "用户不存在")
-	ErrUserConflict          = New(200002, "用户已存在")
-	ErrUserLoginFail         = New(200003, "用户名或密码错误")
-	ErrUserPasswordNotMatch  = New(200004, "密码错误")
-	ErrAuthGenerateTokenFail = New(200005, "生成 token 失败")
-	ErrUserIsSystem          = New(200006, "用户为系统内置用户")
-	ErrUserNotRole           = New(200007, "用户无可用角色")
-	ErrUserDisabled          = New(200008, "用户已禁用")
+	ErrApiNotFound      = New(http.StatusNotFound, 400001, "API_NOT_FOUND", "接口不存在")
+	ErrApiAlreadyExists = New(http.StatusConflict, 400002, "API_ALREADY_EXISTS", "接口已存在")
 )
 
 var (
-	ErrRoleNotFound      = New(300001, "角色不存在")
-	ErrAddPoliciesFail   = New(300002, "分配权限失败")
-	ErrRoleAlreadyExists = New(300003, "角色已存在")
-	ErrRoleIsSystem      = New(300004, "角色为系统内置角色")
-	ErrRoleIsDisabled    = New(300005, "角色已禁用")
-	ErrAdminRoleNotFound = New(300006, "管理员角色不存在")
+	ErrPolicyIsExist = New(http.StatusConflict, 500001, "POLICY_IS_EXIST", "策略已经存在")
 )
 
 var (
-	ErrApiNotFound = New(400001, "接口不存在")
+	ErrMenuNotFound      = New(http.StatusNotFound, 600001, "MENU_NOT_FOUND", "菜单不存在")
+	ErrMenuAlreadyExists = New(http.StatusConflict, 600002, "MENU_ALREADY_EXISTS", "菜单已存在")
+	ErrMenuNotButton     = New(http.StatusBadRequest, 600003, "MENU_NOT_BUTTON", "菜单不是按钮")
+	ErrMenuTypeInvalid   = New(http.StatusBadRequest, 600004, "MENU_TYPE_INVALID", "菜单类型错误")
+	ErrMenuHasChildren   = New(http.StatusConflict, 600005, "MENU_HAS_CHILDREN", "菜单存在子菜单")
+	ErrMenuParentInvalid = New(http.StatusBadRequest, 600006, "MENU_PARENT_INVALID", "上级菜单不存在或为自身及其子菜单")
+	ErrMenuImportInvalid = New(http.StatusBadRequest, 600007, "MENU_IMPORT_INVALID", "菜单导入文件格式错误")
 )
 
+// ========== IM 模块：70 开头 ==========
 var (
-	ErrPolicyIsExist = New(500001, "策略已经存在")
+	ErrImProtocolVersion = New(http.StatusBadRequest, 700001, "IM_PROTOCOL_VERSION", "不支持的协议版本")
+	ErrImFrameInvalid    = New(http.StatusBadRequest, 700002, "IM_FRAME_INVALID", "消息帧格式错误")
+	ErrImEventNotFound   = New(http.StatusBadRequest, 700003, "IM_EVENT_NOT_FOUND", "不支持的事件")
+
+	ErrImPeerInvalid           = New(http.StatusBadRequest, 700101, "IM_PEER_INVALID", "接收方不存在或已禁用")
+	ErrImConversationNotFound  = New(http.StatusNotFound, 700102, "IM_CONVERSATION_NOT_FOUND", "会话不存在")
+	ErrImNotConversationMember = New(http.StatusForbidden, 700103, "IM_NOT_CONVERSATION_MEMBER", "不是会话成员")
+	ErrImMessageNotFound       = New(http.StatusNotFound, 700104, "IM_MESSAGE_NOT_FOUND", "消息不存在")
+	ErrImMessageRecalled       = New(http.StatusConflict, 700105, "IM_MESSAGE_RECALLED", "消息已撤回")
+	ErrImMessageNotSender      = New(http.StatusForbidden, 700106, "IM_MESSAGE_NOT_SENDER", "只能操作自己发送的消息")
+	ErrImRecallExpired         = New(http.StatusBadRequest, 700107, "IM_RECALL_EXPIRED", "消息已超过可撤回时间")
+	ErrImEditExpired           = New(http.StatusBadRequest, 700108, "IM_EDIT_EXPIRED", "消息已超过可编辑时间")
+	ErrImMessageNotEditable    = New(http.StatusBadRequest, 700109, "IM_MESSAGE_NOT_EDITABLE", "只能编辑文本消息")
+	ErrImReactionLimit         = New(http.StatusConflict, 700110, "IM_REACTION_LIMIT", "该消息的表情回应种类已达上限")
+
+	ErrImGroupNotFound        = New(http.StatusNotFound, 700201, "IM_GROUP_NOT_FOUND", "群组不存在")
+	ErrImNotGroupMember       = New(http.StatusForbidden, 700202, "IM_NOT_GROUP_MEMBER", "不是群成员")
+	ErrImGroupPermission      = New(http.StatusForbidden, 700203, "IM_GROUP_PERMISSION", "无权执行该群组操作")
+	ErrImGroupFull            = New(http.StatusConflict, 700204, "IM_GROUP_FULL", "群成员已达上限")
+	ErrImGroupMuted           = New(http.StatusForbidden, 700205, "IM_GROUP_MUTED", "已被禁言")
+	ErrImGroupOwnerLeave      = New(http.StatusBadRequest, 700206, "IM_GROUP_OWNER_LEAVE", "群主需先转让群组才能退出")
+	ErrImGroupInviteOnly      = New(http.StatusForbidden, 700207, "IM_GROUP_INVITE_ONLY", "该群组仅允许邀请加入")
+	ErrImGroupMaxMembers      = New(http.StatusBadRequest, 700208, "IM_GROUP_MAX_MEMBERS", "群成员上限超出允许范围")
+	ErrImGroupOwnedLimit      = New(http.StatusConflict, 700209, "IM_GROUP_OWNED_LIMIT", "创建的群组数已达上限")
+	ErrImGroupTargetNotMember = New(http.StatusBadRequest, 700210, "IM_GROUP_TARGET_NOT_MEMBER", "目标用户不是群成员")
+	ErrImGroupUserInvalid     = New(http.StatusBadRequest, 700211, "IM_GROUP_USER_INVALID", "用户不存在或已禁用")
+
+	ErrImPresenceSubscribeLimit = New(http.StatusBadRequest, 700301, "IM_PRESENCE_SUBSCRIBE_LIMIT", "订阅在线状态的用户数已达上限")
+
+	ErrImFriendSelf            = New(http.StatusBadRequest, 700401, "IM_FRIEND_SELF", "不能添加自己为好友")
+	ErrImAlreadyFriend         = New(http.StatusConflict, 700402, "IM_ALREADY_FRIEND", "已经是好友")
+	ErrImFriendRequestNotFound = New(http.StatusNotFound, 700403, "IM_FRIEND_REQUEST_NOT_FOUND", "好友申请不存在或已处理")
+	ErrImNotFriend             = New(http.StatusNotFound, 700404, "IM_NOT_FRIEND", "对方不是你的好友")
+	ErrImBlocked               = New(http.StatusForbidden, 700405, "IM_BLOCKED", "对方已拒收你的消息")
+	ErrImBlockSelf             = New(http.StatusBadRequest, 700406, "IM_BLOCK_SELF", "不能拉黑自己")
+
+	ErrImAttachmentTooLarge = New(http.StatusRequestEntityTooLarge, 700501, "IM_ATTACHMENT_TOO_LARGE", "附件大小超出限制")
+	ErrImAttachmentType     = New(http.StatusUnsupportedMediaType, 700502, "IM_ATTACHMENT_TYPE", "不支持的附件类型")
+	ErrImAttachmentInvalid  = New(http.StatusBadRequest, 700503, "IM_ATTACHMENT_INVALID", "附件无效")
+	ErrImAttachmentNotFound = New(http.StatusNotFound, 700504, "IM_ATTACHMENT_NOT_FOUND", "附件不存在")
+	ErrImAttachmentURL      = New(http.StatusForbidden, 700505, "IM_ATTACHMENT_URL", "下载地址无效或已过期")
+
+	ErrImMessageBlocked        = New(http.StatusBadRequest, 700601, "IM_MESSAGE_BLOCKED", "消息包含违禁内容，无法发送")
+	ErrImSensitiveWordInvalid  = New(http.StatusBadRequest, 700602, "IM_SENSITIVE_WORD_INVALID", "敏感词不能为空且不能超过64个字符")
+	ErrImSensitiveWordNotFound = New(http.StatusNotFound, 700603, "IM_SENSITIVE_WORD_NOT_FOUND", "敏感词不存在")
+	ErrImGroupWordLimit        = New(http.StatusBadRequest, 700604, "IM_GROUP_WORD_LIMIT", "群自定义敏感词数量超出限制")
+	ErrImReviewNotFound        = New(http.StatusNotFound, 700605, "IM_REVIEW_NOT_FOUND", "审核记录不存在")
+	ErrImReviewDone            = New(http.StatusConflict, 700606, "IM_REVIEW_DONE", "该记录已审核")
 )
@@ -0,0 +1,83 @@
+package imagex
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// MaxPixels 解码图片的像素数上限，避免超大尺寸图片解码时耗尽内存
+const MaxPixels = 40_000_000
+
+// ErrTooManyPixels 图片像素数超过 MaxPixels
+var ErrTooManyPixels = errors.New("imagex: image has too many pixels")
+
+// DecodeConfig 读取图片格式和尺寸，支持 jpeg、png、gif
+func DecodeConfig(data []byte) (image.Config, string, error) {
+	return image.DecodeConfig(bytes.NewReader(data))
+}
+
+// Thumbnail 按最长边 maxSide 等比缩小图片并编码为 JPEG，图片不超过 maxSide 时返回 nil
+func Thumbnail(data []byte, maxSide int) ([]byte, error) {
+	cfg, _, err := DecodeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= maxSide && cfg.Height <= maxSide {
+		return nil, nil
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resize(src, maxSide), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize 区域平均缩小，每个目标像素取其覆盖的源像素平均值；透明区域按白色背景合成
+func resize(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := maxSide, maxSide
+	if w >= h {
+		dh = max(1, h*maxSide/w)
+	} else {
+		dw = max(1, w*maxSide/h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := b.Min.Y+y*h/dh, b.Min.Y+max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := b.Min.X+x*w/dw, b.Min.X+max((x+1)*w/dw, x*w/dw+1)
+			var r, g, bl, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					// 预乘 alpha 的颜色叠加到白色背景
+					bg := 0xffff - uint64(ca)
+					r += uint64(cr) + bg
+					g += uint64(cg) + bg
+					bl += uint64(cb) + bg
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
@@ -4,17 +4,28 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"server/pkg/errorx"
+	"sync/atomic"
 )
 
 type Response[T any] struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
-	Data T      `json:"data,omitempty"`
+	Code    int    `json:"code"`
+	Msg     string `json:"msg"`
+	Reason  string `json:"reason,omitempty"`
+	Data    T      `json:"data,omitempty"`
+	Details any    `json:"details,omitempty"`
 }
 
 const SuccessCode = 200
 const SuccessMessage = "success"
 
+// exposeErrors 为 true 时在提示信息后附加原始错误，仅用于开发调试，生产环境不能开启
+var exposeErrors atomic.Bool
+
+// SetExposeErrors 设置是否在响应中返回原始错误
+func SetExposeErrors(expose bool) {
+	exposeErrors.Store(expose)
+}
+
 func Success(c *gin.Context) {
 	c.JSON(http.StatusOK, Response[any]{
 		Code: SuccessCode,
@@ -30,17 +41,21 @@ func SuccessWithData[T any](c *gin.Context, data T) {
 	})
 }
 
+// Fail 按业务错误的 HTTP 状态码返回错误，提示信息根据 Accept-Language 选择语言，
+// 非业务错误按 ErrInternal 返回，原始错误只在开启 exposeErrors 时返回
 func Fail(c *gin.Context, err error) {
-	if bizErr, ok := err.(*errorx.BizError); ok {
-		c.JSON(http.StatusOK, Response[any]{
-			Code: bizErr.Code,
-			Msg:  bizErr.Message,
-		})
-	} else {
-		c.JSON(http.StatusOK, Response[any]{
-			Code: errorx.ErrInternalServer.Code,
-			Msg:  "系统异常：" + err.Error(),
-		})
+	bizErr := errorx.FromError(err)
+	msg := bizErr.Localize(errorx.MatchLanguage(c.GetHeader("Accept-Language")))
+	if cause := bizErr.Cause(); cause != nil && exposeErrors.Load() {
+		msg += ": " + cause.Error()
 	}
-	c.Abort()
+
+	// 记录到 gin.Context 中，由访问日志输出原始错误
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(bizErr.Status, Response[any]{
+		Code:    bizErr.Code,
+		Msg:     msg,
+		Reason:  bizErr.Reason,
+		Details: bizErr.Details,
+	})
 }
@@ -0,0 +1,110 @@
+package validatex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"server/pkg/errorx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func init() {
+	// gin 绑定参数后使用本包的校验器，统一使用 validate 标签
+	binding.Validator = &ginValidator{}
+}
+
+// typeMessages 参数类型错误的提示信息
+var typeMessages = map[string]string{
+	errorx.LangZh: "%s类型错误，应为%s",
+	errorx.LangEn: "%s must be of type %s",
+}
+
+// BindJSON 绑定并校验 JSON 请求体
+func BindJSON(c *gin.Context, obj any) error {
+	return bind(c, obj, binding.JSON)
+}
+
+// BindQuery 绑定并校验查询参数
+func BindQuery(c *gin.Context, obj any) error {
+	return bind(c, obj, binding.Query)
+}
+
+// BindForm 绑定并校验 multipart 表单，文件字段使用 *multipart.FileHeader
+func BindForm(c *gin.Context, obj any) error {
+	return bind(c, obj, binding.FormMultipart)
+}
+
+// BindUri 绑定并校验路径参数
+func BindUri(c *gin.Context, obj any) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		return bindError(c, err)
+	}
+	return nil
+}
+
+// bind 绑定失败时返回 errorx.ErrInvalidParam，详情中包含全部字段错误，提示信息按 Accept-Language 翻译
+func bind(c *gin.Context, obj any, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		return bindError(c, err)
+	}
+	return nil
+}
+
+// DecodeJSON 解析并校验 JSON 数据，用于 WebSocket 等非 HTTP 请求，错误提示按 lang 翻译
+func DecodeJSON(data []byte, obj any, lang string) error {
+	if err := json.Unmarshal(data, obj); err != nil {
+		return decodeError(err, lang)
+	}
+	return translateError(validate.Struct(obj), lang)
+}
+
+func bindError(c *gin.Context, err error) error {
+	return decodeError(err, errorx.MatchLanguage(c.GetHeader("Accept-Language")))
+}
+
+func decodeError(err error, lang string) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errorx.ErrRequestTooLarge.Wrap(err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return errorx.ErrInvalidParam.WithDetails([]*FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf(typeMessages[lang], typeErr.Field, typeErr.Type.String()),
+		}}).Wrap(err)
+	}
+	return translateError(err, lang)
+}
+
+type ginValidator struct{}
+
+func (v *ginValidator) ValidateStruct(obj any) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return validate.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *ginValidator) Engine() any {
+	return validate
+}
@@ -1,38 +1,164 @@
 package validatex
 
 import (
-	"fmt"
-	"github.com/go-playground/validator/v10"
+	"errors"
 	"reflect"
+	"regexp"
+	"server/pkg/errorx"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
 )
 
-// Validator 全局单例
-var validate *validator.Validate
+// FieldError 单个字段的校验错误，Field 为 JSON 字段路径，比如 items[0].id
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// rule 自定义校验规则及各语言的提示信息，{0} 为字段名
+type rule struct {
+	tag      string
+	fn       validator.Func
+	messages map[string]string
+}
+
+var (
+	// Validator 全局单例
+	validate *validator.Validate
+	uni      *ut.UniversalTranslator
+
+	phoneRegexp = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+	// rules 自定义校验规则统一在此注册
+	rules = []rule{
+		{
+			tag: "notzero",
+			fn:  notZero,
+			messages: map[string]string{
+				errorx.LangZh: "{0}不能为0",
+				errorx.LangEn: "{0} must not be zero",
+			},
+		},
+		{
+			tag: "phone",
+			fn: func(fl validator.FieldLevel) bool {
+				return phoneRegexp.MatchString(fl.Field().String())
+			},
+			messages: map[string]string{
+				errorx.LangZh: "{0}必须是有效的手机号码",
+				errorx.LangEn: "{0} must be a valid phone number",
+			},
+		},
+	}
+)
 
 func init() {
 	validate = validator.New()
+	// 错误中的字段名使用 JSON 字段名，与请求参数保持一致
+	validate.RegisterTagNameFunc(fieldName)
 
-	err := validate.RegisterValidation("notzero", func(fl validator.FieldLevel) bool {
-		field := fl.Field()
+	zhLocale := zh.New()
+	uni = ut.New(zhLocale, zhLocale, en.New())
+	for lang, register := range map[string]func(*validator.Validate, ut.Translator) error{
+		errorx.LangZh: zhTranslations.RegisterDefaultTranslations,
+		errorx.LangEn: enTranslations.RegisterDefaultTranslations,
+	} {
+		trans, _ := uni.GetTranslator(lang)
+		if err := register(validate, trans); err != nil {
+			panic(err)
+		}
+	}
 
-		if field.Kind() == reflect.Ptr && !field.IsNil() {
-			if v, ok := field.Interface().(*int64); ok {
-				return *v != 0
+	for _, r := range rules {
+		if err := validate.RegisterValidation(r.tag, r.fn); err != nil {
+			panic(err)
+		}
+		for lang, message := range r.messages {
+			trans, _ := uni.GetTranslator(lang)
+			if err := validate.RegisterTranslation(r.tag, trans, registerTranslation(r.tag, message), translate); err != nil {
+				panic(err)
 			}
 		}
-		return false
-	})
-	if err != nil {
-		panic(err)
-		return
 	}
 }
 
+// ValidateStruct 校验结构体，返回包含全部字段错误的 errorx.ErrInvalidParam
 func ValidateStruct(s interface{}) error {
-	if err := validate.Struct(s); err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
-			return fmt.Errorf("字段 %s 验证失败，规则：%s", err.Field(), err.Tag())
+	return translateError(validate.Struct(s), errorx.DefaultLang)
+}
+
+// translateError 将校验错误转换为 errorx.ErrInvalidParam，字段错误按 lang 翻译后放入详情
+func translateError(err error, lang string) error {
+	if err == nil {
+		return nil
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return errorx.ErrInvalidParam.Wrap(err)
+	}
+
+	trans, _ := uni.GetTranslator(lang)
+	fields := make([]*FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, &FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return errorx.ErrInvalidParam.WithDetails(fields).Wrap(err)
+}
+
+// notZero 数值不能为 0，指针类型为 nil 时同样不通过
+func notZero(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return false
+		}
+		field = field.Elem()
+	}
+	return !field.IsZero()
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
 		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldPath 去掉命名空间中的结构体名称，CreateMenuReq.items[0].id -> items[0].id
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func registerTranslation(tag, message string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, message, true)
+	}
+}
+
+func translate(trans ut.Translator, fe validator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field())
+	if err != nil {
+		return fe.Error()
 	}
-	return nil
+	return message
 }